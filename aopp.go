@@ -5,18 +5,26 @@ type AOPParser struct {
 }
 
 func NewAOPParser(g *Grammar, src []byte, opts *RunOptions) *AOPParser {
+	return newAOPParser(g, len(src), opts)
+}
+
+// newAOPParser allocates a AOPParser sized for a source of srcLen bytes.
+func newAOPParser(g *Grammar, srcLen int, opts *RunOptions) *AOPParser {
 	p := &AOPParser{
 		OPParser: &OPParser{
 			g:                 g,
 			concurrency:       opts.Concurrency,
+			srcLen:            srcLen,
+			recovery:          opts.recovery,
+			maxErrors:         opts.maxErrors,
 			reductionStrategy: opts.ReductionStrategy,
 			workers:           make([]*oppWorker, opts.Concurrency),
 			results:           make([]*OPPStack, opts.Concurrency),
+			state:             g.StateFunc.call(srcLen, opts.Concurrency, opts.AvgTokenLength),
 		},
 	}
 
-	srcLen := len(src)
-	stackPoolBaseSize := stacksCountFactored[*Token](src, opts)
+	stackPoolBaseSize := stacksCountFactored[*Token](srcLen, opts)
 	ntPoolBaseSize := srcLen / opts.AvgTokenLength / p.concurrency
 
 	// Initialize memory pools for stacks.
@@ -39,7 +47,7 @@ func NewAOPParser(g *Grammar, src []byte, opts *RunOptions) *AOPParser {
 
 	// If reduction is sweep or mixed, we create another stack and input for the final pass.
 	if p.concurrency > 1 && (p.reductionStrategy == ReductionSweep || p.reductionStrategy == ReductionMixed) {
-		inputPoolBaseSize := stacksCount[Token](src, p.concurrency, opts.AvgTokenLength)
+		inputPoolBaseSize := stacksCount[Token](srcLen, p.concurrency, opts.AvgTokenLength)
 
 		p.pools.sweepInput = NewPool(inputPoolBaseSize, WithConstructor(newStack[Token]))
 		p.pools.sweepStack = NewPool(stackPoolBaseSize+1, WithConstructor(newStackFactory[*Token](stackLen)))
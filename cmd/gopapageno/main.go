@@ -7,12 +7,23 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/giornetta/gopapageno/generator"
 )
 
 func main() {
-	if err := run(); err != nil {
+	var err error
+	switch {
+	case len(os.Args) > 1 && os.Args[1] == "import":
+		err = runImport(os.Args[2:])
+	case len(os.Args) > 1 && os.Args[1] == "inspect":
+		err = runInspect(os.Args[2:])
+	default:
+		err = run()
+	}
+
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -24,6 +35,9 @@ func run() error {
 	outputFlag := flag.String("o", ".", "output directory")
 	typesOnlyFlag := flag.Bool("types-only", false, "generate types only")
 	benchmarkFlag := flag.Bool("benchmark", false, "generate benchmarks")
+	explainCutsFlag := flag.Bool("explain-cuts", false, "describe the cut points of the lexer and why they are safe")
+	normalizeFlag := flag.Bool("normalize", false, "rewrite the grammar into operator form, deleting empty rules and adjacent nonterminals")
+	reportFlag := flag.String("report", "", "write the precedence conflicts of the grammar to file, as JSON if it ends in .json or as text otherwise")
 
 	strategyFlag := flag.String("s", "opp", "strategy to use during parser generation: opp/aopp/copp")
 
@@ -35,19 +49,8 @@ func run() error {
 		return fmt.Errorf("lexicon and grammar description files must be provided")
 	}
 
-	strategy := gopapageno.OPP
-	if *strategyFlag == "aopp" {
-		strategy = gopapageno.AOPP
-	} else if *strategyFlag == "copp" {
-		strategy = gopapageno.COPP
-	}
-
-	var logOut io.Writer
-	if *logFlag {
-		logOut = os.Stderr
-	} else {
-		logOut = io.Discard
-	}
+	strategy := parseStrategy(*strategyFlag)
+	logOut := logOutput(*logFlag)
 
 	opts := &generator.Options{
 		LexerDescriptionFilename:  *lexiconFlag,
@@ -55,6 +58,9 @@ func run() error {
 		OutputDirectory:           *outputFlag,
 		TypesOnly:                 *typesOnlyFlag,
 		GenerateBenchmarks:        *benchmarkFlag,
+		ExplainCuts:               *explainCutsFlag,
+		ReportFilename:            *reportFlag,
+		Normalize:                 *normalizeFlag,
 		Strategy:                  strategy,
 		Logger:                    log.New(logOut, "", 0),
 	}
@@ -65,3 +71,125 @@ func run() error {
 
 	return nil
 }
+
+// runImport translates Yacc/Bison and Flex files into descriptions, printing the issues found.
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+
+	yaccFlag := flags.String("y", "", "Yacc/Bison grammar file")
+	flexFlag := flags.String("l", "", "Flex scanner file")
+	outputFlag := flags.String("o", ".", "output directory")
+	normalizeFlag := flags.Bool("normalize", false, "keep empty rules, checking the grammar as -normalize would")
+	strategyFlag := flags.String("s", "opp", "strategy to check the grammar with: opp/aopp/copp")
+	logFlag := flags.Bool("log", false, "enable logging during import")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *yaccFlag == "" {
+		return fmt.Errorf("grammar file must be provided")
+	}
+
+	opts := &generator.ImportOptions{
+		YaccFilename:    *yaccFlag,
+		FlexFilename:    *flexFlag,
+		OutputDirectory: *outputFlag,
+		Strategy:        parseStrategy(*strategyFlag),
+		Normalize:       *normalizeFlag,
+		Logger:          log.New(logOutput(*logFlag), "", 0),
+	}
+
+	issues, err := generator.Import(opts)
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not import: %w", err)
+	}
+
+	return nil
+}
+
+// runInspect prints the analysis of a grammar: its precedence matrix, terminal sets, rules and rule trie.
+func runInspect(args []string) error {
+	flags := flag.NewFlagSet("inspect", flag.ExitOnError)
+
+	grammarFlag := flags.String("g", "", "grammar source file")
+	outputFlag := flags.String("o", "", "output file, instead of standard output")
+	formatFlag := flags.String("format", "table", "format of the tables: table/csv/html")
+	sectionsFlag := flags.String("sections", "", "comma-separated sections to print among matrix/sets/rules/trie, all of them if empty")
+	normalizeFlag := flags.Bool("normalize", false, "rewrite the grammar into operator form before inspecting it")
+	strategyFlag := flags.String("s", "opp", "strategy to compile the grammar with: opp/aopp/copp")
+	logFlag := flags.Bool("log", false, "enable logging during compilation")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *grammarFlag == "" {
+		return fmt.Errorf("grammar description file must be provided")
+	}
+
+	var sections []generator.InspectSection
+	if *sectionsFlag != "" {
+		for _, section := range strings.Split(*sectionsFlag, ",") {
+			sections = append(sections, generator.InspectSection(strings.TrimSpace(section)))
+		}
+	}
+
+	var out io.Writer = os.Stdout
+
+	var f *os.File
+	if *outputFlag != "" {
+		var err error
+		if f, err = os.Create(*outputFlag); err != nil {
+			return fmt.Errorf("could not create output file: %w", err)
+		}
+		defer f.Close()
+
+		out = f
+	}
+
+	opts := &generator.InspectOptions{
+		ParserDescriptionFilename: *grammarFlag,
+		Sections:                  sections,
+		Format:                    generator.InspectFormat(*formatFlag),
+		Strategy:                  parseStrategy(*strategyFlag),
+		Normalize:                 *normalizeFlag,
+		Output:                    out,
+		Logger:                    log.New(logOutput(*logFlag), "", 0),
+	}
+
+	if err := generator.Inspect(opts); err != nil {
+		return fmt.Errorf("could not inspect: %w", err)
+	}
+
+	if f != nil {
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("could not write output file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func parseStrategy(name string) gopapageno.ParsingStrategy {
+	switch name {
+	case "aopp":
+		return gopapageno.AOPP
+	case "copp":
+		return gopapageno.COPP
+	default:
+		return gopapageno.OPP
+	}
+}
+
+func logOutput(enabled bool) io.Writer {
+	if enabled {
+		return os.Stderr
+	}
+
+	return io.Discard
+}
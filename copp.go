@@ -3,6 +3,7 @@ package gopapageno
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 )
 
 // COPParser implements parsing using a simplified approach to C-OPGs.
@@ -12,6 +13,18 @@ type COPParser struct {
 	concurrency       int
 	reductionStrategy ReductionStrategy
 
+	// srcLen is the length of the source, used as the position of the end of input.
+	srcLen int
+
+	recovery  bool
+	maxErrors int
+
+	// errorsFound counts the syntax errors recorded by every worker.
+	errorsFound atomic.Int64
+
+	// state is passed to semantic actions.
+	state any
+
 	// Pools
 	pools struct {
 		stacks       []*Pool[stack[*Token]]
@@ -32,16 +45,24 @@ type COPParser struct {
 
 // NewCOPParser allocates all required resources for a COPParser to be usable.
 func NewCOPParser(g *Grammar, src []byte, opts *RunOptions) *COPParser {
+	return newCOPParser(g, len(src), opts)
+}
+
+// newCOPParser allocates a COPParser sized for a source of srcLen bytes.
+func newCOPParser(g *Grammar, srcLen int, opts *RunOptions) *COPParser {
 	p := &COPParser{
 		g:                 g,
 		concurrency:       opts.Concurrency,
+		srcLen:            srcLen,
+		recovery:          opts.recovery,
+		maxErrors:         opts.maxErrors,
 		reductionStrategy: opts.ReductionStrategy,
 		workers:           make([]*coppWorker, opts.Concurrency),
 		results:           make([]*COPPStack, opts.Concurrency),
+		state:             g.StateFunc.call(srcLen, opts.Concurrency, opts.AvgTokenLength),
 	}
 
-	srcLen := len(src)
-	stackPoolBaseSize := stacksCountFactored[*Token](src, opts)
+	stackPoolBaseSize := stacksCountFactored[*Token](srcLen, opts)
 	ntPoolBaseSize := srcLen / opts.AvgTokenLength / p.concurrency
 
 	// Initialize memory pools for stacks.
@@ -73,7 +94,7 @@ func NewCOPParser(g *Grammar, src []byte, opts *RunOptions) *COPParser {
 
 	// If reduction is sweep or mixed, we create another stack and input for the final pass.
 	if p.concurrency > 1 && (p.reductionStrategy == ReductionSweep || p.reductionStrategy == ReductionMixed) {
-		inputPoolBaseSize := stacksCount[Token](src, p.concurrency, opts.AvgTokenLength)
+		inputPoolBaseSize := stacksCount[Token](srcLen, p.concurrency, opts.AvgTokenLength)
 
 		p.pools.sweepInput = NewPool(inputPoolBaseSize, WithConstructor(newStack[Token]))
 		p.pools.sweepStack = NewPool(stackPoolBaseSize+1, WithConstructor(newStackFactory[*Token](stackLen)))
@@ -103,15 +124,19 @@ func (p *COPParser) Parse(ctx context.Context, tokensLists []*LOS[Token]) (*Toke
 
 	// First parallel pass of the algorithm.
 	for thread := 0; thread < p.concurrency; thread++ {
-		var nextToken *Token
+		s := NewCOPPStack(p.pools.stacks[thread], p.pools.stateStacks[thread], p.pools.producedTokensMap[thread])
+
+		// The first thread begins with a # on the stack.
+		if thread == 0 {
+			s.Push(newTermToken(0))
+		}
 
-		// If the thread is not the last, take the first token of the next stack as lookahead.
+		// The last thread ends with a #, the others take the first token of the next list as lookahead.
+		nextToken := newTermToken(p.srcLen)
 		if thread < p.concurrency-1 {
-			nextInputListIter := tokensLists[thread+1].HeadIterator()
-			nextToken = nextInputListIter.Next()
+			nextToken = lookahead(tokensLists[thread+1])
 		}
 
-		s := NewCOPPStack(p.pools.stacks[thread], p.pools.stateStacks[thread], p.pools.producedTokensMap[thread])
 		go p.workers[thread].parse(ctx, s, tokensLists[thread], nextToken, false, resultCh, errCh)
 	}
 
@@ -181,6 +206,134 @@ func (p *COPParser) Parse(ctx context.Context, tokensLists []*LOS[Token]) (*Toke
 	}
 
 	root, err := p.results[0].LastNonterminal()
+
+	// When recovering from errors the partial parse tree is returned alongside them.
+	if errs := p.syntaxErrors(); errs != nil {
+		return root, errs
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// parseStream parses the lists of tokens received from batchCh, which must be closed once the source is over.
+// Results are always reduced with a single sweep.
+func (p *COPParser) parseStream(ctx context.Context, batchCh <-chan tokensBatch) (*Token, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if p.pools.sweepInput == nil {
+		p.pools.sweepInput = NewPool(1, WithConstructor(newStack[Token]))
+		p.pools.sweepStack = NewPool(1, WithConstructor(newStack[*Token]))
+		p.pools.sweepStateStack = NewPool(1, WithConstructor(newStack[CyclicAutomataState]))
+	}
+
+	resultCh := make(chan parseResult[COPPStack])
+	errCh := make(chan error, 1)
+
+	// first is the result of the first list, which is never reused. The results of the others are joined into input.
+	var first *COPPStack
+	input := NewLOS[Token](p.pools.sweepInput)
+	producedTokens := make(map[*Token]*Token)
+	joined := 0
+
+	// firstPass runs the first pass on lists, the last of which is followed by next.
+	firstPass := func(lists []*LOS[Token], next *Token) error {
+		p.concurrency = len(lists)
+
+		for i, list := range lists {
+			var s *COPPStack
+			if i == 0 && first == nil {
+				s = NewCOPPStack(p.pools.sweepStack, p.pools.sweepStateStack, make(map[*Token]*Token))
+				s.Push(newTermToken(0))
+			} else {
+				s = NewCOPPStack(p.pools.stacks[i], p.pools.stateStacks[i], p.pools.producedTokensMap[i])
+			}
+
+			// The lookahead is pushed here rather than by the worker, since the list may belong to the pools of the lexer,
+			// which is still running.
+			lookaheadToken := next
+			if i < len(lists)-1 {
+				lookaheadToken = lookahead(lists[i+1])
+			}
+
+			list.pool = p.pools.sweepInput
+			list.Push(*lookaheadToken)
+
+			go p.workers[i].parse(ctx, s, list, nil, false, resultCh, errCh)
+		}
+
+		if err := collectResults[COPPStack](p.results, resultCh, errCh, len(lists)); err != nil {
+			return err
+		}
+
+		results := p.results[:len(lists)]
+		if first == nil {
+			first, results = results[0], results[1:]
+		}
+
+		pushCyclicSweepInput(input, producedTokens, results)
+		joined += len(results)
+
+		// The results have been copied into input, so their stacks can be used again.
+		for thread := range p.workers {
+			p.pools.stacks[thread].Reset()
+			p.pools.stateStacks[thread].Reset()
+
+			clear(p.pools.producedTokensMap[thread])
+		}
+
+		return nil
+	}
+
+	var stream listStream
+
+	for batch := range batchCh {
+		p.srcLen = batch.srcLen
+
+		if lists, next := stream.add(batch.lists); len(lists) > 0 {
+			if err := firstPass(lists, next); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := firstPass([]*LOS[Token]{stream.flush(p.pools.sweepInput)}, newTermToken(p.srcLen)); err != nil {
+		return nil, err
+	}
+
+	result := first
+	if joined > 0 {
+		stack := first.Combine()
+		for k, v := range producedTokens {
+			stack.ProducedTokens[k] = v
+		}
+
+		p.concurrency = 1
+
+		go p.workers[0].parse(ctx, stack, input, nil, true, resultCh, errCh)
+
+		if err := collectResults[COPPStack](p.results, resultCh, errCh, 1); err != nil {
+			return nil, err
+		}
+
+		result = p.results[0]
+	}
+
+	root, err := result.LastNonterminal()
+
+	// When recovering from errors the partial parse tree is returned alongside them.
+	if errs := p.syntaxErrors(); errs != nil {
+		return root, errs
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -188,11 +341,54 @@ func (p *COPParser) Parse(ctx context.Context, tokensLists []*LOS[Token]) (*Toke
 	return root, nil
 }
 
+// reset prepares the parser for a new source of srcLen bytes, rewinding its pools.
+// Parse trees produced before calling reset must not be used anymore.
+func (p *COPParser) reset(srcLen int, opts *RunOptions) {
+	p.concurrency = len(p.workers)
+	p.srcLen = srcLen
+	p.recovery = opts.recovery
+	p.maxErrors = opts.maxErrors
+	p.errorsFound.Store(0)
+	p.state = p.g.StateFunc.call(srcLen, p.concurrency, opts.AvgTokenLength)
+
+	for thread := range p.workers {
+		p.pools.stacks[thread].Reset()
+		p.pools.nonterminals[thread].Reset()
+		p.pools.stateStacks[thread].Reset()
+
+		clear(p.pools.producedTokensMap[thread])
+
+		p.workers[thread].recoveryState.reset()
+	}
+
+	if p.pools.sweepInput != nil {
+		p.pools.sweepInput.Reset()
+		p.pools.sweepStack.Reset()
+		p.pools.sweepStateStack.Reset()
+	}
+}
+
+// syntaxErrors returns the errors that workers recovered from as an ErrorList, or nil if there are none.
+func (p *COPParser) syntaxErrors() error {
+	var errs []*SyntaxError
+	for _, w := range p.workers {
+		errs = append(errs, w.errors...)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return newErrorList(errs, p.maxErrors)
+}
+
 type coppWorker struct {
 	parser *COPParser
 	id     int
 
 	ntPool *Pool[Token]
+
+	recoveryState
 }
 
 // parseCyclic implements COPP.
@@ -202,28 +398,20 @@ func (w *coppWorker) parse(ctx context.Context, stack *COPPStack, tokens *LOS[To
 	rhs := make([]TokenType, 0, w.parser.g.MaxPrefixLength)
 	rhsTokens := make([]*Token, 0, w.parser.g.MaxPrefixLength)
 
-	// If the thread is the first, push a # onto the stack
+	// The stack of the first list already holds a #.
 	// Otherwise, push the first inputToken onto the stack
 	if !finalPass {
-		if w.id == 0 {
-			stack.Push(&Token{
-				Type:       TokenTerm,
-				Precedence: PrecEmpty,
-			})
-		} else {
+		// Errors found by the previous passes of the worker belong to another portion of the source.
+		w.quiet = 0
+
+		if stack.Length() == 0 {
 			t := tokensIt.Next()
 			t.Precedence = PrecEmpty
 			stack.Push(t)
 		}
 
-		// If the thread is the last, push a # onto the tokensList.
-		// Otherwise, push the lookahead token.
-		if w.id == w.parser.concurrency-1 {
-			tokens.Push(Token{
-				Type:       TokenTerm,
-				Precedence: PrecEmpty,
-			})
-		} else if nextToken != nil {
+		// Push the # ending the source, or the lookahead token.
+		if nextToken != nil {
 			tokens.Push(*nextToken)
 		}
 	}
@@ -237,6 +425,11 @@ func (w *coppWorker) parse(ctx context.Context, stack *COPPStack, tokens *LOS[To
 		// Find the first terminal on the stack and get the precedence between it and the current token
 		firstTerminal := stack.FirstTerminal()
 
+		top := TokenTerm
+		if firstTerminal != nil {
+			top = firstTerminal.Type
+		}
+
 		if !inputToken.Type.IsTerminal() {
 			prec = PrecYields
 		} else {
@@ -279,6 +472,7 @@ func (w *coppWorker) parse(ctx context.Context, stack *COPPStack, tokens *LOS[To
 			rhs = rhs[:0]
 			prefixCount = 0
 
+			w.shifted()
 			inputToken = tokensIt.Next()
 		} else if prec == PrecEquals {
 			inputToken.Precedence = prec
@@ -308,6 +502,7 @@ func (w *coppWorker) parse(ctx context.Context, stack *COPPStack, tokens *LOS[To
 				_, s := stack.Pop2()
 				stack.PushWithState(inputToken, *s)
 
+				w.shifted()
 				inputToken = tokensIt.Next()
 
 				continue
@@ -315,8 +510,16 @@ func (w *coppWorker) parse(ctx context.Context, stack *COPPStack, tokens *LOS[To
 
 			lhsToken, err := w.matchPrefix(lhs, ruleNum, rhsTokens[:len(rhsTokens)-prefixCount-1], stack)
 			if err != nil {
-				errCh <- fmt.Errorf("worker %d could not match: %v", w.id, err)
-				return
+				if err := w.recover(w.parser.g.syntaxError(inputToken, top, err.Error()), stack, tokensIt, &inputToken, true); err != nil {
+					errCh <- err
+					return
+				}
+
+				rhsTokens = rhsTokens[:0]
+				rhs = rhs[:0]
+				prefixCount = 0
+
+				continue
 			}
 
 			// Reset state
@@ -335,6 +538,7 @@ func (w *coppWorker) parse(ctx context.Context, stack *COPPStack, tokens *LOS[To
 			_, s := stack.Pop2()
 			stack.PushWithState(inputToken, *s)
 
+			w.shifted()
 			inputToken = tokensIt.Next()
 		} else if prec == PrecTakes {
 			// If there are no tokens yielding precedence on the stack, push inputToken onto the stack.
@@ -351,6 +555,7 @@ func (w *coppWorker) parse(ctx context.Context, stack *COPPStack, tokens *LOS[To
 					stack.SwapState()
 				}
 
+				w.shifted()
 				inputToken = tokensIt.Next()
 			} else {
 				var i int
@@ -386,8 +591,25 @@ func (w *coppWorker) parse(ctx context.Context, stack *COPPStack, tokens *LOS[To
 
 				lhsToken, err := w.match(rhs, rhsTokens, stack)
 				if err != nil {
-					errCh <- fmt.Errorf("worker %d could not match: %v", w.id, err)
-					return
+					if err := w.recover(w.parser.g.syntaxError(inputToken, top, err.Error()), stack, tokensIt, &inputToken, false); err != nil {
+						errCh <- err
+						return
+					}
+
+					// The first nonterminal of the handle stands in for it, so that the partial tree isn't lost.
+					lhsToken = firstNonterminal(rhsTokens)
+					if lhsToken == nil {
+						// The handle has already been popped: drop it from the current construction.
+						stack.StateTokenStack.Tos = stack.State.PreviousIndex + stack.State.PreviousLen
+						stack.State.CurrentIndex = stack.StateTokenStack.Tos
+						stack.State.CurrentLen = 0
+
+						rhsTokens = rhsTokens[:0]
+						rhs = rhs[:0]
+						prefixCount = 0
+
+						continue
+					}
 				}
 
 				// Reset state
@@ -403,14 +625,57 @@ func (w *coppWorker) parse(ctx context.Context, stack *COPPStack, tokens *LOS[To
 			rhs = rhs[:0]
 		} else {
 			//If there's no precedence relation, abort the parsing
-			errCh <- fmt.Errorf("no precedence relation found")
-			return
+			if err := w.recover(w.parser.g.syntaxError(inputToken, top, "no precedence relation found"), stack, tokensIt, &inputToken, true); err != nil {
+				errCh <- err
+				return
+			}
+
+			rhsTokens = rhsTokens[:0]
+			rhs = rhs[:0]
+			prefixCount = 0
 		}
 	}
 
 	resultCh <- parseResult[COPPStack]{w.id, stack}
 }
 
+// recover records err and resynchronizes the worker after it, skipping input up to the next synchronizing terminal.
+// Unlike OPP workers, the stack is left untouched, since its construction states can't be safely rewound;
+// if the stack didn't change since the error was found, mustSkip makes sure that the offending token is skipped.
+// Errors found right after recovering from another one are not reported.
+// It returns the error that must abort parsing if recovery is disabled.
+func (w *coppWorker) recover(err *SyntaxError, stack *COPPStack, tokensIt *LOSIt[Token], inputToken **Token, mustSkip bool) error {
+	p := w.parser
+
+	if !p.recovery {
+		return err
+	}
+
+	// Once too many errors were found, the rest of the input is skipped, keeping the partial parse tree.
+	if w.record(err, &p.errorsFound, p.maxErrors) {
+		*inputToken = nil
+		return nil
+	}
+
+	acceptable := func(t *Token) bool {
+		top := stack.FirstTerminal()
+		return !mustSkip && top != nil && p.g.hasPrecedence(top.Type, t.Type)
+	}
+
+	t := *inputToken
+	if mustSkip {
+		t = tokensIt.Next()
+	}
+
+	for t != nil && t.Type != TokenTerm && !p.g.isSync(t.Type) && !acceptable(t) {
+		t = tokensIt.Next()
+	}
+
+	*inputToken = t
+
+	return nil
+}
+
 func (w *coppWorker) matchPrefix(lhs TokenType, ruleNum uint16, rhsTokens []*Token, s *COPPStack) (*Token, error) {
 	var lhsToken *Token
 	var rf RuleFlags
@@ -435,8 +700,14 @@ func (w *coppWorker) matchPrefix(lhs TokenType, ruleNum uint16, rhsTokens []*Tok
 		rf = rf.Set(RuleCombine)
 	}
 
+	if lhsToken == parentToken {
+		lhsToken.extendSpan(rhsTokens)
+	} else {
+		lhsToken.setSpan(rhsTokens)
+	}
+
 	//Execute the semantic action
-	w.parser.g.Func(ruleNum, rf, lhsToken, rhsTokens, w.id)
+	w.parser.g.Func(ruleNum, rf, lhsToken, rhsTokens, w.id, w.parser.state)
 
 	s.ProducedTokens[rhsTokens[0]] = lhsToken
 
@@ -446,7 +717,7 @@ func (w *coppWorker) matchPrefix(lhs TokenType, ruleNum uint16, rhsTokens []*Tok
 func (w *coppWorker) match(rhs []TokenType, rhsTokens []*Token, s *COPPStack) (*Token, error) {
 	lhs, ruleNum := w.parser.g.findRuleMatch(rhs)
 	if lhs == TokenEmpty {
-		return nil, fmt.Errorf("could not find match for rhs %v", rhs)
+		return nil, fmt.Errorf("no rule matches %s", w.parser.g.sprintTypes(rhs))
 	}
 
 	var lhsToken *Token
@@ -468,8 +739,14 @@ func (w *coppWorker) match(rhs []TokenType, rhsTokens []*Token, s *COPPStack) (*
 		rt = RuleCombine
 	}
 
+	if lhsToken == parentToken {
+		lhsToken.extendSpan(rhsTokens)
+	} else {
+		lhsToken.setSpan(rhsTokens)
+	}
+
 	//Execute the semantic action
-	w.parser.g.Func(ruleNum, rt, lhsToken, rhsTokens, w.id)
+	w.parser.g.Func(ruleNum, rt, lhsToken, rhsTokens, w.id, w.parser.state)
 
 	return lhsToken, nil
 }
@@ -478,9 +755,21 @@ func (p *COPParser) CombineSweepLOS(pool *Pool[stack[Token]], stacks []*COPPStac
 	input := NewLOS[Token](pool)
 	newProducedTokens := make(map[*Token]*Token)
 
+	pushCyclicSweepInput(input, newProducedTokens, stacks[:p.concurrency-1])
+
+	return input, newProducedTokens
+}
+
+// pushCyclicSweepInput appends to input the tokens of stacks, which follow the first one, leaving out the first token of each.
+// The temporary parents of the tokens pushed are added to newProducedTokens.
+func pushCyclicSweepInput(input *LOS[Token], newProducedTokens map[*Token]*Token, stacks []*COPPStack) {
+	if len(stacks) == 0 {
+		return
+	}
+
 	tokenSet := make(map[*Token]struct{}, stacks[0].Length())
 
-	for i := 0; i < p.concurrency-1; i++ {
+	for i := range stacks {
 		it := stacks[i].Iterator()
 
 		//Ignore the first token.
@@ -568,6 +857,4 @@ func (p *COPParser) CombineSweepLOS(pool *Pool[stack[Token]], stacks []*COPPStac
 			}
 		}
 	}
-
-	return input, newProducedTokens
 }
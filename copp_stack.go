@@ -256,6 +256,14 @@ func (s *COPPStack) LastNonterminal() (*Token, error) {
 		return s.StateTokenStack.Slice(s.State.CurrentIndex, s.State.CurrentLen)[0], nil
 	}
 
+	// A parser that stopped early because of too many syntax errors leaves its partial tree in the previous constructions.
+	tokens := s.StateTokenStack.Data[:s.StateTokenStack.Tos]
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if !tokens[i].Type.IsTerminal() {
+			return tokens[i], nil
+		}
+	}
+
 	return nil, fmt.Errorf("no token stack current")
 }
 
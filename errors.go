@@ -0,0 +1,304 @@
+package gopapageno
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// A LexError is returned when the source contains a sequence of bytes that can't be matched by any lexer rule.
+// It wraps ErrInvalid.
+type LexError struct {
+	// Offset is the position of the offending byte in the source.
+	Offset int
+	// Line and Column are the 1-based location of Offset. They are zero if it couldn't be resolved.
+	Line   int
+	Column int
+
+	// Context is a bounded excerpt of the source surrounding Offset.
+	Context string
+}
+
+func (e *LexError) Error() string {
+	if e.Context == "" {
+		return fmt.Sprintf("%s: %v", location(e.Offset, e.Line, e.Column), ErrInvalid)
+	}
+
+	return fmt.Sprintf("%s: %v near %q", location(e.Offset, e.Line, e.Column), ErrInvalid, e.Context)
+}
+
+func (e *LexError) Unwrap() error {
+	return ErrInvalid
+}
+
+// A SyntaxError is returned when a parser finds a token that can't follow the ones read before it.
+type SyntaxError struct {
+	// Offset is the position of the first byte of the offending token in the source.
+	Offset int
+	// Line and Column are the 1-based location of Offset. They are zero if it couldn't be resolved.
+	Line   int
+	Column int
+
+	// Token is the type of the offending token.
+	Token     TokenType
+	TokenName string
+
+	// Top is the type of the topmost terminal on the parser stack when the error was found.
+	Top     TokenType
+	TopName string
+
+	// Expected contains the terminals that could have followed Top according to the precedence matrix.
+	Expected      []TokenType
+	ExpectedNames []string
+
+	// Reason describes why the parser could not go on.
+	Reason string
+}
+
+func (e *SyntaxError) Error() string {
+	var sb strings.Builder
+
+	sb.WriteString(location(e.Offset, e.Line, e.Column))
+	sb.WriteString(": syntax error: ")
+
+	if e.Token == TokenTerm {
+		sb.WriteString("unexpected end of input")
+	} else {
+		sb.WriteString("unexpected ")
+		sb.WriteString(e.TokenName)
+	}
+
+	if e.Top != TokenTerm {
+		sb.WriteString(" after ")
+		sb.WriteString(e.TopName)
+	}
+
+	if e.Reason != "" {
+		sb.WriteString(": ")
+		sb.WriteString(e.Reason)
+	}
+
+	if len(e.ExpectedNames) > 0 {
+		sb.WriteString(" (expected one of ")
+		sb.WriteString(strings.Join(e.ExpectedNames, ", "))
+		sb.WriteString(")")
+	}
+
+	return sb.String()
+}
+
+// An ErrorList contains the syntax errors found by a parser running with error recovery, sorted by offset.
+type ErrorList []*SyntaxError
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	default:
+		return fmt.Sprintf("%v (and %d more errors)", l[0], len(l)-1)
+	}
+}
+
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, e := range l {
+		errs[i] = e
+	}
+
+	return errs
+}
+
+// newErrorList sorts errs by offset, keeping a single error for each offset,
+// and keeps at most maxErrors of them if maxErrors is positive.
+// Workers reducing the results of other workers can find a second error on a token that was already reported.
+func newErrorList(errs []*SyntaxError, maxErrors int) ErrorList {
+	slices.SortStableFunc(errs, func(a, b *SyntaxError) int {
+		return cmp.Compare(a.Offset, b.Offset)
+	})
+
+	errs = slices.CompactFunc(errs, func(a, b *SyntaxError) bool {
+		return a.Offset == b.Offset
+	})
+
+	if maxErrors > 0 && len(errs) > maxErrors {
+		errs = errs[:maxErrors]
+	}
+
+	return errs
+}
+
+// syntaxError builds a SyntaxError for token, found while top was the topmost terminal on the stack.
+func (g *Grammar) syntaxError(token *Token, top TokenType, reason string) *SyntaxError {
+	e := &SyntaxError{
+		Offset:    token.Start,
+		Token:     token.Type,
+		TokenName: g.TokenName(token.Type),
+		Top:       top,
+		TopName:   g.TokenName(top),
+		Reason:    reason,
+	}
+
+	e.Expected = g.expectedTerminals(top)
+	e.ExpectedNames = g.tokenNames(e.Expected)
+
+	return e
+}
+
+// tokenNames returns the names of every token type in types.
+func (g *Grammar) tokenNames(types []TokenType) []string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = g.TokenName(t)
+	}
+
+	return names
+}
+
+// sprintTypes formats a sequence of token types, such as a rule right-hand side.
+func (g *Grammar) sprintTypes(types []TokenType) string {
+	return "[" + strings.Join(g.tokenNames(types), " ") + "]"
+}
+
+// expectedTerminals returns the terminals that have a precedence relation with top.
+// The bit-packed precedence matrix can't represent missing relations, so the full matrix is required.
+func (g *Grammar) expectedTerminals(top TokenType) []TokenType {
+	if int(top.Value()) >= len(g.PrecedenceMatrix) {
+		return nil
+	}
+
+	var expected []TokenType
+	for v, prec := range g.PrecedenceMatrix[top.Value()] {
+		if prec != PrecEmpty {
+			expected = append(expected, TokenTerm|TokenType(v))
+		}
+	}
+
+	return expected
+}
+
+// hasPrecedence reports whether any precedence relation holds between the terminals t1 and t2.
+// If the full precedence matrix isn't available it assumes there is one.
+func (g *Grammar) hasPrecedence(t1 TokenType, t2 TokenType) bool {
+	if int(t1.Value()) >= len(g.PrecedenceMatrix) {
+		return true
+	}
+
+	return g.PrecedenceMatrix[t1.Value()][t2.Value()] != PrecEmpty
+}
+
+// isSync reports whether t is one of the synchronizing terminals used for error recovery.
+func (g *Grammar) isSync(t TokenType) bool {
+	return slices.Contains(g.SyncTerminals, t)
+}
+
+// recoveryShifts is the number of tokens a parser must shift after recovering from a syntax error before reporting
+// the following one, since errors found earlier are most likely caused by the recovery itself.
+const recoveryShifts = 3
+
+// A recoveryState tracks the syntax errors a parser worker recovered from.
+type recoveryState struct {
+	// errors contains the syntax errors the worker recovered from.
+	errors []*SyntaxError
+
+	// quiet is the number of tokens left to shift before errors are reported again.
+	quiet int
+}
+
+// record adds err to the errors found, unless it follows the previous one too closely.
+// found counts the errors recorded by every worker of the parser: record reports whether maxErrors of them were found,
+// in which case parsing must stop.
+func (s *recoveryState) record(err *SyntaxError, found *atomic.Int64, maxErrors int) bool {
+	if maxErrors > 0 && found.Load() >= int64(maxErrors) {
+		return true
+	}
+
+	quiet := s.quiet > 0
+	s.quiet = recoveryShifts
+
+	if quiet {
+		return false
+	}
+
+	s.errors = append(s.errors, err)
+
+	return maxErrors > 0 && found.Add(1) >= int64(maxErrors)
+}
+
+// shifted records that a token was shifted onto the stack.
+func (s *recoveryState) shifted() {
+	if s.quiet > 0 {
+		s.quiet--
+	}
+}
+
+// reset forgets the errors found.
+func (s *recoveryState) reset() {
+	s.errors = s.errors[:0]
+	s.quiet = 0
+}
+
+// firstNonterminal returns the first nonterminal among tokens, or nil if there is none.
+// Parsers recovering from errors use it as a stand-in for a handle that can't be reduced.
+func firstNonterminal(tokens []*Token) *Token {
+	for _, t := range tokens {
+		if !t.Type.IsTerminal() {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// TokenName returns the name of a token type as declared in the grammar description.
+func (g *Grammar) TokenName(t TokenType) string {
+	if name, ok := g.TokenNames[t]; ok {
+		return name
+	}
+
+	switch {
+	case t == TokenTerm:
+		return "#"
+	case t == TokenEmpty:
+		return "Empty"
+	case t.IsTerminal():
+		return fmt.Sprintf("terminal %d", t.Value())
+	default:
+		return fmt.Sprintf("nonterminal %d", t.Value())
+	}
+}
+
+// locateError resolves the line and column of a LexError or SyntaxError contained in err, using the map of the source.
+func locateError(err error, m *SourceMap) {
+	var list ErrorList
+	if errors.As(err, &list) {
+		for _, e := range list {
+			l := m.Locate(e.Offset)
+			e.Line, e.Column = l.Line, l.Column
+		}
+	}
+
+	var lexErr *LexError
+	if errors.As(err, &lexErr) {
+		l := m.Locate(lexErr.Offset)
+		lexErr.Line, lexErr.Column = l.Line, l.Column
+	}
+
+	var syntaxErr *SyntaxError
+	if errors.As(err, &syntaxErr) {
+		l := m.Locate(syntaxErr.Offset)
+		syntaxErr.Line, syntaxErr.Column = l.Line, l.Column
+	}
+}
+
+func location(offset, line, column int) string {
+	if line == 0 {
+		return fmt.Sprintf("offset %d", offset)
+	}
+
+	return fmt.Sprintf("%d:%d", line, column)
+}
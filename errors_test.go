@@ -0,0 +1,136 @@
+package gopapageno
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSyntaxError(t *testing.T) {
+	const (
+		num  = TokenTerm + 1
+		plus = TokenTerm + 2
+	)
+
+	g := &Grammar{
+		NumTerminals: 3,
+		PrecedenceMatrix: [][]Precedence{
+			{PrecEquals, PrecYields, PrecYields},
+			{PrecTakes, PrecEmpty, PrecTakes},
+			{PrecTakes, PrecYields, PrecTakes},
+		},
+		TokenNames: map[TokenType]string{
+			num:  "NUMBER",
+			plus: "PLUS",
+		},
+	}
+
+	err := fmt.Errorf("could not parse: %w", g.syntaxError(&Token{Type: num, Start: 4}, num, "no precedence relation found"))
+	locateError(err, NewSourceMap([]byte("1+2\n3")))
+
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("expected a SyntaxError, got %v", err)
+	}
+
+	if syntaxErr.Line != 2 || syntaxErr.Column != 1 {
+		t.Errorf("expected error at 2:1, got %d:%d", syntaxErr.Line, syntaxErr.Column)
+	}
+
+	expected := "2:1: syntax error: unexpected NUMBER after NUMBER: no precedence relation found (expected one of #, PLUS)"
+	if syntaxErr.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, syntaxErr.Error())
+	}
+}
+
+func TestLexErrorContext(t *testing.T) {
+	data := []byte(strings.Repeat("a", 100) + "!" + strings.Repeat("b", 100))
+
+	w := &scannerWorker{
+		data:        data,
+		pos:         100,
+		startingPos: 1000,
+	}
+
+	err := w.lexError()
+
+	if err.Offset != 1100 {
+		t.Errorf("expected offset 1100, got %d", err.Offset)
+	}
+
+	expected := strings.Repeat("a", lexErrorContext) + "!" + strings.Repeat("b", lexErrorContext)
+	if err.Context != expected {
+		t.Errorf("expected context %q, got %q", expected, err.Context)
+	}
+
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected error to wrap ErrInvalid")
+	}
+}
+
+func TestNewErrorList(t *testing.T) {
+	errs := []*SyntaxError{
+		{Offset: 30, Reason: "c"},
+		{Offset: 10, Reason: "a"},
+		{Offset: 20, Reason: "b"},
+		{Offset: 10, Reason: "d"},
+	}
+
+	list := newErrorList(errs, 2)
+	if len(list) != 2 || list[0].Offset != 10 || list[1].Offset != 20 {
+		t.Fatalf("expected errors at offsets 10 and 20, got %v", list)
+	}
+
+	if list[0].Reason != "a" {
+		t.Errorf("expected the error at offset 10 to be the first one found, got %q", list[0].Reason)
+	}
+
+	var syntaxErr *SyntaxError
+	if !errors.As(fmt.Errorf("could not parse: %w", list), &syntaxErr) || syntaxErr != list[0] {
+		t.Errorf("expected the first error to be found through the list")
+	}
+}
+
+func TestRecoveryStateRecord(t *testing.T) {
+	var found atomic.Int64
+	var s recoveryState
+
+	if s.record(&SyntaxError{Offset: 1}, &found, 3) {
+		t.Fatalf("expected the first error not to reach the limit")
+	}
+
+	// Errors found before recoveryShifts tokens are shifted are cascades of the previous one.
+	for range recoveryShifts - 1 {
+		s.shifted()
+	}
+	s.record(&SyntaxError{Offset: 2}, &found, 3)
+
+	if len(s.errors) != 1 || found.Load() != 1 {
+		t.Fatalf("expected the second error to be suppressed, got %d errors", len(s.errors))
+	}
+
+	for range recoveryShifts {
+		s.shifted()
+	}
+	s.record(&SyntaxError{Offset: 3}, &found, 3)
+
+	if len(s.errors) != 2 {
+		t.Fatalf("expected the third error to be recorded, got %d errors", len(s.errors))
+	}
+
+	// Errors recorded by other workers count towards the limit.
+	found.Add(1)
+	for range recoveryShifts {
+		s.shifted()
+	}
+
+	if !s.record(&SyntaxError{Offset: 4}, &found, 3) {
+		t.Errorf("expected the limit to be reached")
+	}
+
+	if len(s.errors) != 2 {
+		t.Errorf("expected no errors to be recorded past the limit, got %d", len(s.errors))
+	}
+}
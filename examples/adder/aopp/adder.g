@@ -11,7 +11,7 @@ S : E
 
 E : E PLUS E
 {
-    newValue := parserPools[thread].Get()
+    newValue := state[thread].Get()
     *newValue = *$1.Value.(*int64) + *$3.Value.(*int64)
     $$.Value = newValue
 } | LPAR E RPAR
@@ -28,15 +28,15 @@ import (
 	"math"
 )
 
-var parserPools []*gopapageno.Pool[int64]
-
-func ParserPreallocMem(inputSize int, numThreads int) {
-	parserPools = make([]*gopapageno.Pool[int64], numThreads)
+func ParserPreallocMem(inputSize int, numThreads int) []*gopapageno.Pool[int64] {
+	pools := make([]*gopapageno.Pool[int64], numThreads)
 
 	avgCharsPerNumber := float64(2)
 	poolSizePerThread := int(math.Ceil((float64(inputSize) / avgCharsPerNumber) / float64(numThreads)))
 
 	for i := 0; i < numThreads; i++ {
-		parserPools[i] = gopapageno.NewPool[int64](poolSizePerThread)
+		pools[i] = gopapageno.NewPool[int64](poolSizePerThread)
 	}
+
+	return pools
 }
\ No newline at end of file
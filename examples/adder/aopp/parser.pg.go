@@ -2,28 +2,30 @@
 package main
 
 import (
-	"fmt"
 	"github.com/giornetta/gopapageno"
 	"strings"
+	"fmt"
+	"os"
 )
 
 import (
 	"math"
 )
 
-var parserPools []*gopapageno.Pool[int64]
-
-func ParserPreallocMem(inputSize int, numThreads int) {
-	parserPools = make([]*gopapageno.Pool[int64], numThreads)
+func ParserPreallocMem(inputSize int, numThreads int) []*gopapageno.Pool[int64] {
+	pools := make([]*gopapageno.Pool[int64], numThreads)
 
 	avgCharsPerNumber := float64(2)
 	poolSizePerThread := int(math.Ceil((float64(inputSize) / avgCharsPerNumber) / float64(numThreads)))
 
 	for i := 0; i < numThreads; i++ {
-		parserPools[i] = gopapageno.NewPool[int64](poolSizePerThread)
+		pools[i] = gopapageno.NewPool[int64](poolSizePerThread)
 	}
+
+	return pools
 }
 
+
 // Non-terminals
 const (
 	E = gopapageno.TokenEmpty + 1 + iota
@@ -38,7 +40,78 @@ const (
 	RPAR
 )
 
-func SprintToken[TokenValue any](root *gopapageno.Token) string {
+func DumpGraph[ValueType any](root *gopapageno.Token, f *os.File) {
+	sb := strings.Builder{}
+	sb.WriteString("digraph parse_tree {\n")
+	sb.WriteString("ratio = fill;\n")
+	sb.WriteString("node [style=filled];\n")
+
+	var graphPrintRec func(t *gopapageno.Token, p *gopapageno.Token, sb *strings.Builder, i int)
+	graphPrintRec = func(t *gopapageno.Token, p *gopapageno.Token, sb *strings.Builder, i int) {
+		if t == nil {
+			return
+		}
+
+		if p == nil {
+			graphPrintRec(t.Child, t, sb, i+1)
+			return
+		}
+
+		var t_name, t_color, p_name, p_color string
+
+		switch p.Type {
+		case E:
+			p_name, p_color = "E", "0.408 0.498 1.000"
+		case S:
+			p_name, p_color = "S", "0.408 0.498 1.000"
+		case gopapageno.TokenEmpty:
+			p_name, p_color = "__EMPTY__", "0.408 0.498 1.000"
+		case LPAR:
+			p_name, p_color = "LPAR", "0.641 0.212 1.000"
+		case NUMBER:
+			p_name, p_color = "NUMBER", "0.641 0.212 1.000"
+		case PLUS:
+			p_name, p_color = "PLUS", "0.641 0.212 1.000"
+		case RPAR:
+			p_name, p_color = "RPAR", "0.641 0.212 1.000"
+		case gopapageno.TokenTerm:
+			p_name, p_color = "__TERM__", "0.641 0.212 1.000"
+		}
+
+		switch t.Type {
+		case E:
+			t_name, t_color = "E", "0.408 0.498 1.000"
+		case S:
+			t_name, t_color = "S", "0.408 0.498 1.000"
+		case gopapageno.TokenEmpty:
+			t_name, t_color = "__EMPTY__", "0.408 0.498 1.000"
+		case LPAR:
+			t_name, t_color = "LPAR", "0.641 0.212 1.000"
+		case NUMBER:
+			t_name, t_color = "NUMBER", "0.641 0.212 1.000"
+		case PLUS:
+			t_name, t_color = "PLUS", "0.641 0.212 1.000"
+		case RPAR:
+			t_name, t_color = "RPAR", "0.641 0.212 1.000"
+		case gopapageno.TokenTerm:
+			t_name, t_color = "__TERM__", "0.641 0.212 1.000"
+		}
+
+		sb.WriteString(fmt.Sprintf("\"%p\" -> \"%p\";\n", p, t))
+		sb.WriteString(fmt.Sprintf("\"%p\" [label=\"%s\" color=\"%s\"];\n", p, p_name, p_color))
+		sb.WriteString(fmt.Sprintf("\"%p\" [label=\"%s\" color=\"%s\"];\n", t, t_name, t_color))
+
+		graphPrintRec(t.Child, t, sb, i+1)
+		graphPrintRec(t.Next, p, sb, i)
+	}
+	graphPrintRec(root, nil, &sb, 0)
+	sb.WriteString("}\n")
+
+	fmt.Fprint(f, sb.String())
+}
+
+
+func SprintToken[ValueType any](root *gopapageno.Token) string {
 	var sprintRec func(t *gopapageno.Token, sb *strings.Builder, indent string)
 
 	sprintRec = func(t *gopapageno.Token, sb *strings.Builder, indent string) {
@@ -75,19 +148,25 @@ func SprintToken[TokenValue any](root *gopapageno.Token) string {
 		default:
 			sb.WriteString("Unknown")
 		}
+
 		if t.Value != nil {
-			sb.WriteString(fmt.Sprintf(": %v", *t.Value.(*TokenValue)))
+			if v, ok := any(t.Value).(*ValueType); ok {
+				sb.WriteString(fmt.Sprintf(": %v", *v))
+			} else {
+				sb.WriteString(fmt.Sprintf("%v", v))
+			}
 		}
+		
 		sb.WriteString("\n")
-
+		
 		sprintRec(t.Child, sb, indent)
 		sprintRec(t.Next, sb, indent[:len(indent)-4])
 	}
 
 	var sb strings.Builder
-
+	
 	sprintRec(root, &sb, "")
-
+	
 	return sb.String()
 }
 
@@ -99,14 +178,11 @@ func NewGrammar() *gopapageno.Grammar {
 	rules := []gopapageno.Rule{
 		{S, []gopapageno.TokenType{E}, gopapageno.RuleSimple},
 		{E, []gopapageno.TokenType{E, PLUS, E}, gopapageno.RuleSimple},
-		{S, []gopapageno.TokenType{S}, gopapageno.RuleSimple},
 		{E, []gopapageno.TokenType{LPAR, E, RPAR}, gopapageno.RuleSimple},
 		{E, []gopapageno.TokenType{NUMBER}, gopapageno.RuleSimple},
 	}
-	compressedRules := []uint16{0, 0, 4, 1, 11, 2, 24, 32769, 27, 32770, 40, 2, 0, 1, 32771, 16, 0, 0, 1, 1, 21, 1, 1, 0, 2, 2, 0, 0, 0, 1, 1, 32, 0, 0, 1, 32772, 37, 1, 3, 0, 1, 4, 0}
+	compressedRules := []uint16{0, 0, 3, 1, 9, 32769, 22, 32770, 35, 2, 0, 1, 32771, 14, 0, 0, 1, 1, 19, 1, 1, 0, 0, 0, 1, 1, 27, 0, 0, 1, 32772, 32, 1, 2, 0, 1, 3, 0	}
 
-	maxPrefixLength := 0
-	prefixes := [][]gopapageno.TokenType{}
 	precMatrix := [][]gopapageno.Precedence{
 		{gopapageno.PrecEquals, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields},
 		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecEquals},
@@ -115,15 +191,26 @@ func NewGrammar() *gopapageno.Grammar {
 		{gopapageno.PrecTakes, gopapageno.PrecEquals, gopapageno.PrecEquals, gopapageno.PrecTakes, gopapageno.PrecTakes},
 	}
 	bitPackedMatrix := []uint64{
-		706666674870612,
+		706666674870612, 
 	}
 
-	fn := func(rule uint16, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int) {
-		var ruleType gopapageno.RuleFlags
-		switch rule {
-		case 0:
-			ruleType = gopapageno.RuleSimple
+	tokenNames := map[gopapageno.TokenType]string{
+		E: "E",
+		S: "S",
+		LPAR: "LPAR",
+		NUMBER: "NUMBER",
+		PLUS: "PLUS",
+		RPAR: "RPAR",
+	}
 
+	syncTerminals := []gopapageno.TokenType{}
+
+	fn := func(ruleDescription uint16, ruleFlags gopapageno.RuleFlags, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int, runState any){
+		state := runState.([]*gopapageno.Pool[int64])
+		_ = state
+
+		switch ruleDescription {
+		case 0:
 			S0 := lhs
 			E1 := rhs[0]
 
@@ -131,12 +218,10 @@ func NewGrammar() *gopapageno.Grammar {
 			S0.LastChild = E1
 
 			{
-				S0.Value = E1.Value
+			    S0.Value = E1.Value
 			}
 			_ = E1
 		case 1:
-			ruleType = gopapageno.RuleSimple
-
 			E0 := lhs
 			E1 := rhs[0]
 			PLUS2 := rhs[1]
@@ -148,29 +233,14 @@ func NewGrammar() *gopapageno.Grammar {
 			E0.LastChild = E3
 
 			{
-				newValue := parserPools[thread].Get()
-				*newValue = *E1.Value.(*int64) + *E3.Value.(*int64)
-				E0.Value = newValue
+			    newValue := state[thread].Get()
+			    *newValue = *E1.Value.(*int64) + *E3.Value.(*int64)
+			    E0.Value = newValue
 			}
 			_ = E1
 			_ = PLUS2
 			_ = E3
 		case 2:
-			ruleType = gopapageno.RuleSimple
-
-			S0 := lhs
-			S1 := rhs[0]
-
-			S0.Child = S1
-			S0.LastChild = S1
-
-			{
-				S0.Value = S1.Value
-			}
-			_ = S1
-		case 3:
-			ruleType = gopapageno.RuleSimple
-
 			E0 := lhs
 			LPAR1 := rhs[0]
 			E2 := rhs[1]
@@ -182,14 +252,12 @@ func NewGrammar() *gopapageno.Grammar {
 			E0.LastChild = RPAR3
 
 			{
-				E0.Value = E2.Value
+			    E0.Value = E2.Value
 			}
 			_ = LPAR1
 			_ = E2
 			_ = RPAR3
-		case 4:
-			ruleType = gopapageno.RuleSimple
-
+		case 3:
 			E0 := lhs
 			NUMBER1 := rhs[0]
 
@@ -197,25 +265,28 @@ func NewGrammar() *gopapageno.Grammar {
 			E0.LastChild = NUMBER1
 
 			{
-				E0.Value = NUMBER1.Value
+			    E0.Value = NUMBER1.Value
 			}
 			_ = NUMBER1
 		}
-		_ = ruleType
+		_ = ruleFlags
 	}
 
 	return &gopapageno.Grammar{
-		NumTerminals:              numTerminals,
-		NumNonterminals:           numNonTerminals,
-		MaxRHSLength:              maxRHSLen,
-		Rules:                     rules,
-		CompressedRules:           compressedRules,
-		PrecedenceMatrix:          precMatrix,
+		NumTerminals:  numTerminals,
+		NumNonterminals: numNonTerminals,
+		MaxRHSLength: maxRHSLen,
+		Rules: rules,
+		CompressedRules: compressedRules,
+		PrecedenceMatrix: precMatrix,
 		BitPackedPrecedenceMatrix: bitPackedMatrix,
-		MaxPrefixLength:           maxPrefixLength,
-		Prefixes:                  prefixes,
-		Func:                      fn,
-		ParsingStrategy:           gopapageno.AOPP,
-		PreambleFunc:              ParserPreallocMem,
+		TokenNames: tokenNames,
+		SyncTerminals: syncTerminals,
+		Func: fn,
+		ParsingStrategy: gopapageno.AOPP,
+		StateFunc: func(sourceLen, concurrency, _ int) any {
+			return ParserPreallocMem(sourceLen, concurrency)
+		},
 	}
 }
+
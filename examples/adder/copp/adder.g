@@ -13,7 +13,7 @@ E : (T PLUS)+ T
     var firstValue, secondValue int64
 
     if !ruleFlags.Has(gopapageno.RuleAppend) {
-        $$.Value = parserPools[thread].Get()
+        $$.Value = state[thread].Get()
 
         firstValue = *$1.Value.(*int64)
     } else {
@@ -41,15 +41,15 @@ import (
 	"math"
 )
 
-var parserPools []*gopapageno.Pool[int64]
-
-func ParserPreallocMem(inputSize int, numThreads int) {
-	parserPools = make([]*gopapageno.Pool[int64], numThreads)
+func ParserPreallocMem(inputSize int, numThreads int) []*gopapageno.Pool[int64] {
+	pools := make([]*gopapageno.Pool[int64], numThreads)
 
 	avgCharsPerNumber := float64(2)
 	poolSizePerThread := int(math.Ceil((float64(inputSize) / avgCharsPerNumber) / float64(numThreads)))
 
 	for i := 0; i < numThreads; i++ {
-		parserPools[i] = gopapageno.NewPool[int64](poolSizePerThread)
+		pools[i] = gopapageno.NewPool[int64](poolSizePerThread)
 	}
+
+	return pools
 }
\ No newline at end of file
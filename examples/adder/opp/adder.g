@@ -1,6 +1,8 @@
 %axiom S
-
-%preamble ParserPreallocMem
+%type S int64
+%type E int64
+%type T int64
+%type NUMBER int64
 
 %%
 
@@ -11,9 +13,7 @@ S : E
 
 E : E PLUS T
 {
-	newValue := parserPools[thread].Get()
-	*newValue = *$1.Value.(*int64) + *$3.Value.(*int64)
-	$$.Value = newValue
+	$$.Value = $1.Value + $3.Value
 } | T
 {
 	$$.Value = $1.Value
@@ -28,21 +28,3 @@ T : NUMBER
 };
 
 %%
-
-import (
-	"math"
-)
-
-var parserPools []*gopapageno.Pool[int64]
-
-func ParserPreallocMem(inputSize int, numThreads int) {
-	parserPools = make([]*gopapageno.Pool[int64], numThreads)
-
-	avgCharsPerNumber := float64(2)
-	poolSizePerThread := int(math.Ceil((float64(inputSize) / avgCharsPerNumber) / float64(numThreads)))
-
-	for i := 0; i < numThreads; i++ {
-		parserPools[i] = gopapageno.NewPool[int64](poolSizePerThread)
-
-	}
-}
\ No newline at end of file
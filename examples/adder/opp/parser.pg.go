@@ -2,28 +2,12 @@
 package main
 
 import (
-	"fmt"
 	"github.com/giornetta/gopapageno"
 	"strings"
+	"fmt"
+	"os"
 )
 
-import (
-	"math"
-)
-
-var parserPools []*gopapageno.Pool[int64]
-
-func ParserPreallocMem(inputSize int, numThreads int) {
-	parserPools = make([]*gopapageno.Pool[int64], numThreads)
-
-	avgCharsPerNumber := float64(2)
-	poolSizePerThread := int(math.Ceil((float64(inputSize) / avgCharsPerNumber) / float64(numThreads)))
-
-	for i := 0; i < numThreads; i++ {
-		parserPools[i] = gopapageno.NewPool[int64](poolSizePerThread)
-
-	}
-}
 
 // Non-terminals
 const (
@@ -40,6 +24,81 @@ const (
 	RPAR
 )
 
+func DumpGraph[ValueType any](root *gopapageno.Token, f *os.File) {
+	sb := strings.Builder{}
+	sb.WriteString("digraph parse_tree {\n")
+	sb.WriteString("ratio = fill;\n")
+	sb.WriteString("node [style=filled];\n")
+
+	var graphPrintRec func(t *gopapageno.Token, p *gopapageno.Token, sb *strings.Builder, i int)
+	graphPrintRec = func(t *gopapageno.Token, p *gopapageno.Token, sb *strings.Builder, i int) {
+		if t == nil {
+			return
+		}
+
+		if p == nil {
+			graphPrintRec(t.Child, t, sb, i+1)
+			return
+		}
+
+		var t_name, t_color, p_name, p_color string
+
+		switch p.Type {
+		case E:
+			p_name, p_color = "E", "0.408 0.498 1.000"
+		case E_T:
+			p_name, p_color = "E_T", "0.408 0.498 1.000"
+		case S:
+			p_name, p_color = "S", "0.408 0.498 1.000"
+		case gopapageno.TokenEmpty:
+			p_name, p_color = "__EMPTY__", "0.408 0.498 1.000"
+		case LPAR:
+			p_name, p_color = "LPAR", "0.641 0.212 1.000"
+		case NUMBER:
+			p_name, p_color = "NUMBER", "0.641 0.212 1.000"
+		case PLUS:
+			p_name, p_color = "PLUS", "0.641 0.212 1.000"
+		case RPAR:
+			p_name, p_color = "RPAR", "0.641 0.212 1.000"
+		case gopapageno.TokenTerm:
+			p_name, p_color = "__TERM__", "0.641 0.212 1.000"
+		}
+
+		switch t.Type {
+		case E:
+			t_name, t_color = "E", "0.408 0.498 1.000"
+		case E_T:
+			t_name, t_color = "E_T", "0.408 0.498 1.000"
+		case S:
+			t_name, t_color = "S", "0.408 0.498 1.000"
+		case gopapageno.TokenEmpty:
+			t_name, t_color = "__EMPTY__", "0.408 0.498 1.000"
+		case LPAR:
+			t_name, t_color = "LPAR", "0.641 0.212 1.000"
+		case NUMBER:
+			t_name, t_color = "NUMBER", "0.641 0.212 1.000"
+		case PLUS:
+			t_name, t_color = "PLUS", "0.641 0.212 1.000"
+		case RPAR:
+			t_name, t_color = "RPAR", "0.641 0.212 1.000"
+		case gopapageno.TokenTerm:
+			t_name, t_color = "__TERM__", "0.641 0.212 1.000"
+		}
+
+		sb.WriteString(fmt.Sprintf("\"%p\" -> \"%p\";\n", p, t))
+		sb.WriteString(fmt.Sprintf("\"%p\" [label=\"%s\" color=\"%s\"];\n", p, p_name, p_color))
+		sb.WriteString(fmt.Sprintf("\"%p\" [label=\"%s\" color=\"%s\"];\n", t, t_name, t_color))
+
+		graphPrintRec(t.Child, t, sb, i+1)
+		graphPrintRec(t.Next, p, sb, i)
+	}
+	graphPrintRec(root, nil, &sb, 0)
+	sb.WriteString("}\n")
+
+	fmt.Fprint(f, sb.String())
+}
+
+
 func SprintToken[ValueType any](root *gopapageno.Token) string {
 	var sprintRec func(t *gopapageno.Token, sb *strings.Builder, indent string)
 
@@ -83,21 +142,41 @@ func SprintToken[ValueType any](root *gopapageno.Token) string {
 		if t.Value != nil {
 			if v, ok := any(t.Value).(*ValueType); ok {
 				sb.WriteString(fmt.Sprintf(": %v", *v))
+			} else {
+				sb.WriteString(fmt.Sprintf("%v", v))
 			}
 		}
-
+		
 		sb.WriteString("\n")
-
+		
 		sprintRec(t.Child, sb, indent)
 		sprintRec(t.Next, sb, indent[:len(indent)-4])
 	}
 
 	var sb strings.Builder
-
+	
 	sprintRec(root, &sb, "")
-
+	
 	return sb.String()
 }
+// parserRunState holds the memory used by semantic actions during a single run.
+type parserRunState struct {
+	values0 []*gopapageno.Pool[int64]
+}
+
+func newParserRunState(sourceLen int, concurrency int, avgTokenLength int) *parserRunState {
+	s := &parserRunState{}
+
+	poolSizePerThread := sourceLen / avgTokenLength / concurrency
+
+	s.values0 = make([]*gopapageno.Pool[int64], concurrency)
+	for thread := 0; thread < concurrency; thread++ {
+		s.values0[thread] = gopapageno.NewPool[int64](poolSizePerThread)
+	}
+
+	return s
+}
+
 
 func NewGrammar() *gopapageno.Grammar {
 	numTerminals := uint16(5)
@@ -113,7 +192,7 @@ func NewGrammar() *gopapageno.Grammar {
 		{E_T, []gopapageno.TokenType{LPAR, E_T, RPAR}, gopapageno.RuleSimple},
 		{E_T, []gopapageno.TokenType{NUMBER}, gopapageno.RuleSimple},
 	}
-	compressedRules := []uint16{0, 0, 4, 1, 11, 2, 24, 32769, 37, 32770, 60, 3, 0, 1, 32771, 16, 0, 0, 1, 2, 21, 1, 1, 0, 3, 2, 1, 32771, 29, 0, 0, 1, 2, 34, 1, 3, 0, 0, 0, 2, 1, 44, 2, 52, 0, 0, 1, 32772, 49, 2, 4, 0, 0, 0, 1, 32772, 57, 2, 5, 0, 2, 6, 0}
+	compressedRules := []uint16{0, 0, 4, 1, 11, 2, 24, 32769, 37, 32770, 60, 3, 0, 1, 32771, 16, 0, 0, 1, 2, 21, 1, 1, 0, 3, 2, 1, 32771, 29, 0, 0, 1, 2, 34, 1, 3, 0, 0, 0, 2, 1, 44, 2, 52, 0, 0, 1, 32772, 49, 2, 4, 0, 0, 0, 1, 32772, 57, 2, 5, 0, 2, 6, 0	}
 
 	precMatrix := [][]gopapageno.Precedence{
 		{gopapageno.PrecEquals, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields},
@@ -123,10 +202,25 @@ func NewGrammar() *gopapageno.Grammar {
 		{gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecTakes, gopapageno.PrecTakes},
 	}
 	bitPackedMatrix := []uint64{
-		706597955393876,
+		706597955393876, 
+	}
+
+	tokenNames := map[gopapageno.TokenType]string{
+		E: "E",
+		E_T: "E_T",
+		S: "S",
+		LPAR: "LPAR",
+		NUMBER: "NUMBER",
+		PLUS: "PLUS",
+		RPAR: "RPAR",
 	}
 
-	fn := func(ruleDescription uint16, ruleFlags gopapageno.RuleFlags, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int) {
+	syncTerminals := []gopapageno.TokenType{}
+
+	fn := func(ruleDescription uint16, ruleFlags gopapageno.RuleFlags, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int, runState any){
+		rs := runState.(*parserRunState)
+		_ = rs
+
 		switch ruleDescription {
 		case 0:
 			S0 := lhs
@@ -150,11 +244,15 @@ func NewGrammar() *gopapageno.Grammar {
 			PLUS2.Next = E_T3
 			E0.LastChild = E_T3
 
+			E0Value := gopapageno.ValueOf[int64](E0)
+			E1Value := gopapageno.ValueOf[int64](E1)
+			E_T3Value := gopapageno.ValueOf[int64](E_T3)
 			{
-				newValue := parserPools[thread].Get()
-				*newValue = *E1.Value.(*int64) + *E_T3.Value.(*int64)
-				E0.Value = newValue
+				E0Value = E1Value + E_T3Value
 			}
+			_ = E1Value
+			_ = E_T3Value
+			gopapageno.SetValue(E0, E0Value, rs.values0[thread])
 			_ = E1
 			_ = PLUS2
 			_ = E_T3
@@ -180,11 +278,15 @@ func NewGrammar() *gopapageno.Grammar {
 			PLUS2.Next = E_T3
 			E0.LastChild = E_T3
 
+			E0Value := gopapageno.ValueOf[int64](E0)
+			E_T1Value := gopapageno.ValueOf[int64](E_T1)
+			E_T3Value := gopapageno.ValueOf[int64](E_T3)
 			{
-				newValue := parserPools[thread].Get()
-				*newValue = *E_T1.Value.(*int64) + *E_T3.Value.(*int64)
-				E0.Value = newValue
+				E0Value = E_T1Value + E_T3Value
 			}
+			_ = E_T1Value
+			_ = E_T3Value
+			gopapageno.SetValue(E0, E0Value, rs.values0[thread])
 			_ = E_T1
 			_ = PLUS2
 			_ = E_T3
@@ -199,9 +301,13 @@ func NewGrammar() *gopapageno.Grammar {
 			E2.Next = RPAR3
 			E_T0.LastChild = RPAR3
 
+			E_T0Value := gopapageno.ValueOf[int64](E_T0)
+			E2Value := gopapageno.ValueOf[int64](E2)
 			{
-				E_T0.Value = E2.Value
+			    E_T0Value = E2Value
 			}
+			_ = E2Value
+			gopapageno.SetValue(E_T0, E_T0Value, rs.values0[thread])
 			_ = LPAR1
 			_ = E2
 			_ = RPAR3
@@ -216,9 +322,13 @@ func NewGrammar() *gopapageno.Grammar {
 			E_T2.Next = RPAR3
 			E_T0.LastChild = RPAR3
 
+			E_T0Value := gopapageno.ValueOf[int64](E_T0)
+			E_T2Value := gopapageno.ValueOf[int64](E_T2)
 			{
-				E_T0.Value = E_T2.Value
+			    E_T0Value = E_T2Value
 			}
+			_ = E_T2Value
+			gopapageno.SetValue(E_T0, E_T0Value, rs.values0[thread])
 			_ = LPAR1
 			_ = E_T2
 			_ = RPAR3
@@ -238,15 +348,20 @@ func NewGrammar() *gopapageno.Grammar {
 	}
 
 	return &gopapageno.Grammar{
-		NumTerminals:              numTerminals,
-		NumNonterminals:           numNonTerminals,
-		MaxRHSLength:              maxRHSLen,
-		Rules:                     rules,
-		CompressedRules:           compressedRules,
-		PrecedenceMatrix:          precMatrix,
+		NumTerminals:  numTerminals,
+		NumNonterminals: numNonTerminals,
+		MaxRHSLength: maxRHSLen,
+		Rules: rules,
+		CompressedRules: compressedRules,
+		PrecedenceMatrix: precMatrix,
 		BitPackedPrecedenceMatrix: bitPackedMatrix,
-		Func:                      fn,
-		ParsingStrategy:           gopapageno.OPP,
-		PreambleFunc:              ParserPreallocMem,
+		TokenNames: tokenNames,
+		SyncTerminals: syncTerminals,
+		Func: fn,
+		ParsingStrategy: gopapageno.OPP,
+		StateFunc: func(sourceLen, concurrency, avgTokenLength int) any {
+			return newParserRunState(sourceLen, concurrency, avgTokenLength)
+		},
 	}
 }
+
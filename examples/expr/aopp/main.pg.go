@@ -27,9 +27,11 @@ func run() error {
 	strategyFlag := flag.String("s", "sweep", "parsing strategy to execute")
 	logFlag := flag.Bool("log", false, "enable logging")
 	avgTokensFlag := flag.Int("avg", 4, "average length of tokens")
-
+	parallelFactorFlag := flag.Float64("pf", 4, "parallelism factor of the source text (0, 1]")
 	cpuProfileFlag := flag.String("cpuprof", "", "output file for CPU profiling")
 	memProfileFlag := flag.String("memprof", "", "output file for Memory profiling")
+	dumpGraphFlag := flag.String("graph", "", "output graphviz dot file of the AST")
+	maxErrorsFlag := flag.Int("errors", 0, "recover from syntax errors, reporting at most this many of them (0 disables recovery)")
 
 	flag.Parse()
 
@@ -63,30 +65,42 @@ func run() error {
 	if *strategyFlag == "parallel" {
 		strat = gopapageno.ReductionParallel
 	} else if *strategyFlag == "mixed" {
-		strat = gopapageno.ReductionMixed
+		strat = gopapageno.ReductionMixed	
 	}
 
-	r := gopapageno.NewRunner(
-		NewLexer(),
-		NewGrammar(),
+	runnerOpts := []gopapageno.RunnerOpt{
 		gopapageno.WithConcurrency(*concurrencyFlag),
 		gopapageno.WithLogging(log.New(logOut, "", 0)),
 		gopapageno.WithCPUProfiling(cpuProfileWriter),
 		gopapageno.WithMemoryProfiling(memProfileWriter),
 		gopapageno.WithReductionStrategy(strat),
 		gopapageno.WithAverageTokenLength(*avgTokensFlag),
-	)
+		gopapageno.WithParallelFactor(*parallelFactorFlag),
+		gopapageno.WithGarbageCollection(false),
+	}
+
+	if *maxErrorsFlag > 0 {
+		runnerOpts = append(runnerOpts,
+			gopapageno.WithErrorRecovery(*maxErrorsFlag),
+			gopapageno.WithDiagnostics(func(err error) {
+				fmt.Fprintln(os.Stderr, err)
+			}),
+		)
+	}
+
+	r := gopapageno.NewRunner(NewLexer(), NewGrammar(), runnerOpts...)
 
 	ctx := context.Background()
 
+	// When recovering from errors, a partial parse tree may be returned alongside them.
 	root, err := r.Run(ctx, bytes)
-	if err != nil {
+	if err != nil && root == nil {
 		return fmt.Errorf("could not parse source: %w", err)
 	}
 
 	fmt.Printf("Parsing took: %v\n", time.Since(start))
-	// fmt.Printf("Result: %v\n", *root.Value.(*int64))
-
+	// fmt.Printf("Result: %v\n", root.Value)
+	
 	h := root.Height()
 	s := root.Size()
 	fmt.Printf("Height: %d\nSize: %d\n", h, s)
@@ -94,5 +108,12 @@ func run() error {
 		fmt.Println(SprintToken[any](root))
 	}
 
+	if *dumpGraphFlag != "" {
+		f, err := os.Create(*dumpGraphFlag)
+		if err == nil {
+			DumpGraph[any](root, f)
+		}
+	}
+
 	return nil
 }
@@ -2,9 +2,10 @@
 package main
 
 import (
-	"fmt"
 	"github.com/giornetta/gopapageno"
 	"strings"
+	"fmt"
+	"os"
 )
 
 import (
@@ -25,6 +26,7 @@ func ParserPreallocMem(inputSize int, numThreads int) {
 	}
 }
 
+
 // Non-terminals
 const (
 	D_E_P_T = gopapageno.TokenEmpty + 1 + iota
@@ -42,7 +44,90 @@ const (
 	RPAR
 )
 
-func SprintToken[TokenValue any](root *gopapageno.Token) string {
+func DumpGraph[ValueType any](root *gopapageno.Token, f *os.File) {
+	sb := strings.Builder{}
+	sb.WriteString("digraph parse_tree {\n")
+	sb.WriteString("ratio = fill;\n")
+	sb.WriteString("node [style=filled];\n")
+
+	var graphPrintRec func(t *gopapageno.Token, p *gopapageno.Token, sb *strings.Builder, i int)
+	graphPrintRec = func(t *gopapageno.Token, p *gopapageno.Token, sb *strings.Builder, i int) {
+		if t == nil {
+			return
+		}
+
+		if p == nil {
+			graphPrintRec(t.Child, t, sb, i+1)
+			return
+		}
+
+		var t_name, t_color, p_name, p_color string
+
+		switch p.Type {
+		case D_E_P_T:
+			p_name, p_color = "D_E_P_T", "0.408 0.498 1.000"
+		case D_T:
+			p_name, p_color = "D_T", "0.408 0.498 1.000"
+		case P:
+			p_name, p_color = "P", "0.408 0.498 1.000"
+		case S:
+			p_name, p_color = "S", "0.408 0.498 1.000"
+		case gopapageno.TokenEmpty:
+			p_name, p_color = "__EMPTY__", "0.408 0.498 1.000"
+		case DIVIDE:
+			p_name, p_color = "DIVIDE", "0.641 0.212 1.000"
+		case LPAR:
+			p_name, p_color = "LPAR", "0.641 0.212 1.000"
+		case NUMBER:
+			p_name, p_color = "NUMBER", "0.641 0.212 1.000"
+		case PLUS:
+			p_name, p_color = "PLUS", "0.641 0.212 1.000"
+		case RPAR:
+			p_name, p_color = "RPAR", "0.641 0.212 1.000"
+		case gopapageno.TokenTerm:
+			p_name, p_color = "__TERM__", "0.641 0.212 1.000"
+		}
+
+		switch t.Type {
+		case D_E_P_T:
+			t_name, t_color = "D_E_P_T", "0.408 0.498 1.000"
+		case D_T:
+			t_name, t_color = "D_T", "0.408 0.498 1.000"
+		case P:
+			t_name, t_color = "P", "0.408 0.498 1.000"
+		case S:
+			t_name, t_color = "S", "0.408 0.498 1.000"
+		case gopapageno.TokenEmpty:
+			t_name, t_color = "__EMPTY__", "0.408 0.498 1.000"
+		case DIVIDE:
+			t_name, t_color = "DIVIDE", "0.641 0.212 1.000"
+		case LPAR:
+			t_name, t_color = "LPAR", "0.641 0.212 1.000"
+		case NUMBER:
+			t_name, t_color = "NUMBER", "0.641 0.212 1.000"
+		case PLUS:
+			t_name, t_color = "PLUS", "0.641 0.212 1.000"
+		case RPAR:
+			t_name, t_color = "RPAR", "0.641 0.212 1.000"
+		case gopapageno.TokenTerm:
+			t_name, t_color = "__TERM__", "0.641 0.212 1.000"
+		}
+
+		sb.WriteString(fmt.Sprintf("\"%p\" -> \"%p\";\n", p, t))
+		sb.WriteString(fmt.Sprintf("\"%p\" [label=\"%s\" color=\"%s\"];\n", p, p_name, p_color))
+		sb.WriteString(fmt.Sprintf("\"%p\" [label=\"%s\" color=\"%s\"];\n", t, t_name, t_color))
+
+		graphPrintRec(t.Child, t, sb, i+1)
+		graphPrintRec(t.Next, p, sb, i)
+	}
+	graphPrintRec(root, nil, &sb, 0)
+	sb.WriteString("}\n")
+
+	fmt.Fprint(f, sb.String())
+}
+
+
+func SprintToken[ValueType any](root *gopapageno.Token) string {
 	var sprintRec func(t *gopapageno.Token, sb *strings.Builder, indent string)
 
 	sprintRec = func(t *gopapageno.Token, sb *strings.Builder, indent string) {
@@ -85,19 +170,25 @@ func SprintToken[TokenValue any](root *gopapageno.Token) string {
 		default:
 			sb.WriteString("Unknown")
 		}
+
 		if t.Value != nil {
-			sb.WriteString(fmt.Sprintf(": %v", *t.Value.(*TokenValue)))
+			if v, ok := any(t.Value).(*ValueType); ok {
+				sb.WriteString(fmt.Sprintf(": %v", *v))
+			} else {
+				sb.WriteString(fmt.Sprintf("%v", v))
+			}
 		}
+		
 		sb.WriteString("\n")
-
+		
 		sprintRec(t.Child, sb, indent)
 		sprintRec(t.Next, sb, indent[:len(indent)-4])
 	}
 
 	var sb strings.Builder
-
+	
 	sprintRec(root, &sb, "")
-
+	
 	return sb.String()
 }
 
@@ -111,44 +202,37 @@ func NewGrammar() *gopapageno.Grammar {
 		{D_T, []gopapageno.TokenType{D_E_P_T, DIVIDE, D_E_P_T}, gopapageno.RuleSimple},
 		{P, []gopapageno.TokenType{D_E_P_T, PLUS, D_E_P_T}, gopapageno.RuleCyclic},
 		{P, []gopapageno.TokenType{D_E_P_T, PLUS, D_T}, gopapageno.RuleCyclic},
-		{P, []gopapageno.TokenType{D_E_P_T, PLUS, P}, gopapageno.RuleAppendLeft},
-		{P, []gopapageno.TokenType{D_E_P_T, PLUS, P}, gopapageno.RuleAppendLeft},
 		{S, []gopapageno.TokenType{D_T}, gopapageno.RuleSimple},
 		{D_T, []gopapageno.TokenType{D_T, DIVIDE, D_E_P_T}, gopapageno.RuleSimple},
 		{P, []gopapageno.TokenType{D_T, PLUS, D_E_P_T}, gopapageno.RuleCyclic},
 		{P, []gopapageno.TokenType{D_T, PLUS, D_T}, gopapageno.RuleCyclic},
-		{P, []gopapageno.TokenType{D_T, PLUS, P}, gopapageno.RuleAppendLeft},
-		{P, []gopapageno.TokenType{D_T, PLUS, P}, gopapageno.RuleAppendLeft},
 		{S, []gopapageno.TokenType{P}, gopapageno.RuleSimple},
-		{P, []gopapageno.TokenType{P, PLUS, D_E_P_T}, gopapageno.RuleAppend},
-		{P, []gopapageno.TokenType{P, PLUS, D_E_P_T}, gopapageno.RuleAppend},
-		{P, []gopapageno.TokenType{P, PLUS, D_T}, gopapageno.RuleAppend},
-		{P, []gopapageno.TokenType{P, PLUS, D_T}, gopapageno.RuleAppend},
-		{P, []gopapageno.TokenType{P, PLUS, P}, gopapageno.RuleCombine},
-		{P, []gopapageno.TokenType{P, PLUS, P}, gopapageno.RuleCombine},
-		{P, []gopapageno.TokenType{P, PLUS, P}, gopapageno.RuleCombine},
-		{P, []gopapageno.TokenType{P, PLUS, P}, gopapageno.RuleCombine},
-		{S, []gopapageno.TokenType{S}, gopapageno.RuleSimple},
 		{D_E_P_T, []gopapageno.TokenType{LPAR, S, RPAR}, gopapageno.RuleSimple},
 		{D_E_P_T, []gopapageno.TokenType{NUMBER}, gopapageno.RuleSimple},
 	}
-	compressedRules := []uint16{0, 0, 6, 1, 15, 2, 48, 3, 81, 4, 104, 32770, 107, 32771, 120, 4, 0, 2, 32769, 22, 32772, 30, 0, 0, 1, 1, 27, 2, 1, 0, 0, 0, 3, 1, 39, 2, 42, 3, 45, 3, 2, 0, 3, 3, 0, 3, 5, 0, 4, 6, 2, 32769, 55, 32772, 63, 0, 0, 1, 1, 60, 2, 7, 0, 0, 0, 3, 1, 72, 2, 75, 3, 78, 3, 8, 0, 3, 9, 0, 3, 11, 0, 4, 12, 1, 32772, 86, 0, 0, 3, 1, 95, 2, 98, 3, 101, 3, 14, 0, 3, 16, 0, 3, 20, 0, 4, 21, 0, 0, 0, 1, 4, 112, 0, 0, 1, 32773, 117, 1, 22, 0, 1, 23, 0}
+	compressedRules := []uint16{0, 0, 5, 1, 13, 2, 41, 3, 69, 32770, 72, 32771, 85, 4, 0, 2, 32769, 20, 32772, 28, 0, 0, 1, 1, 25, 2, 1, 0, 0, 0, 2, 1, 35, 2, 38, 3, 2, 0, 3, 3, 0, 4, 4, 2, 32769, 48, 32772, 56, 0, 0, 1, 1, 53, 2, 5, 0, 0, 0, 2, 1, 63, 2, 66, 3, 6, 0, 3, 7, 0, 4, 8, 0, 0, 0, 1, 4, 77, 0, 0, 1, 32773, 82, 1, 9, 0, 1, 10, 0	}
 
-	maxPrefixLength := 5
+	maxPrefixLength := 4
 	prefixes := [][]gopapageno.TokenType{
-		{D_E_P_T, PLUS, D_E_P_T},
-		{D_E_P_T, PLUS, D_T},
-		{D_T, PLUS, D_E_P_T},
-		{D_T, PLUS, D_T},
-		{D_E_P_T, PLUS, D_E_P_T, PLUS, D_E_P_T},
-		{D_E_P_T, PLUS, D_E_P_T, PLUS, D_T},
-		{D_E_P_T, PLUS, D_T, PLUS, D_E_P_T},
-		{D_E_P_T, PLUS, D_T, PLUS, D_T},
-		{D_T, PLUS, D_E_P_T, PLUS, D_E_P_T},
-		{D_T, PLUS, D_E_P_T, PLUS, D_T},
-		{D_T, PLUS, D_T, PLUS, D_E_P_T},
-		{D_T, PLUS, D_T, PLUS, D_T},
+		{D_E_P_T, PLUS, D_E_P_T, PLUS},
+		{D_E_P_T, PLUS, D_T, PLUS},
+		{D_T, PLUS, D_E_P_T, PLUS},
+		{D_T, PLUS, D_T, PLUS},
+		{D_E_P_T, PLUS, D_E_P_T, PLUS},
+		{D_E_P_T, PLUS, D_T, PLUS},
+		{D_T, PLUS, D_E_P_T, PLUS},
+		{D_T, PLUS, D_T, PLUS},
+		{D_E_P_T, PLUS, D_E_P_T, PLUS},
+		{D_E_P_T, PLUS, D_T, PLUS},
+		{D_T, PLUS, D_E_P_T, PLUS},
+		{D_T, PLUS, D_T, PLUS},
+		{D_E_P_T, PLUS, D_E_P_T, PLUS},
+		{D_E_P_T, PLUS, D_T, PLUS},
+		{D_T, PLUS, D_E_P_T, PLUS},
+		{D_T, PLUS, D_T, PLUS},
 	}
+	compressedPrefixes := []uint16{0, 0, 2, 1, 7, 2, 35, 0, 0, 1, 32772, 12, 0, 0, 2, 1, 19, 2, 27, 0, 0, 1, 32772, 24, 3, 7, 0, 0, 0, 1, 32772, 32, 3, 7, 0, 0, 0, 1, 32772, 40, 0, 0, 2, 1, 47, 2, 55, 0, 0, 1, 32772, 52, 3, 7, 0, 0, 0, 1, 32772, 60, 3, 7, 0	}
+
 	precMatrix := [][]gopapageno.Precedence{
 		{gopapageno.PrecEquals, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields},
 		{gopapageno.PrecTakes, gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecTakes, gopapageno.PrecTakes},
@@ -158,15 +242,26 @@ func NewGrammar() *gopapageno.Grammar {
 		{gopapageno.PrecTakes, gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecTakes, gopapageno.PrecTakes},
 	}
 	bitPackedMatrix := []uint64{
-		12130059261172884820, 160,
+		12130059261172884820, 160, 
 	}
 
-	fn := func(rule uint16, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int) {
-		var ruleType gopapageno.RuleFlags
-		switch rule {
-		case 0:
-			ruleType = gopapageno.RuleSimple
+	tokenNames := map[gopapageno.TokenType]string{
+		D_E_P_T: "D_E_P_T",
+		D_T: "D_T",
+		P: "P",
+		S: "S",
+		DIVIDE: "DIVIDE",
+		LPAR: "LPAR",
+		NUMBER: "NUMBER",
+		PLUS: "PLUS",
+		RPAR: "RPAR",
+	}
+
+	syncTerminals := []gopapageno.TokenType{}
 
+	fn := func(ruleDescription uint16, ruleFlags gopapageno.RuleFlags, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int, runState any){
+		switch ruleDescription {
+		case 0:
 			S0 := lhs
 			D_E_P_T1 := rhs[0]
 
@@ -178,8 +273,6 @@ func NewGrammar() *gopapageno.Grammar {
 			}
 			_ = D_E_P_T1
 		case 1:
-			ruleType = gopapageno.RuleSimple
-
 			D_T0 := lhs
 			D_E_P_T1 := rhs[0]
 			DIVIDE2 := rhs[1]
@@ -191,25 +284,35 @@ func NewGrammar() *gopapageno.Grammar {
 			D_T0.LastChild = D_E_P_T3
 
 			{
-				newValue := parserInt64Pools[thread].Get()
-				*newValue = *D_E_P_T1.Value.(*int64) / *D_E_P_T3.Value.(*int64)
-				D_T0.Value = newValue
+			    newValue := parserInt64Pools[thread].Get()
+			    *newValue = *D_E_P_T1.Value.(*int64) / *D_E_P_T3.Value.(*int64)
+			    D_T0.Value = newValue
 			}
 			_ = D_E_P_T1
 			_ = DIVIDE2
 			_ = D_E_P_T3
 		case 2:
-			ruleType = gopapageno.RuleCyclic
-
 			P0 := lhs
 			D_E_P_T1 := rhs[0]
 			PLUS2 := rhs[1]
 			D_E_P_T3 := rhs[2]
 
-			P0.Child = D_E_P_T1
-			D_E_P_T1.Next = PLUS2
+			if ruleFlags.Has(gopapageno.RuleAppend) {
+				P0.LastChild.Next = PLUS2
+			} else {
+				P0.Child = D_E_P_T1
+				D_E_P_T1.Next = PLUS2
+			}
+
 			PLUS2.Next = D_E_P_T3
-			P0.LastChild = D_E_P_T3
+
+			if ruleFlags.Has(gopapageno.RuleCombine) {
+				PLUS2.Next = D_E_P_T3.Child
+				P0.LastChild = D_E_P_T3.LastChild
+			} else {
+				PLUS2.Next = D_E_P_T3
+				P0.LastChild = D_E_P_T3
+			}
 
 			{
 				newValue := parserInt64Pools[thread].Get()
@@ -220,73 +323,37 @@ func NewGrammar() *gopapageno.Grammar {
 			_ = PLUS2
 			_ = D_E_P_T3
 		case 3:
-			ruleType = gopapageno.RuleCyclic
-
 			P0 := lhs
 			D_E_P_T1 := rhs[0]
 			PLUS2 := rhs[1]
 			D_T3 := rhs[2]
 
-			P0.Child = D_E_P_T1
-			D_E_P_T1.Next = PLUS2
-			PLUS2.Next = D_T3
-			P0.LastChild = D_T3
-
-			{
-				newValue := parserInt64Pools[thread].Get()
-				*newValue = *D_E_P_T1.Value.(*int64) + *D_T3.Value.(*int64)
-				P0.Value = newValue
+			if ruleFlags.Has(gopapageno.RuleAppend) {
+				P0.LastChild.Next = PLUS2
+			} else {
+				P0.Child = D_E_P_T1
+				D_E_P_T1.Next = PLUS2
 			}
-			_ = D_E_P_T1
-			_ = PLUS2
-			_ = D_T3
-		case 4:
-			ruleType = gopapageno.RuleAppendLeft
-
-			P0 := lhs
-			D_E_P_T1 := rhs[0]
-			PLUS2 := rhs[1]
-			P3 := rhs[2]
 
-			oldChild := P0
-			P0.Child = D_E_P_T1
-			D_E_P_T1.Next = PLUS2
-			PLUS2.Next = P3
-			P3.Next = oldChild
+			PLUS2.Next = D_T3
 
-			{
-				newValue := parserInt64Pools[thread].Get()
-				*newValue = *D_E_P_T1.Value.(*int64) + *P3.Value.(*int64)
-				P0.Value = newValue
+			if ruleFlags.Has(gopapageno.RuleCombine) {
+				PLUS2.Next = D_T3.Child
+				P0.LastChild = D_T3.LastChild
+			} else {
+				PLUS2.Next = D_T3
+				P0.LastChild = D_T3
 			}
-			_ = D_E_P_T1
-			_ = PLUS2
-			_ = P3
-		case 5:
-			ruleType = gopapageno.RuleAppendLeft
-
-			P0 := lhs
-			D_E_P_T1 := rhs[0]
-			PLUS2 := rhs[1]
-			P3 := rhs[2]
-
-			oldChild := P0
-			P0.Child = D_E_P_T1
-			D_E_P_T1.Next = PLUS2
-			PLUS2.Next = P3
-			P3.Next = oldChild
 
 			{
 				newValue := parserInt64Pools[thread].Get()
-				*newValue = *D_E_P_T1.Value.(*int64) + *P3.Value.(*int64)
+				*newValue = *D_E_P_T1.Value.(*int64) + *D_T3.Value.(*int64)
 				P0.Value = newValue
 			}
 			_ = D_E_P_T1
 			_ = PLUS2
-			_ = P3
-		case 6:
-			ruleType = gopapageno.RuleSimple
-
+			_ = D_T3
+		case 4:
 			S0 := lhs
 			D_T1 := rhs[0]
 
@@ -294,12 +361,10 @@ func NewGrammar() *gopapageno.Grammar {
 			S0.LastChild = D_T1
 
 			{
-				S0.Value = D_T1.Value
+			    S0.Value = D_T1.Value
 			}
 			_ = D_T1
-		case 7:
-			ruleType = gopapageno.RuleSimple
-
+		case 5:
 			D_T0 := lhs
 			D_T1 := rhs[0]
 			DIVIDE2 := rhs[1]
@@ -311,25 +376,35 @@ func NewGrammar() *gopapageno.Grammar {
 			D_T0.LastChild = D_E_P_T3
 
 			{
-				newValue := parserInt64Pools[thread].Get()
-				*newValue = *D_T1.Value.(*int64) / *D_E_P_T3.Value.(*int64)
-				D_T0.Value = newValue
+			    newValue := parserInt64Pools[thread].Get()
+			    *newValue = *D_T1.Value.(*int64) / *D_E_P_T3.Value.(*int64)
+			    D_T0.Value = newValue
 			}
 			_ = D_T1
 			_ = DIVIDE2
 			_ = D_E_P_T3
-		case 8:
-			ruleType = gopapageno.RuleCyclic
-
+		case 6:
 			P0 := lhs
 			D_T1 := rhs[0]
 			PLUS2 := rhs[1]
 			D_E_P_T3 := rhs[2]
 
-			P0.Child = D_T1
-			D_T1.Next = PLUS2
+			if ruleFlags.Has(gopapageno.RuleAppend) {
+				P0.LastChild.Next = PLUS2
+			} else {
+				P0.Child = D_T1
+				D_T1.Next = PLUS2
+			}
+
 			PLUS2.Next = D_E_P_T3
-			P0.LastChild = D_E_P_T3
+
+			if ruleFlags.Has(gopapageno.RuleCombine) {
+				PLUS2.Next = D_E_P_T3.Child
+				P0.LastChild = D_E_P_T3.LastChild
+			} else {
+				PLUS2.Next = D_E_P_T3
+				P0.LastChild = D_E_P_T3
+			}
 
 			{
 				newValue := parserInt64Pools[thread].Get()
@@ -339,74 +414,38 @@ func NewGrammar() *gopapageno.Grammar {
 			_ = D_T1
 			_ = PLUS2
 			_ = D_E_P_T3
-		case 9:
-			ruleType = gopapageno.RuleCyclic
-
+		case 7:
 			P0 := lhs
 			D_T1 := rhs[0]
 			PLUS2 := rhs[1]
 			D_T3 := rhs[2]
 
-			P0.Child = D_T1
-			D_T1.Next = PLUS2
-			PLUS2.Next = D_T3
-			P0.LastChild = D_T3
-
-			{
-				newValue := parserInt64Pools[thread].Get()
-				*newValue = *D_T1.Value.(*int64) + *D_T3.Value.(*int64)
-				P0.Value = newValue
+			if ruleFlags.Has(gopapageno.RuleAppend) {
+				P0.LastChild.Next = PLUS2
+			} else {
+				P0.Child = D_T1
+				D_T1.Next = PLUS2
 			}
-			_ = D_T1
-			_ = PLUS2
-			_ = D_T3
-		case 10:
-			ruleType = gopapageno.RuleAppendLeft
-
-			P0 := lhs
-			D_T1 := rhs[0]
-			PLUS2 := rhs[1]
-			P3 := rhs[2]
 
-			oldChild := P0
-			P0.Child = D_T1
-			D_T1.Next = PLUS2
-			PLUS2.Next = P3
-			P3.Next = oldChild
+			PLUS2.Next = D_T3
 
-			{
-				newValue := parserInt64Pools[thread].Get()
-				*newValue = *D_T1.Value.(*int64) + *P3.Value.(*int64)
-				P0.Value = newValue
+			if ruleFlags.Has(gopapageno.RuleCombine) {
+				PLUS2.Next = D_T3.Child
+				P0.LastChild = D_T3.LastChild
+			} else {
+				PLUS2.Next = D_T3
+				P0.LastChild = D_T3
 			}
-			_ = D_T1
-			_ = PLUS2
-			_ = P3
-		case 11:
-			ruleType = gopapageno.RuleAppendLeft
-
-			P0 := lhs
-			D_T1 := rhs[0]
-			PLUS2 := rhs[1]
-			P3 := rhs[2]
-
-			oldChild := P0
-			P0.Child = D_T1
-			D_T1.Next = PLUS2
-			PLUS2.Next = P3
-			P3.Next = oldChild
 
 			{
 				newValue := parserInt64Pools[thread].Get()
-				*newValue = *D_T1.Value.(*int64) + *P3.Value.(*int64)
+				*newValue = *D_T1.Value.(*int64) + *D_T3.Value.(*int64)
 				P0.Value = newValue
 			}
 			_ = D_T1
 			_ = PLUS2
-			_ = P3
-		case 12:
-			ruleType = gopapageno.RuleSimple
-
+			_ = D_T3
+		case 8:
 			S0 := lhs
 			P1 := rhs[0]
 
@@ -417,182 +456,7 @@ func NewGrammar() *gopapageno.Grammar {
 				S0.Value = P1.Value
 			}
 			_ = P1
-		case 13:
-			ruleType = gopapageno.RuleAppend
-
-			P0 := lhs
-			P1 := rhs[0]
-			PLUS2 := rhs[1]
-			D_E_P_T3 := rhs[2]
-
-			P0.LastChild.Next = PLUS2
-			PLUS2.Next = D_E_P_T3
-			P0.LastChild = D_E_P_T3
-
-			{
-				newValue := parserInt64Pools[thread].Get()
-				*newValue = *P1.Value.(*int64) + *D_E_P_T3.Value.(*int64)
-				P0.Value = newValue
-			}
-			_ = P1
-			_ = PLUS2
-			_ = D_E_P_T3
-		case 14:
-			ruleType = gopapageno.RuleAppend
-
-			P0 := lhs
-			P1 := rhs[0]
-			PLUS2 := rhs[1]
-			D_E_P_T3 := rhs[2]
-
-			P0.LastChild.Next = PLUS2
-			PLUS2.Next = D_E_P_T3
-			P0.LastChild = D_E_P_T3
-
-			{
-				newValue := parserInt64Pools[thread].Get()
-				*newValue = *P1.Value.(*int64) + *D_E_P_T3.Value.(*int64)
-				P0.Value = newValue
-			}
-			_ = P1
-			_ = PLUS2
-			_ = D_E_P_T3
-		case 15:
-			ruleType = gopapageno.RuleAppend
-
-			P0 := lhs
-			P1 := rhs[0]
-			PLUS2 := rhs[1]
-			D_T3 := rhs[2]
-
-			P0.LastChild.Next = PLUS2
-			PLUS2.Next = D_T3
-			P0.LastChild = D_T3
-
-			{
-				newValue := parserInt64Pools[thread].Get()
-				*newValue = *P1.Value.(*int64) + *D_T3.Value.(*int64)
-				P0.Value = newValue
-			}
-			_ = P1
-			_ = PLUS2
-			_ = D_T3
-		case 16:
-			ruleType = gopapageno.RuleAppend
-
-			P0 := lhs
-			P1 := rhs[0]
-			PLUS2 := rhs[1]
-			D_T3 := rhs[2]
-
-			P0.LastChild.Next = PLUS2
-			PLUS2.Next = D_T3
-			P0.LastChild = D_T3
-
-			{
-				newValue := parserInt64Pools[thread].Get()
-				*newValue = *P1.Value.(*int64) + *D_T3.Value.(*int64)
-				P0.Value = newValue
-			}
-			_ = P1
-			_ = PLUS2
-			_ = D_T3
-		case 17:
-			ruleType = gopapageno.RuleCombine
-
-			P0 := lhs
-			P1 := rhs[0]
-			PLUS2 := rhs[1]
-			P3 := rhs[2]
-
-			P0.LastChild.Next = PLUS2
-			PLUS2.Next = P3.Child
-			P0.LastChild = P3.LastChild
-
-			{
-				newValue := parserInt64Pools[thread].Get()
-				*newValue = *P1.Value.(*int64) + *P3.Value.(*int64)
-				P0.Value = newValue
-			}
-			_ = P1
-			_ = PLUS2
-			_ = P3
-		case 18:
-			ruleType = gopapageno.RuleCombine
-
-			P0 := lhs
-			P1 := rhs[0]
-			PLUS2 := rhs[1]
-			P3 := rhs[2]
-
-			P0.LastChild.Next = PLUS2
-			PLUS2.Next = P3.Child
-			P0.LastChild = P3.LastChild
-
-			{
-				newValue := parserInt64Pools[thread].Get()
-				*newValue = *P1.Value.(*int64) + *P3.Value.(*int64)
-				P0.Value = newValue
-			}
-			_ = P1
-			_ = PLUS2
-			_ = P3
-		case 19:
-			ruleType = gopapageno.RuleCombine
-
-			P0 := lhs
-			P1 := rhs[0]
-			PLUS2 := rhs[1]
-			P3 := rhs[2]
-
-			P0.LastChild.Next = PLUS2
-			PLUS2.Next = P3.Child
-			P0.LastChild = P3.LastChild
-
-			{
-				newValue := parserInt64Pools[thread].Get()
-				*newValue = *P1.Value.(*int64) + *P3.Value.(*int64)
-				P0.Value = newValue
-			}
-			_ = P1
-			_ = PLUS2
-			_ = P3
-		case 20:
-			ruleType = gopapageno.RuleCombine
-
-			P0 := lhs
-			P1 := rhs[0]
-			PLUS2 := rhs[1]
-			P3 := rhs[2]
-
-			P0.LastChild.Next = PLUS2
-			PLUS2.Next = P3.Child
-			P0.LastChild = P3.LastChild
-
-			{
-				newValue := parserInt64Pools[thread].Get()
-				*newValue = *P1.Value.(*int64) + *P3.Value.(*int64)
-				P0.Value = newValue
-			}
-			_ = P1
-			_ = PLUS2
-			_ = P3
-		case 21:
-			ruleType = gopapageno.RuleSimple
-
-			S0 := lhs
-			S1 := rhs[0]
-
-			S0.Child = S1
-			S0.LastChild = S1
-
-			{
-				S0.Value = S1.Value
-			}
-			_ = S1
-		case 22:
-			ruleType = gopapageno.RuleSimple
-
+		case 9:
 			D_E_P_T0 := lhs
 			LPAR1 := rhs[0]
 			S2 := rhs[1]
@@ -609,9 +473,7 @@ func NewGrammar() *gopapageno.Grammar {
 			_ = LPAR1
 			_ = S2
 			_ = RPAR3
-		case 23:
-			ruleType = gopapageno.RuleSimple
-
+		case 10:
 			D_E_P_T0 := lhs
 			NUMBER1 := rhs[0]
 
@@ -619,25 +481,29 @@ func NewGrammar() *gopapageno.Grammar {
 			D_E_P_T0.LastChild = NUMBER1
 
 			{
-				D_E_P_T0.Value = NUMBER1.Value
+			    D_E_P_T0.Value = NUMBER1.Value
 			}
 			_ = NUMBER1
 		}
-		_ = ruleType
+		_ = ruleFlags
 	}
 
 	return &gopapageno.Grammar{
-		NumTerminals:              numTerminals,
-		NumNonterminals:           numNonTerminals,
-		MaxRHSLength:              maxRHSLen,
-		Rules:                     rules,
-		CompressedRules:           compressedRules,
-		PrecedenceMatrix:          precMatrix,
+		NumTerminals:  numTerminals,
+		NumNonterminals: numNonTerminals,
+		MaxRHSLength: maxRHSLen,
+		Rules: rules,
+		CompressedRules: compressedRules,
+		PrecedenceMatrix: precMatrix,
 		BitPackedPrecedenceMatrix: bitPackedMatrix,
-		MaxPrefixLength:           maxPrefixLength,
-		Prefixes:                  prefixes,
-		Func:                      fn,
-		ParsingStrategy:           gopapageno.COPP,
-		PreambleFunc:              ParserPreallocMem,
+		TokenNames: tokenNames,
+		SyncTerminals: syncTerminals,
+		MaxPrefixLength: maxPrefixLength,
+		Prefixes: prefixes,
+		CompressedPrefixes: compressedPrefixes,
+		Func: fn,
+		ParsingStrategy: gopapageno.COPP,
+		PreambleFunc: ParserPreallocMem,
 	}
 }
+
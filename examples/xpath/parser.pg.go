@@ -156,6 +156,8 @@ func SprintToken[ValueType any](root *gopapageno.Token) string {
 		if t.Value != nil {
 			if v, ok := any(t.Value).(*ValueType); ok {
 				sb.WriteString(fmt.Sprintf(": %v", *v))
+			} else {
+				sb.WriteString(fmt.Sprintf("%v", v))
 			}
 		}
 		
@@ -201,7 +203,18 @@ func NewGrammar() *gopapageno.Grammar {
 		750230570707284, 
 	}
 
-	fn := func(ruleDescription uint16, ruleFlags gopapageno.RuleFlags, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int){
+	tokenNames := map[gopapageno.TokenType]string{
+		DOCUMENT: "DOCUMENT",
+		ELEM: "ELEM",
+		CLOSETAG: "CLOSETAG",
+		OPENCLOSETAG: "OPENCLOSETAG",
+		OPENTAG: "OPENTAG",
+		TEXT: "TEXT",
+	}
+
+	syncTerminals := []gopapageno.TokenType{}
+
+	fn := func(ruleDescription uint16, ruleFlags gopapageno.RuleFlags, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int, runState any){
 		switch ruleDescription {
 		case 0:
 			DOCUMENT0 := lhs
@@ -438,8 +451,10 @@ func NewGrammar() *gopapageno.Grammar {
 		CompressedRules: compressedRules,
 		PrecedenceMatrix: precMatrix,
 		BitPackedPrecedenceMatrix: bitPackedMatrix,
+		TokenNames: tokenNames,
+		SyncTerminals: syncTerminals,
 		Func: fn,
-		ParsingStrategy: gopapageno.OPP,
+		ParsingStrategy: gopapageno.AOPP,
 		PreambleFunc: ParserPreallocMem,
 	}
 }
@@ -478,7 +478,7 @@ func NewGrammar() *gopapageno.Grammar {
 		12251244168589170004, 629637665892, 372014444257634816, 9406471509205066922, 1538262213470849696, 6165595102986372437, 12189697498042484049, 9405767830901227560, 3063021872248554122, 4, 
 	}
 
-	fn := func(ruleDescription uint16, ruleFlags gopapageno.RuleFlags, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int){
+	fn := func(ruleDescription uint16, ruleFlags gopapageno.RuleFlags, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int, runState any){
 		switch ruleDescription {
 		case 0:
 			AndExpr_OrExpr0 := lhs
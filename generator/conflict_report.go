@@ -0,0 +1,277 @@
+package generator
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/giornetta/gopapageno"
+)
+
+// A conflictReport lists the precedence conflicts of a grammar: the pairs of terminals between which more than one
+// precedence relation holds, along with the rules causing each of them.
+type conflictReport struct {
+	Strategy  string               `json:"strategy"`
+	Conflicts []precedenceConflict `json:"conflicts"`
+}
+
+// A precedenceConflict is found between terminal Left and terminal Right, when Right follows Left.
+type precedenceConflict struct {
+	Left      string             `json:"left"`
+	Right     string             `json:"right"`
+	Relations []conflictRelation `json:"relations"`
+
+	// Resolution is the precedence settled by the declared precedence of both terminals, if any.
+	// Under the AOPP strategy, it is Associative when a rule causing the conflict is associative.
+	Resolution string `json:"resolution,omitempty"`
+}
+
+// A conflictRelation is one of the precedences holding between the terminals of a conflict.
+type conflictRelation struct {
+	Precedence string          `json:"precedence"`
+	Causes     []relationCause `json:"causes"`
+}
+
+// A relationCause is a rule whose right-hand side makes a relation hold.
+type relationCause struct {
+	Rule string `json:"rule"`
+
+	// Symbols holds the positions in the right-hand side of the rule of the symbols causing the relation.
+	Symbols [2]int `json:"symbols"`
+
+	// Nonterminal is the one next to a terminal in the rule, which derives the other terminal at its edge.
+	// Derivation lists the rules deriving it, the first one being a rule of Nonterminal.
+	Nonterminal string   `json:"nonterminal,omitempty"`
+	Derivation  []string `json:"derivation,omitempty"`
+}
+
+// conflictReport finds the precedence conflicts of the grammar, whose tokens must have been inferred.
+// Conflicts are reported whether or not they are settled, along with how they are.
+func (p *grammarDescription) conflictReport(strategy gopapageno.ParsingStrategy) *conflictReport {
+	lts, rts := p.getTerminalSets()
+
+	relations := make(map[[2]string]map[gopapageno.Precedence][]relationCause)
+	associative := make(map[[2]string]bool)
+	add := func(a string, b string, prec gopapageno.Precedence, rule ruleDescription, cause relationCause) {
+		pair := [2]string{a, b}
+		if relations[pair] == nil {
+			relations[pair] = make(map[gopapageno.Precedence][]relationCause)
+		}
+
+		relations[pair][prec] = append(relations[pair][prec], cause)
+
+		if prec != gopapageno.PrecEquals && p.isAssociative(rule) {
+			associative[pair] = true
+		}
+	}
+
+	for _, rule := range p.rules {
+		rhs := rule.RHS
+
+		for i := 0; i < len(rhs)-1; i++ {
+			x, y := rhs[i], rhs[i+1]
+
+			switch {
+			case p.terminals.Contains(x) && p.terminals.Contains(y):
+				add(x, y, gopapageno.PrecEquals, rule, relationCause{Rule: rule.String(), Symbols: [2]int{i, i + 1}})
+			case p.nonterminals.Contains(x) && p.terminals.Contains(y):
+				for _, t := range rts[x].Slice() {
+					add(t, y, gopapageno.PrecTakes, rule, relationCause{
+						Rule:        rule.String(),
+						Symbols:     [2]int{i, i + 1},
+						Nonterminal: x,
+						Derivation:  p.derivation(rts, false, x, t),
+					})
+				}
+			case p.terminals.Contains(x) && p.nonterminals.Contains(y):
+				for _, t := range lts[y].Slice() {
+					add(x, t, gopapageno.PrecYields, rule, relationCause{
+						Rule:        rule.String(),
+						Symbols:     [2]int{i, i + 1},
+						Nonterminal: y,
+						Derivation:  p.derivation(lts, true, y, t),
+					})
+				}
+
+				if i+2 < len(rhs) && p.terminals.Contains(rhs[i+2]) {
+					add(x, rhs[i+2], gopapageno.PrecEquals, rule, relationCause{Rule: rule.String(), Symbols: [2]int{i, i + 2}})
+				}
+			}
+		}
+	}
+
+	report := &conflictReport{
+		Strategy:  strategy.String(),
+		Conflicts: make([]precedenceConflict, 0),
+	}
+
+	pairs := slices.SortedFunc(maps.Keys(relations), func(a, b [2]string) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+	})
+
+	for _, pair := range pairs {
+		if len(relations[pair]) < 2 {
+			continue
+		}
+
+		c := precedenceConflict{
+			Left:  pair[0],
+			Right: pair[1],
+		}
+
+		for _, prec := range sortedKeys(relations[pair]) {
+			c.Relations = append(c.Relations, conflictRelation{
+				Precedence: prec.String(),
+				Causes:     relations[pair][prec],
+			})
+		}
+
+		if _, ok := relations[pair][gopapageno.PrecEquals]; !ok {
+			if prec, ok := p.operators.resolve(pair[0], pair[1]); ok {
+				c.Resolution = prec.String()
+			} else if strategy == gopapageno.AOPP && associative[pair] {
+				c.Resolution = gopapageno.PrecAssociative.String()
+			}
+		}
+
+		report.Conflicts = append(report.Conflicts, c)
+	}
+
+	return report
+}
+
+// derivation returns the rules through which terminal t belongs to the terminal set of nonterminal n, the first one
+// being a rule of n. Sets holds the left terminal sets if left is true, and the right ones otherwise.
+// The shortest derivation is returned, trying rules in the order they are defined.
+func (p *grammarDescription) derivation(sets map[string]*set[string], left bool, n string, t string) []string {
+	type step struct {
+		from string
+		rule int
+	}
+	steps := map[string]step{n: {rule: -1}}
+
+	for queue := []string{n}; len(queue) > 0; queue = queue[1:] {
+		x := queue[0]
+
+		for i, rule := range p.rules {
+			if rule.LHS != x {
+				continue
+			}
+
+			// Right terminal sets are found from the end of the rules.
+			rhs := rule.RHS
+			if !left {
+				rhs = slices.Clone(rhs)
+				slices.Reverse(rhs)
+			}
+
+			if j := slices.IndexFunc(rhs, p.terminals.Contains); j >= 0 && rhs[j] == t {
+				rules := []string{rule.String()}
+				for s := steps[x]; s.rule >= 0; s = steps[s.from] {
+					rules = append(rules, p.rules[s.rule].String())
+				}
+
+				slices.Reverse(rules)
+				return rules
+			}
+
+			if edge := rhs[0]; p.nonterminals.Contains(edge) && sets[edge].Contains(t) {
+				if _, ok := steps[edge]; !ok {
+					steps[edge] = step{from: x, rule: i}
+					queue = append(queue, edge)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// write writes the report to the file called filename, as JSON if its extension is .json, or as text otherwise.
+func (r *conflictReport) write(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("could not create report file %s: %w", filename, err)
+	}
+	defer f.Close()
+
+	if filepath.Ext(filename) == ".json" {
+		err = r.writeJSON(f)
+	} else {
+		err = r.writeText(f)
+	}
+	if err != nil {
+		return fmt.Errorf("could not write report file %s: %w", filename, err)
+	}
+
+	return f.Close()
+}
+
+// writeJSON writes the report to w as JSON.
+func (r *conflictReport) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+
+	return enc.Encode(r)
+}
+
+// writeText writes the report to w in a human-readable form.
+func (r *conflictReport) writeText(w io.Writer) error {
+	var sb strings.Builder
+
+	switch len(r.Conflicts) {
+	case 0:
+		fmt.Fprintf(&sb, "No precedence conflicts found (strategy %s).\n", r.Strategy)
+	case 1:
+		fmt.Fprintf(&sb, "1 precedence conflict found (strategy %s).\n", r.Strategy)
+	default:
+		fmt.Fprintf(&sb, "%d precedence conflicts found (strategy %s).\n", len(r.Conflicts), r.Strategy)
+	}
+
+	for _, c := range r.Conflicts {
+		precs := make([]string, len(c.Relations))
+		for i, rel := range c.Relations {
+			precs[i] = rel.Precedence
+		}
+
+		fmt.Fprintf(&sb, "\n%s followed by %s: %s\n", c.Left, c.Right, strings.Join(precs, " or "))
+
+		for _, rel := range c.Relations {
+			for _, cause := range rel.Causes {
+				fmt.Fprintf(&sb, "  %s in %s", rel.Precedence, cause.Rule)
+
+				switch rel.Precedence {
+				case gopapageno.PrecTakes.String():
+					fmt.Fprintf(&sb, ", since %s ends with %s: %s", cause.Nonterminal, c.Left, strings.Join(cause.Derivation, ", then "))
+				case gopapageno.PrecYields.String():
+					fmt.Fprintf(&sb, ", since %s begins with %s: %s", cause.Nonterminal, c.Right, strings.Join(cause.Derivation, ", then "))
+				}
+
+				sb.WriteString("\n")
+			}
+		}
+
+		switch c.Resolution {
+		case "":
+		case gopapageno.PrecAssociative.String():
+			fmt.Fprintf(&sb, "  Resolved as %s, since one of the rules is associative.\n", c.Resolution)
+		default:
+			fmt.Fprintf(&sb, "  Resolved as %s by the declared precedence of the terminals.\n", c.Resolution)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys[K cmp.Ordered, V any](m map[K]V) []K {
+	return slices.Sorted(maps.Keys(m))
+}
@@ -0,0 +1,201 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/giornetta/gopapageno"
+)
+
+// compileGrammar parses the grammar description src and compiles it with the given strategy,
+// returning the error of compile along with the grammar.
+func compileGrammar(t *testing.T, src string, strategy gopapageno.ParsingStrategy) (*grammarDescription, error) {
+	t.Helper()
+
+	opts := &Options{Strategy: strategy, Logger: log.New(io.Discard, "", 0)}
+
+	p, err := parseGrammarDescription(strings.NewReader(src), opts)
+	if err != nil {
+		t.Fatalf("could not parse grammar description: %v", err)
+	}
+
+	return p, p.compile(opts)
+}
+
+const sumGrammar = `%axiom S
+%s
+
+%%
+
+S : E
+{
+};
+
+E : E PLUS E
+{
+} | NUMBER
+{
+};
+
+%%`
+
+const sumConflicts = `[
+  {
+    "left": "PLUS",
+    "right": "PLUS",
+    "relations": [
+      {
+        "precedence": "Yields",
+        "causes": [
+          {
+            "rule": "E -> E PLUS E",
+            "symbols": [1, 2],
+            "nonterminal": "E",
+            "derivation": ["E -> E PLUS E"]
+          }
+        ]
+      },
+      {
+        "precedence": "Takes",
+        "causes": [
+          {
+            "rule": "E -> E PLUS E",
+            "symbols": [0, 1],
+            "nonterminal": "E",
+            "derivation": ["E -> E PLUS E"]
+          }
+        ]
+      }
+    ]%s
+  }
+]`
+
+func TestConflictReportJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		strategy  gopapageno.ParsingStrategy
+		conflicts string
+		fails     bool
+	}{
+		{
+			name:      "none",
+			src:       strings.NewReplacer("%s", "", "E : E PLUS E", "E : E PLUS NUMBER").Replace(sumGrammar),
+			strategy:  gopapageno.AOPP,
+			conflicts: `[]`,
+		},
+		{
+			name:      "unresolved",
+			src:       strings.Replace(sumGrammar, "%s", "", 1),
+			strategy:  gopapageno.OPP,
+			conflicts: strings.Replace(sumConflicts, "%s", "", 1),
+			fails:     true,
+		},
+		{
+			name:      "resolved",
+			src:       strings.Replace(sumGrammar, "%s", "%left PLUS", 1),
+			strategy:  gopapageno.OPP,
+			conflicts: strings.Replace(sumConflicts, "%s", `, "resolution": "Takes"`, 1),
+		},
+		{
+			name:      "associative",
+			src:       strings.Replace(sumGrammar, "%s", "", 1),
+			strategy:  gopapageno.AOPP,
+			conflicts: strings.Replace(sumConflicts, "%s", `, "resolution": "Associative"`, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		p, err := compileGrammar(t, tt.src, tt.strategy)
+		if (err != nil) != tt.fails {
+			t.Errorf("%s: unexpected compile error: %v", tt.name, err)
+		}
+
+		var buf bytes.Buffer
+		if err := p.report.writeJSON(&buf); err != nil {
+			t.Fatalf("%s: could not write report: %v", tt.name, err)
+		}
+
+		var got, expected any
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("%s: report isn't valid JSON: %v", tt.name, err)
+		}
+
+		if err := json.Unmarshal([]byte(`{"strategy": "`+tt.strategy.String()+`", "conflicts": `+tt.conflicts+`}`), &expected); err != nil {
+			t.Fatalf("%s: invalid expected report: %v", tt.name, err)
+		}
+
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected report %v, got:\n%s", tt.name, expected, buf.String())
+		}
+	}
+}
+
+func TestConflictReportText(t *testing.T) {
+	p, _ := compileGrammar(t, strings.Replace(sumGrammar, "%s", "%left PLUS", 1), gopapageno.OPP)
+
+	var buf bytes.Buffer
+	if err := p.report.writeText(&buf); err != nil {
+		t.Fatalf("could not write report: %v", err)
+	}
+
+	expected := `1 precedence conflict found (strategy OPP).
+
+PLUS followed by PLUS: Yields or Takes
+  Yields in E -> E PLUS E, since E begins with PLUS: E -> E PLUS E
+  Takes in E -> E PLUS E, since E ends with PLUS: E -> E PLUS E
+  Resolved as Takes by the declared precedence of the terminals.
+`
+	if buf.String() != expected {
+		t.Errorf("expected report:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestConflictReportAOPP(t *testing.T) {
+	src := `%axiom S
+
+%%
+
+S : E
+{
+};
+
+E : E PLUS F
+{
+} | F
+{
+};
+
+F : PLUS F
+{
+} | NUMBER
+{
+};
+
+%%`
+
+	var logs bytes.Buffer
+	opts := &Options{Strategy: gopapageno.AOPP, Logger: log.New(&logs, "", 0)}
+
+	p, err := parseGrammarDescription(strings.NewReader(src), opts)
+	if err != nil {
+		t.Fatalf("could not parse grammar description: %v", err)
+	}
+
+	if err := p.compile(opts); err == nil {
+		t.Errorf("expected the conflict to be left unresolved")
+	}
+
+	if len(p.report.Conflicts) != 1 || p.report.Conflicts[0].Resolution != "" {
+		t.Errorf("expected an unresolved conflict to be reported, got %+v", p.report.Conflicts)
+	}
+
+	if !strings.Contains(logs.String(), "E : [E PLUS E_F]\n") || !strings.Contains(logs.String(), "Takes precedence conflict between terminals") {
+		t.Errorf("expected the conflict to be logged, got:\n%s", logs.String())
+	}
+}
@@ -2,11 +2,14 @@ package generator
 
 import (
 	"fmt"
+	"log"
 	"strings"
+
 	"github.com/giornetta/gopapageno"
 )
 
-func LogPrecedenceConflict(p gopapageno.Precedence, c conflict) {
+// logPrecedenceConflict reports the conflict c found on precedence p, pointing at the terminals causing it.
+func logPrecedenceConflict(logger *log.Logger, p gopapageno.Precedence, c conflict) {
 	sb := strings.Builder{}
 	for _ = range len(c.rule.LHS) + 4 {
 		sb.WriteString(" ")
@@ -26,6 +29,6 @@ func LogPrecedenceConflict(p gopapageno.Precedence, c conflict) {
 	}
 	sb.WriteString(fmt.Sprintf("%v precedence conflict between terminals", p))
 
-	fmt.Printf("%v : %v\n", c.rule.LHS, c.rule.RHS)
-	fmt.Printf("%v\n", sb.String())
+	logger.Printf("%v : %v\n", c.rule.LHS, c.rule.RHS)
+	logger.Printf("%v\n", sb.String())
 }
@@ -22,6 +22,16 @@ type Options struct {
 	TypesOnly                 bool
 	GenerateBenchmarks        bool
 
+	// ExplainCuts enables describing the cut points of the lexer, and why sources can be cut there safely.
+	ExplainCuts bool
+
+	// ReportFilename, if set, is the file the precedence conflicts of the grammar are reported to,
+	// as JSON if its extension is .json, or as text otherwise. It is written even if some conflicts are unresolved.
+	ReportFilename string
+
+	// Normalize enables rewriting the grammar into operator form, deleting empty rules and adjacent nonterminals.
+	Normalize bool
+
 	Strategy gopapageno.ParsingStrategy
 
 	Logger *log.Logger
@@ -42,6 +52,14 @@ func Generate(opts *Options) error {
 		return fmt.Errorf("could not compile lexer: %w", err)
 	}
 
+	if err := lexerDesc.checkConflicts(opts.Logger); err != nil {
+		return fmt.Errorf("invalid lexer rules: %w", err)
+	}
+
+	if opts.ExplainCuts {
+		lexerDesc.explainCuts(os.Stdout)
+	}
+
 	if err := lexerFile.Close(); err != nil {
 		opts.Logger.Printf("could not close lexer description file: %v\n", err)
 	}
@@ -56,8 +74,16 @@ func Generate(opts *Options) error {
 		return fmt.Errorf("could not parse parser description: %w", err)
 	}
 
-	if err := parserDesc.compile(opts); err != nil {
-		return fmt.Errorf("could not compile parser: %w", err)
+	compileErr := parserDesc.compile(opts)
+
+	if opts.ReportFilename != "" && parserDesc.report != nil {
+		if err := parserDesc.report.write(opts.ReportFilename); err != nil {
+			return fmt.Errorf("could not report conflicts: %w", err)
+		}
+	}
+
+	if compileErr != nil {
+		return fmt.Errorf("could not compile parser: %w", compileErr)
 	}
 
 	if err := parserFile.Close(); err != nil {
@@ -160,6 +186,7 @@ func run() error {
 	cpuProfileFlag := flag.String("cpuprof", "", "output file for CPU profiling")
 	memProfileFlag := flag.String("memprof", "", "output file for Memory profiling")
 	dumpGraphFlag := flag.String("graph", "", "output graphviz dot file of the AST")
+	maxErrorsFlag := flag.Int("errors", 0, "recover from syntax errors, reporting at most this many of them (0 disables recovery)")
 
 	flag.Parse()
 
@@ -196,9 +223,7 @@ func run() error {
 		strat = gopapageno.ReductionMixed	
 	}
 
-	r := gopapageno.NewRunner(
-		NewLexer(),
-		NewGrammar(),
+	runnerOpts := []gopapageno.RunnerOpt{
 		gopapageno.WithConcurrency(*concurrencyFlag),
 		gopapageno.WithLogging(log.New(logOut, "", 0)),
 		gopapageno.WithCPUProfiling(cpuProfileWriter),
@@ -207,12 +232,24 @@ func run() error {
 		gopapageno.WithAverageTokenLength(*avgTokensFlag),
 		gopapageno.WithParallelFactor(*parallelFactorFlag),
 		gopapageno.WithGarbageCollection(false),
-	)
+	}
+
+	if *maxErrorsFlag > 0 {
+		runnerOpts = append(runnerOpts,
+			gopapageno.WithErrorRecovery(*maxErrorsFlag),
+			gopapageno.WithDiagnostics(func(err error) {
+				fmt.Fprintln(os.Stderr, err)
+			}),
+		)
+	}
+
+	r := gopapageno.NewRunner(NewLexer(), NewGrammar(), runnerOpts...)
 
 	ctx := context.Background()
 
+	// When recovering from errors, a partial parse tree may be returned alongside them.
 	root, err := r.Run(ctx, bytes)
-	if err != nil {
+	if err != nil && root == nil {
 		return fmt.Errorf("could not parse source: %%w", err)
 	}
 
@@ -13,7 +13,10 @@ import (
 )
 
 var (
-	axiomRegexp = regexp.MustCompile("^%axiom\\s*([a-zA-Z][a-zA-Z0-9]*)\\s*$")
+	axiomRegexp = regexp.MustCompile("^%axiom\\s*([a-zA-Z][a-zA-Z0-9_]*)\\s*$")
+	syncRegexp  = regexp.MustCompile("^%sync((?:\\s+[a-zA-Z][a-zA-Z0-9_]*)+)\\s*$")
+	typeRegexp  = regexp.MustCompile("^%type\\s+([a-zA-Z][a-zA-Z0-9_]*)\\s+(\\S.*?)\\s*$")
+	assocRegexp = regexp.MustCompile("^%(left|right|nonassoc)((?:\\s+[a-zA-Z][a-zA-Z0-9_]*)+)\\s*$")
 )
 
 const (
@@ -25,6 +28,23 @@ type grammarDescription struct {
 	axiom        string
 	preambleFunc string
 
+	// syncTokens are the terminals on which generated parsers resynchronize after a syntax error.
+	syncTokens []string
+
+	// types maps tokens to the Go type of their semantic value, as declared with %type.
+	types map[string]string
+
+	// operators holds the precedence of the terminals declared with %left, %right or %nonassoc,
+	// which settles the precedence conflicts among them.
+	operators operatorPrecedences
+
+	// valueTypes maps the tokens of the transformed grammar to the Go type of their semantic value.
+	// It is nil until resolveTypes() is executed successfully.
+	valueTypes map[string]string
+
+	// merged maps the nonterminals created by deleteRepeatedRHS() to the ones they were merged from.
+	merged map[string][]string
+
 	rules []ruleDescription
 
 	code string
@@ -36,6 +56,14 @@ type grammarDescription struct {
 	terminals *set[string]
 
 	precMatrix precedenceMatrix
+
+	// leftTerminals and rightTerminals hold the terminal sets of the nonterminals the matrix is built from,
+	// since the rules are transformed further once it is.
+	leftTerminals  map[string]*set[string]
+	rightTerminals map[string]*set[string]
+
+	// report holds the precedence conflicts of the grammar, once compile() has computed its precedences.
+	report *conflictReport
 }
 
 type ruleDescription struct {
@@ -60,6 +88,9 @@ func parseGrammarDescription(r io.Reader, opts *Options) (*grammarDescription, e
 	moreThanOneAxiomWarning := false
 
 	var preambleFunc string
+	var syncTokens []string
+	types := make(map[string]string)
+	operators := make(operatorPrecedences)
 
 	for scanner.Scan() {
 		l := scanner.Text()
@@ -75,6 +106,18 @@ func parseGrammarDescription(r io.Reader, opts *Options) (*grammarDescription, e
 			axiom = match[1]
 		} else if match := preambleRegex.FindStringSubmatch(l); match != nil {
 			preambleFunc = match[1]
+		} else if match := syncRegexp.FindStringSubmatch(l); match != nil {
+			syncTokens = append(syncTokens, strings.Fields(match[1])...)
+		} else if match := typeRegexp.FindStringSubmatch(l); match != nil {
+			if _, ok := types[match[1]]; ok {
+				return nil, fmt.Errorf("type of token %s is declared more than once", match[1])
+			}
+			types[match[1]] = match[2]
+		} else if match := assocRegexp.FindStringSubmatch(l); match != nil {
+			assoc := map[string]associativity{"left": assocLeft, "right": assocRight, "nonassoc": assocNone}[match[1]]
+			if err := operators.declare(assoc, strings.Fields(match[2])); err != nil {
+				return nil, err
+			}
 		} else if l != "" {
 			return nil, fmt.Errorf("unrecognized parser option: %s", l)
 		}
@@ -123,6 +166,9 @@ func parseGrammarDescription(r io.Reader, opts *Options) (*grammarDescription, e
 	return &grammarDescription{
 		axiom:        axiom,
 		preambleFunc: preambleFunc,
+		syncTokens:   syncTokens,
+		types:        types,
+		operators:    operators,
 		code:         preambleBuilder.String(),
 		rules:        rules,
 	}, nil
@@ -276,7 +322,48 @@ func (p *grammarDescription) compile(opts *Options) error {
 		return fmt.Errorf("axiom isn't used in any ruleDescription")
 	}
 
-	p.deleteRepeatedRHS()
+	for _, token := range p.syncTokens {
+		if !p.terminals.Contains(token) {
+			return fmt.Errorf("synchronizing token %s is not a terminal", token)
+		}
+	}
+
+	for token := range p.types {
+		if !p.terminals.Contains(token) && !p.nonterminals.Contains(token) {
+			return fmt.Errorf("typed token %s isn't used in any rule", token)
+		}
+	}
+
+	if err := p.checkOperators(); err != nil {
+		return err
+	}
+
+	if opts.Normalize {
+		if opts.Strategy == gopapageno.COPP {
+			return fmt.Errorf("grammars can't be normalized with the %s strategy", opts.Strategy)
+		}
+
+		if err := p.normalize(opts.Logger); err != nil {
+			return fmt.Errorf("could not normalize grammar: %w", err)
+		}
+	} else {
+		for _, rule := range p.rules {
+			if len(rule.RHS) == 0 {
+				return fmt.Errorf("rule %s has an empty right-hand side, which operator precedence grammars can't have: normalize the grammar to delete it", rule)
+			}
+		}
+	}
+
+	p.deleteRepeatedRHS(opts.Logger)
+
+	if err := p.resolveTypes(); err != nil {
+		return fmt.Errorf("could not resolve semantic value types: %w", err)
+	}
+
+	p.leftTerminals, p.rightTerminals = p.getTerminalSets()
+
+	// The report is built before the matrix, which can't be built if some conflicts are left unresolved.
+	p.report = p.conflictReport(opts.Strategy)
 
 	var precMatrix precedenceMatrix
 	var err error
@@ -386,6 +473,19 @@ func (p *grammarDescription) emit(opts *Options, packageName string) error {
 	 **********/
 	p.emitTokens(f)
 
+	/*************
+	 * Run State *
+	 *************/
+	stateType, err := preambleStateType(p.code, p.preambleFunc)
+	if err != nil {
+		return fmt.Errorf("could not inspect parser preamble: %w", err)
+	}
+
+	pooled := p.pooledTypes()
+	if len(pooled) > 0 {
+		p.emitRunState(f, stateType, pooled)
+	}
+
 	// NewParser func starts here.
 	fmt.Fprintf(f, "\nfunc NewGrammar() *gopapageno.Grammar {\n")
 
@@ -494,10 +594,28 @@ func (p *grammarDescription) emit(opts *Options, packageName string) error {
 	}
 	fmt.Fprintf(f, "\n\t}\n\n")
 
+	/***************
+	 * Token Names *
+	 ***************/
+	fmt.Fprintf(f, "\ttokenNames := map[gopapageno.TokenType]string{\n")
+	for _, token := range p.nonterminals.Slice() {
+		if token != emptyToken {
+			fmt.Fprintf(f, "\t\t%s: %q,\n", token, token)
+		}
+	}
+	for _, token := range p.terminals.Slice() {
+		if token != termToken {
+			fmt.Fprintf(f, "\t\t%s: %q,\n", token, token)
+		}
+	}
+	fmt.Fprintf(f, "\t}\n\n")
+
+	fmt.Fprintf(f, "\tsyncTerminals := []gopapageno.TokenType{%s}\n\n", strings.Join(p.syncTokens, ", "))
+
 	/*******************
 	 * Grammar Function *
 	 *******************/
-	p.emitParserFunctions(f)
+	p.emitParserFunctions(f, stateType, pooled)
 
 	/********************
 	 * Construct Grammar *
@@ -510,6 +628,8 @@ func (p *grammarDescription) emit(opts *Options, packageName string) error {
 	fmt.Fprintf(f, "\t\tCompressedRules: compressedRules,\n")
 	fmt.Fprintf(f, "\t\tPrecedenceMatrix: precMatrix,\n")
 	fmt.Fprintf(f, "\t\tBitPackedPrecedenceMatrix: bitPackedMatrix,\n")
+	fmt.Fprintf(f, "\t\tTokenNames: tokenNames,\n")
+	fmt.Fprintf(f, "\t\tSyncTerminals: syncTerminals,\n")
 	if opts.Strategy == gopapageno.COPP {
 		fmt.Fprintf(f, "\t\tMaxPrefixLength: maxPrefixLength,\n")
 		fmt.Fprintf(f, "\t\tPrefixes: prefixes,\n")
@@ -518,8 +638,17 @@ func (p *grammarDescription) emit(opts *Options, packageName string) error {
 	fmt.Fprintf(f, "\t\tFunc: fn,\n")
 	fmt.Fprintf(f, "\t\tParsingStrategy: gopapageno.%s,\n", opts.Strategy)
 
-	if p.preambleFunc != "" {
-		fmt.Fprintf(f, "\t\tPreambleFunc: %s,\n", p.preambleFunc)
+	if len(pooled) > 0 {
+		// The state returned by the preamble function is built by newParserRunState.
+		if p.preambleFunc != "" && stateType == "" {
+			emitPreamble(f, p.preambleFunc, stateType)
+		}
+
+		fmt.Fprintf(f, "\t\tStateFunc: func(sourceLen, concurrency, avgTokenLength int) any {\n")
+		fmt.Fprintf(f, "\t\t\treturn newParserRunState(sourceLen, concurrency, avgTokenLength)\n")
+		fmt.Fprintf(f, "\t\t},\n")
+	} else if p.preambleFunc != "" {
+		emitPreamble(f, p.preambleFunc, stateType)
 	}
 
 	fmt.Fprintf(f, "\t}\n}\n\n")
@@ -527,8 +656,19 @@ func (p *grammarDescription) emit(opts *Options, packageName string) error {
 	return nil
 }
 
-func (p *grammarDescription) emitParserFunctions(f io.Writer) {
-	fmt.Fprintf(f, "\tfn := func(ruleDescription uint16, ruleFlags gopapageno.RuleFlags, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int){\n")
+func (p *grammarDescription) emitParserFunctions(f io.Writer, stateType string, pooled []string) {
+	fmt.Fprintf(f, "\tfn := func(ruleDescription uint16, ruleFlags gopapageno.RuleFlags, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int, runState any){\n")
+	if len(pooled) > 0 {
+		fmt.Fprintf(f, "\t\trs := runState.(*parserRunState)\n")
+		fmt.Fprintf(f, "\t\t_ = rs\n\n")
+
+		if stateType != "" {
+			fmt.Fprintf(f, "\t\tstate := rs.state\n")
+			fmt.Fprintf(f, "\t\t_ = state\n\n")
+		}
+	} else {
+		emitStateAssertion(f, stateType)
+	}
 	fmt.Fprintf(f, "\t\tswitch ruleDescription {\n")
 	for i, rule := range p.rules {
 		if len(rule.RHS) == 0 || rule.Flags.Has(gopapageno.RulePrefix) {
@@ -577,7 +717,12 @@ func (p *grammarDescription) emitParserFunctions(f io.Writer) {
 		}
 		fmt.Fprintf(f, "\n")
 
+		// The rules deriving the axiom just copy the value, whatever its type.
 		action := rule.Action
+		if action != axiomSemAction {
+			action = p.emitTypedValues(f, rule, action)
+		}
+
 		action = strings.Replace(action, "$$", rule.LHS+"0", -1)
 		for j, _ := range rule.RHS {
 			action = strings.Replace(action, fmt.Sprintf("$%d", j+1), fmt.Sprintf("%s%d", rule.RHS[j], j+1), -1)
@@ -589,6 +734,10 @@ func (p *grammarDescription) emitParserFunctions(f io.Writer) {
 			fmt.Fprintf(f, "\n")
 		}
 
+		if rule.Action != axiomSemAction {
+			p.emitTypedStore(f, rule, pooled)
+		}
+
 		for j, _ := range rule.RHS {
 			fmt.Fprintf(f, "\t\t\t_ = %s%d\n", rule.RHS[j], j+1)
 		}
@@ -601,13 +750,16 @@ func (p *grammarDescription) emitParserFunctions(f io.Writer) {
 func (p *grammarDescription) emitTokens(f io.Writer) {
 	fmt.Fprintf(f, "// Non-terminals\n")
 	fmt.Fprintf(f, "const (\n")
-	for i, token := range p.nonterminals.Slice() {
+	first := true
+	for _, token := range p.nonterminals.Slice() {
 		if token == emptyToken {
 			continue
 		}
 
-		if i == 0 {
+		// emptyToken can sort anywhere, so the first constant emitted is the one to initialize.
+		if first {
 			fmt.Fprintf(f, "\t%s = gopapageno.TokenEmpty + 1 + iota\n", token)
+			first = false
 		} else {
 			fmt.Fprintf(f, "\t%s\n", token)
 		}
@@ -616,13 +768,15 @@ func (p *grammarDescription) emitTokens(f io.Writer) {
 
 	fmt.Fprintf(f, "// Terminals\n")
 	fmt.Fprintf(f, "const (\n")
-	for i, token := range p.terminals.Slice() {
+	first = true
+	for _, token := range p.terminals.Slice() {
 		if token == termToken {
 			continue
 		}
 
-		if i == 0 {
+		if first {
 			fmt.Fprintf(f, "\t%s = gopapageno.TokenTerm + 1 + iota\n", token)
+			first = false
 		} else {
 			fmt.Fprintf(f, "\t%s\n", token)
 		}
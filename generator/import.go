@@ -0,0 +1,426 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/giornetta/gopapageno"
+)
+
+// ImportOptions configures the translation of Yacc/Bison and Flex files into gopapageno descriptions.
+type ImportOptions struct {
+	// YaccFilename is the Yacc/Bison grammar to translate into a parser description.
+	YaccFilename string
+
+	// FlexFilename, if set, is the Flex scanner to translate into a lexer description.
+	FlexFilename string
+
+	// OutputDirectory is where the descriptions are written, named after the translated files.
+	OutputDirectory string
+
+	// Strategy and Normalize are used to check the translated grammar as the generator would.
+	Strategy  gopapageno.ParsingStrategy
+	Normalize bool
+
+	Logger *log.Logger
+}
+
+// An ImportIssue is a construct of a translated file that is unsupported or couldn't be translated faithfully,
+// or a problem found in the resulting descriptions, such as a precedence conflict.
+type ImportIssue struct {
+	Filename string
+
+	// Line is the line of the construct in Filename, or 0 if the issue concerns the whole file.
+	Line int
+
+	Message string
+}
+
+func (i ImportIssue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", i.Filename, i.Line, i.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", i.Filename, i.Message)
+}
+
+// Import translates a Yacc/Bison grammar, and optionally a Flex scanner, into gopapageno descriptions.
+// The translated grammar is then checked, reporting its precedence conflicts.
+// It returns the issues found along the way, and an error only if the descriptions couldn't be written.
+func Import(opts *ImportOptions) ([]ImportIssue, error) {
+	grammarFilename := importedFilename(opts.OutputDirectory, opts.YaccFilename, ".g")
+	lexerFilename := importedFilename(opts.OutputDirectory, opts.FlexFilename, ".l")
+
+	// Flex scanners usually share the extension of lexer descriptions.
+	for _, input := range []string{opts.YaccFilename, opts.FlexFilename} {
+		if input != "" && (isSameFilename(input, grammarFilename) || isSameFilename(input, lexerFilename)) {
+			return nil, fmt.Errorf("the descriptions would overwrite %s: choose another output directory", input)
+		}
+	}
+
+	names := newImportNames()
+
+	yaccSource, err := os.ReadFile(opts.YaccFilename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read grammar file: %w", err)
+	}
+
+	grammar := newYaccImporter(opts.YaccFilename, string(yaccSource), names, opts.Normalize)
+	grammarDescription := grammar.translate()
+	issues := grammar.issues
+
+	if err := os.WriteFile(grammarFilename, []byte(grammarDescription), 0644); err != nil {
+		return issues, fmt.Errorf("could not write parser description: %w", err)
+	}
+	opts.Logger.Printf("Written parser description %s.\n", grammarFilename)
+
+	if opts.FlexFilename != "" {
+		flexSource, err := os.ReadFile(opts.FlexFilename)
+		if err != nil {
+			return issues, fmt.Errorf("could not read scanner file: %w", err)
+		}
+
+		scanner := newFlexImporter(opts.FlexFilename, string(flexSource), names)
+		lexerDescription := scanner.translate()
+		issues = append(issues, scanner.issues...)
+
+		for _, token := range scanner.returned {
+			if !grammar.uses(token) {
+				issues = append(issues, ImportIssue{
+					Filename: opts.FlexFilename,
+					Message:  fmt.Sprintf("token %s is returned by the scanner but isn't used by the grammar, so the lexer description won't compile", token),
+				})
+			}
+		}
+
+		if err := os.WriteFile(lexerFilename, []byte(lexerDescription), 0644); err != nil {
+			return issues, fmt.Errorf("could not write lexer description: %w", err)
+		}
+		opts.Logger.Printf("Written lexer description %s.\n", lexerFilename)
+
+		issues = append(issues, checkImportedLexer(lexerFilename, lexerDescription, opts.Logger)...)
+	}
+
+	for _, rename := range names.renamed {
+		issues = append(issues, ImportIssue{
+			Filename: opts.YaccFilename,
+			Message:  rename,
+		})
+	}
+
+	issues = append(issues, checkImportedGrammar(grammarFilename, grammarDescription, opts)...)
+
+	return issues, nil
+}
+
+// importedFilename returns the name of the description translated from filename, in directory dir.
+func importedFilename(dir string, filename string, ext string) string {
+	base := filepath.Base(filename)
+	return filepath.Join(dir, strings.TrimSuffix(base, filepath.Ext(base))+ext)
+}
+
+// isSameFilename reports whether filenames a and b name the same file.
+func isSameFilename(a string, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+
+	return errA == nil && errB == nil && absA == absB
+}
+
+// checkImportedLexer compiles a translated lexer description, reporting why it can't be used.
+func checkImportedLexer(filename string, description string, logger *log.Logger) []ImportIssue {
+	issue := func(err error) []ImportIssue {
+		return []ImportIssue{{Filename: filename, Message: err.Error()}}
+	}
+
+	l, err := parseLexerDescription(strings.NewReader(description), logger)
+	if err != nil {
+		return issue(err)
+	}
+
+	if err := l.compile(); err != nil {
+		return issue(err)
+	}
+
+	if err := l.checkConflicts(logger); err != nil {
+		return issue(err)
+	}
+
+	return nil
+}
+
+// checkImportedGrammar compiles a translated parser description as the generator would,
+// reporting its precedence conflicts and why it can't be used.
+func checkImportedGrammar(filename string, description string, opts *ImportOptions) []ImportIssue {
+	genOpts := &Options{
+		Strategy:  opts.Strategy,
+		Normalize: opts.Normalize,
+		Logger:    opts.Logger,
+	}
+
+	p, err := parseGrammarDescription(strings.NewReader(description), genOpts)
+	if err != nil {
+		return []ImportIssue{{Filename: filename, Message: err.Error()}}
+	}
+
+	compileErr := p.compile(genOpts)
+
+	issues := make([]ImportIssue, 0)
+	if p.report != nil {
+		for _, c := range p.report.Conflicts {
+			// Conflicts settled by %left, %right and %nonassoc are resolved as Yacc/Bison would.
+			if c.Resolution != "" {
+				continue
+			}
+
+			precs := make([]string, len(c.Relations))
+			for i, rel := range c.Relations {
+				precs[i] = rel.Precedence
+			}
+
+			message := fmt.Sprintf("precedence conflict: %s followed by %s is %s", c.Left, c.Right, strings.Join(precs, " or "))
+			issues = append(issues, ImportIssue{Filename: filename, Message: message})
+		}
+	}
+
+	if compileErr != nil {
+		issues = append(issues, ImportIssue{Filename: filename, Message: compileErr.Error()})
+	}
+
+	return issues
+}
+
+// importNames translates the symbols of imported files into identifiers valid in descriptions and in generated code,
+// consistently across the grammar and the scanner.
+type importNames struct {
+	// names maps the symbols of the imported files to their identifiers.
+	names map[string]string
+
+	// used holds the identifiers given so far.
+	used map[string]bool
+
+	// renamed describes the symbols that couldn't keep their name.
+	renamed []string
+}
+
+func newImportNames() *importNames {
+	return &importNames{
+		names: make(map[string]string),
+		used:  make(map[string]bool),
+	}
+}
+
+// literalNames are the identifiers given to the terminals written as character literals.
+var literalNames = map[byte]string{
+	'+': "PLUS", '-': "MINUS", '*': "TIMES", '/': "DIVIDE", '%': "PERCENT", '^': "CARET",
+	'&': "AMPERSAND", '|': "PIPE", '~': "TILDE", '!': "BANG", '=': "EQUAL", '<': "LESS", '>': "GREATER",
+	'(': "LPAR", ')': "RPAR", '[': "LBRACKET", ']': "RBRACKET", '{': "LBRACE", '}': "RBRACE",
+	',': "COMMA", ';': "SEMICOLON", ':': "COLON", '.': "DOT", '?': "QUESTION", '@': "AT", '#': "HASH",
+	'$': "DOLLAR", '\\': "BACKSLASH", '\'': "QUOTE", '"': "DQUOTE", '`': "BACKQUOTE",
+	'\n': "NEWLINE", '\t': "TAB", ' ': "SPACE",
+}
+
+// digitNames spell out the digits of symbols, since identifiers in descriptions can't contain them.
+var digitNames = []string{"Zero", "One", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine"}
+
+// reservedNames can't name tokens, since generated code would refer to something else through them.
+var reservedNames = map[string]bool{
+	// Go keywords.
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true, "defer": true,
+	"else": true, "fallthrough": true, "for": true, "func": true, "go": true, "goto": true, "if": true,
+	"import": true, "interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+	// Predeclared identifiers used by generated code.
+	"any": true, "bool": true, "byte": true, "error": true, "false": true, "int": true, "int64": true,
+	"len": true, "make": true, "nil": true, "rune": true, "string": true, "true": true, "uint16": true, "uint64": true,
+	// Packages and variables of generated code.
+	"fmt": true, "os": true, "strings": true, "strconv": true, "gopapageno": true, "fn": true, "rules": true,
+	"lhs": true, "rhs": true, "thread": true, "token": true, "text": true, "state": true, "mode": true,
+}
+
+// symbol returns the identifier of a symbol of the imported files.
+func (n *importNames) symbol(name string) string {
+	if id, ok := n.names[name]; ok {
+		return id
+	}
+
+	var sb strings.Builder
+	for _, c := range name {
+		switch {
+		case c >= '0' && c <= '9':
+			sb.WriteString(digitNames[c-'0'])
+		case c == '_' || c < unicode.MaxASCII && unicode.IsLetter(c):
+			sb.WriteRune(c)
+		default:
+			sb.WriteByte('_')
+		}
+	}
+
+	id := sb.String()
+	if id == "" || id[0] == '_' {
+		id = "T" + id
+	}
+
+	id = n.unique(id)
+
+	if id != name {
+		n.renamed = append(n.renamed, fmt.Sprintf("symbol %s is renamed %s, since descriptions can't name it as it is", name, id))
+	}
+
+	n.names[name] = id
+	return id
+}
+
+// literal returns the identifier of a terminal written as the character literal c.
+func (n *importNames) literal(c byte) string {
+	key := fmt.Sprintf("'%c'", c)
+	if id, ok := n.names[key]; ok {
+		return id
+	}
+
+	id, ok := literalNames[c]
+	if !ok {
+		id = fmt.Sprintf("CHAR%s", spellDigits(int(c)))
+	}
+
+	id = n.unique(id)
+
+	n.names[key] = id
+	return id
+}
+
+// literals returns the character literals named so far, in ascending order.
+func (n *importNames) literals() []byte {
+	var literals []byte
+	for key := range n.names {
+		if len(key) == 3 && key[0] == '\'' {
+			literals = append(literals, key[1])
+		}
+	}
+
+	slices.Sort(literals)
+	return literals
+}
+
+// unique returns id, modified if needed so that it's neither reserved nor given to another symbol, and marks it as used.
+func (n *importNames) unique(id string) string {
+	for reservedNames[id] || n.used[id] {
+		id += "_"
+	}
+
+	n.used[id] = true
+	return id
+}
+
+// spellDigits returns the decimal representation of v, with its digits spelled out.
+func spellDigits(v int) string {
+	var sb strings.Builder
+	for _, c := range fmt.Sprint(v) {
+		sb.WriteString(digitNames[c-'0'])
+	}
+
+	return sb.String()
+}
+
+// unquoteChar returns the character denoted by the body of a C character literal, without its quotes.
+// It reports false if it isn't a single byte.
+func unquoteChar(body string) (byte, bool) {
+	if len(body) == 1 {
+		return body[0], true
+	}
+
+	if len(body) < 2 || body[0] != '\\' {
+		return 0, false
+	}
+
+	switch body[1] {
+	case 'n':
+		return '\n', len(body) == 2
+	case 't':
+		return '\t', len(body) == 2
+	case 'r':
+		return '\r', len(body) == 2
+	case 'f':
+		return '\f', len(body) == 2
+	case 'v':
+		return '\v', len(body) == 2
+	case 'a':
+		return '\a', len(body) == 2
+	case 'b':
+		return '\b', len(body) == 2
+	case 'x':
+		var c byte
+		if _, err := fmt.Sscanf(body[2:], "%x", &c); err != nil {
+			return 0, false
+		}
+		return c, true
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		var c byte
+		if _, err := fmt.Sscanf(body[1:], "%o", &c); err != nil {
+			return 0, false
+		}
+		return c, true
+	}
+
+	return body[1], len(body) == 2
+}
+
+// skipCBlock returns the position following the braced block of C code beginning at position pos of src,
+// skipping the braces in strings, character literals and comments. It returns -1 if the block isn't terminated.
+func skipCBlock(src string, pos int) int {
+	depth := 0
+
+	for pos < len(src) {
+		switch c := src[pos]; {
+		case c == '"' || c == '\'':
+			pos = skipCLiteral(src, pos)
+			continue
+		case strings.HasPrefix(src[pos:], "/*"):
+			end := strings.Index(src[pos+2:], "*/")
+			if end < 0 {
+				return -1
+			}
+			pos += end + 4
+			continue
+		case strings.HasPrefix(src[pos:], "//"):
+			for pos < len(src) && src[pos] != '\n' {
+				pos++
+			}
+			continue
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return pos + 1
+			}
+		}
+
+		pos++
+	}
+
+	return -1
+}
+
+// skipCLiteral returns the position following the C string or character literal beginning at position pos of src.
+func skipCLiteral(src string, pos int) int {
+	quote := src[pos]
+
+	for pos++; pos < len(src) && src[pos] != quote && src[pos] != '\n'; pos++ {
+		if src[pos] == '\\' {
+			pos++
+		}
+	}
+
+	return min(pos+1, len(src))
+}
+
+// lineAt returns the line of position pos in src, counting from 1.
+func lineAt(src string, pos int) int {
+	return strings.Count(src[:min(pos, len(src))], "\n") + 1
+}
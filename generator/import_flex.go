@@ -0,0 +1,683 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	flexReturnRegexp = regexp.MustCompile(`\breturn\s*\(?\s*('(?:\\.|[^'\\])+'|[A-Za-z_][A-Za-z0-9_]*(?:\s*\[\s*0\s*\])?)\s*\)?\s*;`)
+	flexBeginRegexp  = regexp.MustCompile(`\bBEGIN\s*\(?\s*([A-Za-z_][A-Za-z0-9_]*|0)\s*\)?\s*;?`)
+
+	// flexUnsupportedRegexp matches the Flex actions and functions that can't be translated.
+	flexUnsupportedRegexp = regexp.MustCompile(`\b(ECHO|REJECT|yymore|yyless|unput|input|yyterminate|yy_push_state|yy_pop_state)\b`)
+)
+
+// posixClasses are the ranges matched by the POSIX classes of Flex character classes.
+var posixClasses = map[string]string{
+	"alpha":  `a-zA-Z`,
+	"digit":  `0-9`,
+	"alnum":  `a-zA-Z0-9`,
+	"upper":  `A-Z`,
+	"lower":  `a-z`,
+	"xdigit": `0-9a-fA-F`,
+	"space":  `\x20\t\n\r\x0B\x0C`,
+	"blank":  `\x20\t`,
+	// NUL is left out of cntrl, since patterns can't match it.
+	"cntrl":  `\x01-\x1F\x7F`,
+	"punct":  `!-/:-@\[-` + "`" + `\x7B-~`,
+	"print":  `\x20-~`,
+	"graph":  `!-~`,
+}
+
+// A flexRule is a rule of a Flex scanner, after translation.
+type flexRule struct {
+	modes   []string
+	pattern string
+	action  string
+}
+
+// A flexImporter translates a Flex scanner into a lexer description.
+type flexImporter struct {
+	filename string
+	src      string
+	names    *importNames
+
+	// modes holds the declarations of start conditions.
+	modes []string
+
+	// definitions holds the translated definitions, while definitionNames maps their Flex names to their identifiers.
+	definitions     [][2]string
+	definitionNames map[string]string
+
+	rules []flexRule
+
+	// returned holds the tokens returned by the actions.
+	returned []string
+
+	issues []ImportIssue
+}
+
+func newFlexImporter(filename string, src string, names *importNames) *flexImporter {
+	return &flexImporter{
+		filename:        filename,
+		src:             src,
+		names:           names,
+		definitionNames: make(map[string]string),
+	}
+}
+
+// issue records an issue about the construct at position pos of the scanner.
+func (f *flexImporter) issue(pos int, format string, args ...any) {
+	f.issues = append(f.issues, ImportIssue{Filename: f.filename, Line: lineAt(f.src, pos), Message: fmt.Sprintf(format, args...)})
+}
+
+// translate returns the lexer description translated from the scanner.
+func (f *flexImporter) translate() string {
+	pos := f.translateDefinitions()
+	pos = f.translateRules(pos)
+
+	if pos < len(f.src) && strings.TrimSpace(f.src[pos:]) != "" {
+		f.issue(pos, "the user code is C code, and is dropped")
+	}
+
+	return f.description()
+}
+
+// nextLine returns the line beginning at position pos, without its newline, and the position of the following one.
+func (f *flexImporter) nextLine(pos int) (string, int) {
+	end := strings.IndexByte(f.src[pos:], '\n')
+	if end < 0 {
+		return f.src[pos:], len(f.src)
+	}
+
+	return strings.TrimSuffix(f.src[pos:pos+end], "\r"), pos + end + 1
+}
+
+// skipCodeBlock returns the position following the %{ ... %} block beginning at position pos.
+func (f *flexImporter) skipCodeBlock(pos int) int {
+	end := strings.Index(f.src[pos:], "\n%}")
+	if end < 0 {
+		return len(f.src)
+	}
+
+	_, next := f.nextLine(pos + end + 1)
+	return next
+}
+
+// translateDefinitions translates the definitions section, returning the position of the rules section.
+func (f *flexImporter) translateDefinitions() int {
+	pos := 0
+
+	for pos < len(f.src) {
+		start := pos
+
+		var line string
+		line, pos = f.nextLine(pos)
+
+		switch {
+		case separatorRegexp.MatchString(line):
+			return pos
+		case strings.TrimSpace(line) == "":
+		case strings.HasPrefix(line, "%{"), strings.HasPrefix(line, "%top{"):
+			f.issue(start, "C code is dropped")
+			pos = f.skipCodeBlock(start)
+		case strings.HasPrefix(line, "/*"):
+			if end := strings.Index(f.src[start:], "*/"); end >= 0 {
+				_, pos = f.nextLine(start + end)
+			}
+		case line[0] == ' ' || line[0] == '\t':
+			f.issue(start, "indented C code is dropped")
+		case modesRegex.MatchString(line):
+			match := modesRegex.FindStringSubmatch(line)
+			f.modes = append(f.modes, fmt.Sprintf("%%%s %s", match[1], strings.Join(strings.Fields(match[2]), " ")))
+		case strings.HasPrefix(line, "%"):
+			f.issue(start, "%s isn't supported, and is dropped", strings.Fields(line)[0])
+		default:
+			f.translateDefinition(start, line)
+		}
+	}
+
+	f.issue(len(f.src), "the scanner has no rules section")
+	return pos
+}
+
+// translateDefinition translates the definition at position pos.
+func (f *flexImporter) translateDefinition(pos int, line string) {
+	name := line
+	pattern := ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		name, pattern = line[:i], strings.TrimSpace(line[i:])
+	}
+
+	if pattern == "" {
+		f.issue(pos, "definition %s has no pattern, and is dropped", name)
+		return
+	}
+
+	translated, ok := f.translatePattern(pos, pattern)
+	if !ok {
+		f.issue(pos, "definition %s is dropped", name)
+		return
+	}
+
+	f.definitions = append(f.definitions, [2]string{f.definitionName(name), translated})
+}
+
+// definitionName returns the identifier of the definition called name,
+// which can only contain letters in lexer descriptions.
+func (f *flexImporter) definitionName(name string) string {
+	if id, ok := f.definitionNames[name]; ok {
+		return id
+	}
+
+	var sb strings.Builder
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			sb.WriteRune(c)
+		case c >= '0' && c <= '9':
+			sb.WriteString(digitNames[c-'0'])
+		}
+	}
+
+	id := sb.String()
+	if id == "" {
+		id = "D"
+	}
+
+	for f.isDefinitionName(id) {
+		id += "D"
+	}
+
+	f.definitionNames[name] = id
+	return id
+}
+
+// isDefinitionName reports whether id already identifies a definition.
+func (f *flexImporter) isDefinitionName(id string) bool {
+	for _, other := range f.definitionNames {
+		if other == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// translateRules translates the rules section beginning at position pos, returning the position of the user code.
+func (f *flexImporter) translateRules(pos int) int {
+	// scope holds the start conditions of the enclosing <SC>{ ... } scope, if any.
+	var scope []string
+
+	// pending holds the rules whose action is |, which is the one of the following rule.
+	var pending []flexRule
+
+	for pos < len(f.src) {
+		start := pos
+
+		line, next := f.nextLine(pos)
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case separatorRegexp.MatchString(line):
+			return next
+		case trimmed == "":
+			pos = next
+			continue
+		case strings.HasPrefix(line, "%{"):
+			f.issue(start, "C code is dropped")
+			pos = f.skipCodeBlock(start)
+			continue
+		case strings.HasPrefix(trimmed, "/*"):
+			if end := strings.Index(f.src[start:], "*/"); end >= 0 {
+				_, pos = f.nextLine(start + end)
+			} else {
+				pos = len(f.src)
+			}
+			continue
+		case scope != nil && trimmed == "}":
+			scope = nil
+			pos = next
+			continue
+		case scope == nil && (line[0] == ' ' || line[0] == '\t'):
+			f.issue(start, "indented C code is dropped")
+			pos = next
+			continue
+		}
+
+		pos += len(line) - len(strings.TrimLeft(line, " \t"))
+
+		modes := scope
+		if f.src[pos] == '<' && !strings.HasPrefix(f.src[pos:], "<<EOF>>") {
+			end := strings.IndexByte(f.src[pos:], '>')
+			modes = strings.Split(f.src[pos+1:pos+end], ",")
+			pos += end + 1
+
+			// A start condition scope encloses the rules up to its closing brace.
+			if rest, _ := f.nextLine(pos); strings.TrimSpace(rest) == "{" {
+				scope = modes
+				pos = next
+				continue
+			}
+		}
+
+		patternStart := pos
+		pos = f.skipPattern(pos)
+		pattern := f.src[patternStart:pos]
+
+		for pos < len(f.src) && (f.src[pos] == ' ' || f.src[pos] == '\t') {
+			pos++
+		}
+
+		action, actionEnd := f.readAction(pos)
+		pos = actionEnd
+
+		if pattern == "<<EOF>>" {
+			f.issue(start, "<<EOF>> rules aren't supported, and are dropped")
+			continue
+		}
+
+		translated, ok := f.translatePattern(start, pattern)
+		if !ok {
+			f.issue(start, "rule %s is dropped", pattern)
+			continue
+		}
+
+		rule := flexRule{modes: modes, pattern: translated}
+		if strings.TrimSpace(action) == "|" {
+			pending = append(pending, rule)
+			continue
+		}
+
+		rule.action = f.translateAction(start, action)
+		for _, p := range pending {
+			p.action = rule.action
+			f.rules = append(f.rules, p)
+		}
+		pending = nil
+
+		f.rules = append(f.rules, rule)
+	}
+
+	return pos
+}
+
+// skipPattern returns the position of the first whitespace following the pattern beginning at position pos,
+// ignoring the one in strings and character classes.
+func (f *flexImporter) skipPattern(pos int) int {
+	src := f.src
+
+	for pos < len(src) {
+		switch src[pos] {
+		case ' ', '\t', '\r', '\n':
+			return pos
+		case '\\':
+			pos += 2
+		case '"':
+			for pos++; pos < len(src) && src[pos] != '"' && src[pos] != '\n'; pos++ {
+				if src[pos] == '\\' {
+					pos++
+				}
+			}
+			pos++
+		case '[':
+			pos = f.skipClass(pos)
+		default:
+			pos++
+		}
+	}
+
+	return min(pos, len(src))
+}
+
+// skipClass returns the position following the character class beginning at position pos.
+func (f *flexImporter) skipClass(pos int) int {
+	src := f.src
+
+	pos++
+	if pos < len(src) && src[pos] == '^' {
+		pos++
+	}
+	// A bracket at the beginning of the class is part of it.
+	if pos < len(src) && src[pos] == ']' {
+		pos++
+	}
+
+	for pos < len(src) && src[pos] != ']' && src[pos] != '\n' {
+		switch {
+		case src[pos] == '\\':
+			pos += 2
+		case strings.HasPrefix(src[pos:], "[:"):
+			if end := strings.Index(src[pos:], ":]"); end >= 0 {
+				pos += end + 2
+			} else {
+				pos++
+			}
+		default:
+			pos++
+		}
+	}
+
+	return min(pos+1, len(src))
+}
+
+// readAction returns the action beginning at position pos and the position following it.
+func (f *flexImporter) readAction(pos int) (string, int) {
+	switch {
+	case pos >= len(f.src):
+		return "", pos
+	case f.src[pos] == '{':
+		end := skipCBlock(f.src, pos)
+		if end < 0 {
+			f.issue(pos, "unterminated action")
+			return f.src[pos:], len(f.src)
+		}
+
+		_, next := f.nextLine(end)
+		return f.src[pos:end], next
+	case strings.HasPrefix(f.src[pos:], "%{"):
+		end := strings.Index(f.src[pos:], "%}")
+		if end < 0 {
+			return f.src[pos+2:], len(f.src)
+		}
+
+		_, next := f.nextLine(pos + end)
+		return f.src[pos+2 : pos+end], next
+	}
+
+	line, next := f.nextLine(pos)
+	return line, next
+}
+
+// translatePattern translates a Flex pattern, found at position pos, into a regex of lexer descriptions.
+// It reports false if the pattern uses unsupported constructs.
+func (f *flexImporter) translatePattern(pos int, pattern string) (string, bool) {
+	var sb strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+
+		switch {
+		case c == '"':
+			for i++; i < len(pattern) && pattern[i] != '"'; i++ {
+				if pattern[i] == '\\' && i+1 < len(pattern) {
+					var r byte
+					r, i = unescapeFlex(pattern, i)
+					sb.WriteString(regexLiteral(r))
+					continue
+				}
+
+				sb.WriteString(regexLiteral(pattern[i]))
+			}
+		case c == '\\' && i+1 < len(pattern):
+			var r byte
+			r, i = unescapeFlex(pattern, i)
+			sb.WriteString(regexLiteral(r))
+		case c == '[':
+			end := strings.Index(pattern[i:], "]")
+			if end < 0 {
+				f.issue(pos, "unterminated character class in %s", pattern)
+				return "", false
+			}
+
+			class, next, ok := f.translateClass(pos, pattern, i)
+			if !ok {
+				return "", false
+			}
+
+			sb.WriteString(class)
+			i = next - 1
+		case c == '{':
+			end := strings.IndexByte(pattern[i:], '}')
+			if end < 0 {
+				f.issue(pos, "unterminated braces in %s", pattern)
+				return "", false
+			}
+
+			inner := pattern[i+1 : i+end]
+			if inner != "" && inner[0] >= '0' && inner[0] <= '9' {
+				sb.WriteString(pattern[i : i+end+1])
+			} else {
+				fmt.Fprintf(&sb, "{%s}", f.definitionName(inner))
+			}
+
+			i += end
+		case c == '/':
+			f.issue(pos, "trailing context in %s isn't supported", pattern)
+			return "", false
+		case c == '$' && i < len(pattern)-1:
+			sb.WriteString(`\$`)
+		case c == '(' && i+1 < len(pattern) && pattern[i+1] == '?':
+			f.issue(pos, "pattern options in %s aren't supported", pattern)
+			return "", false
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String(), true
+}
+
+// translateClass translates the character class beginning at position i of pattern,
+// returning it along with the position following it.
+func (f *flexImporter) translateClass(pos int, pattern string, i int) (string, int, bool) {
+	var sb strings.Builder
+	sb.WriteByte('[')
+
+	i++
+	if i < len(pattern) && pattern[i] == '^' {
+		sb.WriteByte('^')
+		i++
+	}
+
+	for first := true; i < len(pattern); first = false {
+		c := pattern[i]
+
+		switch {
+		case c == ']' && !first:
+			sb.WriteByte(']')
+
+			// Flex can subtract or add classes, which isn't supported.
+			if strings.HasPrefix(pattern[i+1:], "{-}") || strings.HasPrefix(pattern[i+1:], "{+}") {
+				f.issue(pos, "class operators in %s aren't supported", pattern)
+				return "", 0, false
+			}
+			return sb.String(), i + 1, true
+		case strings.HasPrefix(pattern[i:], "[:"):
+			end := strings.Index(pattern[i:], ":]")
+			if end < 0 {
+				break
+			}
+
+			name := pattern[i+2 : i+end]
+			ranges, ok := posixClasses[name]
+			if !ok {
+				f.issue(pos, "POSIX class [:%s:] in %s isn't supported", name, pattern)
+				return "", 0, false
+			}
+
+			sb.WriteString(ranges)
+			i += end + 2
+			continue
+		case c == '\\' && i+1 < len(pattern):
+			var r byte
+			r, i = unescapeFlex(pattern, i)
+			sb.WriteString(regexLiteral(r))
+			i++
+			continue
+		case c == '-' && !first && i+1 < len(pattern) && pattern[i+1] != ']':
+			sb.WriteByte('-')
+			i++
+			continue
+		}
+
+		sb.WriteString(regexLiteral(c))
+		i++
+	}
+
+	f.issue(pos, "unterminated character class in %s", pattern)
+	return "", 0, false
+}
+
+// unescapeFlex returns the byte denoted by the escape sequence beginning at position i of pattern,
+// and the position of its last byte.
+func unescapeFlex(pattern string, i int) (byte, int) {
+	end := i + 2
+
+	switch c := pattern[i+1]; {
+	case c == 'x':
+		for end < len(pattern) && end < i+4 && strings.IndexByte("0123456789abcdefABCDEF", pattern[end]) >= 0 {
+			end++
+		}
+	case c >= '0' && c <= '7':
+		for end < len(pattern) && end < i+4 && pattern[end] >= '0' && pattern[end] <= '7' {
+			end++
+		}
+	}
+
+	r, ok := unquoteChar(pattern[i:end])
+	if !ok {
+		r = pattern[i+1]
+	}
+
+	return r, end - 1
+}
+
+// regexLiteral returns a regex of lexer descriptions matching exactly c.
+// Braces are written as hexadecimal escapes, since lexer descriptions end patterns at braces.
+func regexLiteral(c byte) string {
+	switch c {
+	case '\n':
+		return `\n`
+	case '\t':
+		return `\t`
+	case '\r':
+		return `\r`
+	case '(', ')', '[', ']', '*', '+', '?', '-', '|', '^', '$', '.', '\\':
+		return `\` + string(c)
+	}
+
+	isAlnum := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+	if !isAlnum && (c <= ' ' || c >= 0x7F || c == '{' || c == '}' || c == '"') {
+		return fmt.Sprintf(`\x%02X`, c)
+	}
+
+	return string(c)
+}
+
+// translateAction translates an action, found at position pos, returning the token it returns or skipping the lexeme,
+// and switching start condition as it does. The rest of the C code is kept as a comment.
+func (f *flexImporter) translateAction(pos int, action string) string {
+	var sb strings.Builder
+	sb.WriteString("{\n")
+
+	residual := action
+	for _, re := range []*regexp.Regexp{flexReturnRegexp, flexBeginRegexp} {
+		residual = re.ReplaceAllString(residual, "")
+	}
+	residual = strings.Trim(residual, "{}; \t\r\n")
+
+	if residual != "" {
+		f.issue(pos, "the C code of the action is kept as a comment, and must be translated by hand")
+
+		for _, line := range strings.Split(strings.TrimSpace(action), "\n") {
+			fmt.Fprintf(&sb, "\t// %s\n", strings.TrimSpace(line))
+		}
+	}
+
+	if match := flexUnsupportedRegexp.FindString(action); match != "" {
+		f.issue(pos, "%s isn't supported", match)
+	}
+
+	if match := flexBeginRegexp.FindStringSubmatch(action); match != nil {
+		mode := match[1]
+		if mode == "0" {
+			mode = initialMode
+		}
+
+		fmt.Fprintf(&sb, "\t*mode = Mode%s\n", mode)
+	}
+
+	returns := flexReturnRegexp.FindAllStringSubmatch(action, -1)
+	if len(returns) == 0 {
+		sb.WriteString("\treturn gopapageno.LexSkip\n}")
+		return sb.String()
+	}
+
+	for _, other := range returns[1:] {
+		if other[1] != returns[0][1] {
+			f.issue(pos, "the action returns different tokens depending on its C code: only %s is kept", returns[0][1])
+			break
+		}
+	}
+
+	target := returns[0][1]
+
+	switch {
+	case strings.HasPrefix(target, "'"):
+		c, ok := unquoteChar(strings.Trim(target, "'"))
+		if !ok {
+			f.issue(pos, "character literal %s isn't a single byte", target)
+		}
+
+		fmt.Fprintf(&sb, "\ttoken.Type = %s\n", f.returnToken(f.names.literal(c)))
+	case strings.HasPrefix(target, "yytext"):
+		// The token is the character literal matched, so it's chosen among the ones of the grammar.
+		sb.WriteString("\tswitch text[0] {\n")
+		for _, c := range f.names.literals() {
+			fmt.Fprintf(&sb, "\tcase %s:\n\t\ttoken.Type = %s\n", quoteGoByte(c), f.names.literal(c))
+		}
+		sb.WriteString("\tdefault:\n\t\treturn gopapageno.LexErr\n\t}\n")
+	default:
+		fmt.Fprintf(&sb, "\ttoken.Type = %s\n", f.returnToken(f.names.symbol(target)))
+	}
+
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// returnToken records that token is returned by some action, and returns it.
+func (f *flexImporter) returnToken(token string) string {
+	if !slices.Contains(f.returned, token) {
+		f.returned = append(f.returned, token)
+	}
+
+	return token
+}
+
+// quoteGoByte returns the Go rune literal of c.
+func quoteGoByte(c byte) string {
+	return fmt.Sprintf("%q", rune(c))
+}
+
+// description returns the translated lexer description.
+func (f *flexImporter) description() string {
+	var sb strings.Builder
+
+	for _, mode := range f.modes {
+		fmt.Fprintf(&sb, "%s\n", mode)
+	}
+
+	fmt.Fprintf(&sb, "\n%%%%\n\n")
+
+	for _, def := range f.definitions {
+		fmt.Fprintf(&sb, "%s %s\n", def[0], def[1])
+	}
+
+	fmt.Fprintf(&sb, "\n%%%%\n")
+
+	for _, rule := range f.rules {
+		sb.WriteString("\n")
+		if rule.modes != nil {
+			fmt.Fprintf(&sb, "<%s>", strings.Join(rule.modes, ","))
+		}
+		fmt.Fprintf(&sb, "%s\n%s\n", rule.pattern, rule.action)
+	}
+
+	fmt.Fprintf(&sb, "\n%%%%\n")
+
+	return sb.String()
+}
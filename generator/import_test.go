@@ -0,0 +1,170 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+// issueMessages returns the messages of issues.
+func issueMessages(issues []ImportIssue) []string {
+	messages := make([]string, len(issues))
+	for i, issue := range issues {
+		messages[i] = issue.Message
+	}
+
+	return messages
+}
+
+// checkIssues checks that every issue contains the corresponding substring of expected.
+func checkIssues(t *testing.T, name string, issues []ImportIssue, expected []string) {
+	t.Helper()
+
+	if len(issues) != len(expected) {
+		t.Errorf("%s: expected %d issues, got %q", name, len(expected), issueMessages(issues))
+		return
+	}
+
+	for i, issue := range issues {
+		if !strings.Contains(issue.Message, expected[i]) {
+			t.Errorf("%s: expected issue %d to contain %q, got %q", name, i, expected[i], issue.Message)
+		}
+	}
+}
+
+func TestYaccTranslateAction(t *testing.T) {
+	const copied = "semantic actions are C code"
+
+	tests := []struct {
+		action     string
+		n          int
+		midActions []int
+		expected   string
+		issues     []string
+	}{
+		{`{ $$ = $1 + $3; }`, 3, nil, `{ $$.Value = $1.Value + $3.Value; }`, nil},
+		{`{ $$ = $<ival>2; }`, 2, nil, `{ $$.Value = $2.Value; }`, []string{"type <ival> of $<ival>2 refers to a C type"}},
+		{`{ $$ = $3; }`, 3, []int{1}, `{ $$.Value = $2.Value; }`, nil},
+		{`{ $$ = $1 + $4; }`, 3, []int{2}, `{ $$.Value = $1.Value + $3.Value; }`, nil},
+		{`{ $$ = $2; }`, 2, []int{1}, `{ $$.Value = $2; }`, []string{"$2 refers to the value of an action in the middle of the rule"}},
+		{`{ $$ = $0; }`, 1, nil, `{ $$.Value = $0; }`, []string{"$0 refers to a value outside of the rule"}},
+		{`{ $$ = $-1; }`, 1, nil, `{ $$.Value = $-1; }`, []string{"$-1 refers to a value outside of the rule"}},
+		{`{ $$ = $4; }`, 3, nil, `{ $$.Value = $4; }`, []string{"$4 refers to a value outside of the rule"}},
+		{`{ $$ = $left; }`, 1, nil, `{ $$.Value = $left; }`, []string{"named reference $left isn't supported"}},
+		{`{ $$ = $[left]; }`, 1, nil, `{ $$.Value = $[left]; }`, []string{"named reference $[left] isn't supported"}},
+		{`{ @$ = @1; }`, 1, nil, `{ @$ = @1; }`, []string{"location @$ isn't supported", "location @1 isn't supported"}},
+	}
+
+	for _, tt := range tests {
+		y := newYaccImporter("test.y", tt.action, newImportNames(), false)
+
+		if got := y.translateAction(yaccToken{kind: yaccAction, text: tt.action}, tt.n, tt.midActions); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.action, tt.expected, got)
+		}
+
+		checkIssues(t, tt.action, y.issues, append([]string{copied}, tt.issues...))
+	}
+}
+
+func TestFlexTranslateAction(t *testing.T) {
+	const kept = "the C code of the action is kept as a comment"
+
+	tests := []struct {
+		action   string
+		expected string
+		issues   []string
+	}{
+		{`{ return NUMBER; }`, "{\n\ttoken.Type = NUMBER\n}", nil},
+		{`return('+');`, "{\n\ttoken.Type = PLUS\n}", nil},
+		{`{ return '\n'; }`, "{\n\ttoken.Type = NEWLINE\n}", nil},
+		{`{ return '\x01'; }`, "{\n\ttoken.Type = CHAROne\n}", nil},
+		{`{ return '\101'; }`, "{\n\ttoken.Type = CHARSixFive\n}", nil},
+		{`{ return 'ab'; }`, "{\n\ttoken.Type = CHARZero\n}", []string{"character literal 'ab' isn't a single byte"}},
+		{`{ return yytext[0]; }`, "{\n\tswitch text[0] {\n\tcase '(':\n\t\ttoken.Type = LPAR\n\tcase ')':\n\t\ttoken.Type = RPAR\n\tdefault:\n\t\treturn gopapageno.LexErr\n\t}\n}", nil},
+		{`{ /* spaces */ }`, "{\n\t// { /* spaces */ }\n\treturn gopapageno.LexSkip\n}", []string{kept}},
+		{`;`, "{\n\treturn gopapageno.LexSkip\n}", nil},
+		{`{ BEGIN(COMMENT); }`, "{\n\t*mode = ModeCOMMENT\n\treturn gopapageno.LexSkip\n}", nil},
+		{`{ BEGIN 0; return END; }`, "{\n\t*mode = ModeINITIAL\n\ttoken.Type = END\n}", nil},
+		{`{ yyless(1); return A; }`, "{\n\t// { yyless(1); return A; }\n\ttoken.Type = A\n}", []string{kept, "yyless isn't supported"}},
+		{"{\n\tif (x) return A;\n\treturn B;\n}", "{\n\t// {\n\t// if (x) return A;\n\t// return B;\n\t// }\n\ttoken.Type = A\n}", []string{kept, "only A is kept"}},
+	}
+
+	for _, tt := range tests {
+		names := newImportNames()
+		names.literal('(')
+		names.literal(')')
+
+		f := newFlexImporter("test.l", tt.action, names)
+
+		if got := f.translateAction(0, tt.action); got != tt.expected {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", tt.action, tt.expected, got)
+		}
+
+		checkIssues(t, tt.action, f.issues, tt.issues)
+	}
+}
+
+func TestUnquoteChar(t *testing.T) {
+	tests := []struct {
+		body     string
+		expected byte
+		ok       bool
+	}{
+		{`a`, 'a', true},
+		{`'`, '\'', true},
+		{`\n`, '\n', true},
+		{`\t`, '\t', true},
+		{`\\`, '\\', true},
+		{`\'`, '\'', true},
+		{`\x41`, 'A', true},
+		{`\101`, 'A', true},
+		{`\0`, 0, true},
+		{`\xZZ`, 0, false},
+		{`\nn`, '\n', false},
+		{`ab`, 0, false},
+		{``, 0, false},
+	}
+
+	for _, tt := range tests {
+		c, ok := unquoteChar(tt.body)
+		if ok != tt.ok || (ok && c != tt.expected) {
+			t.Errorf("unquoteChar(%s) = %q, %v, expected %q, %v", tt.body, c, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestYaccTranslate(t *testing.T) {
+	y := newYaccImporter("calc.y", `%token NUMBER
+%left '+'
+%%
+expr : expr '+' expr { $$ = $1 + $3; }
+     | '(' expr ')' { $$ = $2; }
+     | NUMBER
+     ;
+%%
+`, newImportNames(), false)
+
+	expected := `%axiom Start
+%left PLUS
+
+%%
+
+Start : expr
+{
+	$$.Value = $1.Value
+};
+
+expr : expr PLUS expr
+{ $$.Value = $1.Value + $3.Value; } | LPAR expr RPAR
+{ $$.Value = $2.Value; } | NUMBER
+{
+	$$.Value = $1.Value
+};
+
+%%
+`
+	if got := y.translate(); got != expected {
+		t.Errorf("expected description:\n%s\ngot:\n%s", expected, got)
+	}
+
+	checkIssues(t, "calc.y", y.issues, []string{"semantic actions are C code"})
+}
@@ -0,0 +1,617 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A yaccTokenKind classifies the tokens of Yacc/Bison grammars.
+type yaccTokenKind int
+
+const (
+	yaccIdent yaccTokenKind = iota
+	yaccChar
+	yaccString
+	yaccTag
+	yaccNumber
+	yaccDirective
+	yaccAction
+	yaccColon
+	yaccPipe
+	yaccSemicolon
+	yaccSeparator
+	yaccOther
+)
+
+// A yaccToken is a token of a Yacc/Bison grammar, found at position pos of its source.
+type yaccToken struct {
+	kind yaccTokenKind
+	text string
+	pos  int
+}
+
+// A yaccAlternative is an alternative of a Yacc/Bison rule, after translation.
+type yaccAlternative struct {
+	symbols []string
+	action  string
+	line    int
+}
+
+// A yaccImporter translates a Yacc/Bison grammar into a parser description.
+type yaccImporter struct {
+	filename  string
+	src       string
+	names     *importNames
+	normalize bool
+
+	tokens []yaccToken
+
+	// epilogue is the position of the code following the rules, or -1 if there is none.
+	epilogue int
+
+	// aliases maps the string literals declared as aliases of tokens to their identifiers.
+	aliases map[string]string
+
+	axiom      string
+	precedence [][]string
+	sync       []string
+
+	lhs          []string
+	alternatives map[string][]yaccAlternative
+
+	// translatedActions tells whether the grammar has semantic actions, whose C code is copied.
+	translatedActions bool
+
+	issues []ImportIssue
+}
+
+// yaccRefRegexp matches the references to semantic values and locations in Yacc/Bison actions.
+var yaccRefRegexp = regexp.MustCompile(`([$@])(<[^>]*>)?(\$|-?[0-9]+|[A-Za-z_][A-Za-z0-9_.]*|\[[^\]]*\])`)
+
+func newYaccImporter(filename string, src string, names *importNames, normalize bool) *yaccImporter {
+	return &yaccImporter{
+		filename:     filename,
+		src:          src,
+		names:        names,
+		normalize:    normalize,
+		epilogue:     -1,
+		aliases:      make(map[string]string),
+		alternatives: make(map[string][]yaccAlternative),
+	}
+}
+
+// issue records an issue about the construct at position pos of the grammar.
+func (y *yaccImporter) issue(pos int, format string, args ...any) {
+	y.issues = append(y.issues, ImportIssue{Filename: y.filename, Line: lineAt(y.src, pos), Message: fmt.Sprintf(format, args...)})
+}
+
+// translate returns the parser description translated from the grammar.
+func (y *yaccImporter) translate() string {
+	y.tokenize()
+
+	y.nameSymbols()
+
+	i := y.translateDeclarations()
+	y.translateRules(i)
+
+	if y.epilogue >= 0 && strings.TrimSpace(y.src[y.epilogue:]) != "" {
+		y.issue(y.epilogue, "the epilogue is C code, and is dropped")
+	}
+
+	y.dropUnusedPrecedence()
+	y.wrapAxiom()
+
+	return y.description()
+}
+
+// nameSymbols gives identifiers to the symbols of the grammar before character literals,
+// which get the remaining ones.
+func (y *yaccImporter) nameSymbols() {
+	symbolDirectives := []string{"%token", "%term", "%nterm", "%type", "%start", "%left", "%right", "%nonassoc", "%precedence"}
+
+	directive := ""
+	inRules := false
+
+	for _, t := range y.tokens {
+		switch {
+		case t.kind == yaccSeparator:
+			inRules = true
+		case t.kind == yaccDirective:
+			directive = t.text
+		case t.kind != yaccIdent || t.text == "error":
+		case inRules || slices.Contains(symbolDirectives, directive):
+			y.names.symbol(t.text)
+		}
+	}
+}
+
+// tokenize splits the grammar into tokens.
+func (y *yaccImporter) tokenize() {
+	src := y.src
+	pos := 0
+
+	// Once the rules are over, the epilogue is left as it is.
+	separators := 0
+
+	for pos < len(src) && separators < 2 {
+		c := src[pos]
+		start := pos
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f':
+			pos++
+			continue
+		case strings.HasPrefix(src[pos:], "/*"):
+			end := strings.Index(src[pos+2:], "*/")
+			if end < 0 {
+				pos = len(src)
+			} else {
+				pos += end + 4
+			}
+			continue
+		case strings.HasPrefix(src[pos:], "//"):
+			for pos < len(src) && src[pos] != '\n' {
+				pos++
+			}
+			continue
+		case strings.HasPrefix(src[pos:], "%%"):
+			pos += 2
+			separators++
+			if separators == 2 {
+				y.epilogue = pos
+			}
+			y.tokens = append(y.tokens, yaccToken{kind: yaccSeparator, text: "%%", pos: start})
+			continue
+		case strings.HasPrefix(src[pos:], "%{"):
+			end := strings.Index(src[pos:], "%}")
+			if end < 0 {
+				pos = len(src)
+			} else {
+				pos += end + 2
+			}
+			y.tokens = append(y.tokens, yaccToken{kind: yaccDirective, text: "%{", pos: start})
+			continue
+		case c == '%':
+			pos++
+			for pos < len(src) && (isYaccIdentByte(src[pos]) || src[pos] == '-') {
+				pos++
+			}
+			y.tokens = append(y.tokens, yaccToken{kind: yaccDirective, text: src[start:pos], pos: start})
+			continue
+		case c == '\'':
+			pos = skipCLiteral(src, pos)
+			y.tokens = append(y.tokens, yaccToken{kind: yaccChar, text: src[start:pos], pos: start})
+			continue
+		case c == '"':
+			pos = skipCLiteral(src, pos)
+			y.tokens = append(y.tokens, yaccToken{kind: yaccString, text: src[start:pos], pos: start})
+			continue
+		case c == '<':
+			end := strings.IndexByte(src[pos:], '>')
+			if end < 0 {
+				end = 0
+			}
+			pos += end + 1
+			y.tokens = append(y.tokens, yaccToken{kind: yaccTag, text: src[start:pos], pos: start})
+			continue
+		case c == '{':
+			pos = skipCBlock(src, pos)
+			if pos < 0 {
+				y.issue(start, "unterminated action")
+				pos = len(src)
+			}
+			y.tokens = append(y.tokens, yaccToken{kind: yaccAction, text: src[start:pos], pos: start})
+			continue
+		case c >= '0' && c <= '9':
+			for pos < len(src) && src[pos] >= '0' && src[pos] <= '9' {
+				pos++
+			}
+			y.tokens = append(y.tokens, yaccToken{kind: yaccNumber, text: src[start:pos], pos: start})
+			continue
+		case isYaccIdentByte(c):
+			for pos < len(src) && isYaccIdentByte(src[pos]) {
+				pos++
+			}
+			y.tokens = append(y.tokens, yaccToken{kind: yaccIdent, text: src[start:pos], pos: start})
+			continue
+		}
+
+		kind := yaccOther
+		switch c {
+		case ':':
+			kind = yaccColon
+		case '|':
+			kind = yaccPipe
+		case ';':
+			kind = yaccSemicolon
+		}
+
+		pos++
+		y.tokens = append(y.tokens, yaccToken{kind: kind, text: src[start:pos], pos: start})
+	}
+}
+
+// isYaccIdentByte reports whether c can be part of a Yacc/Bison identifier.
+func isYaccIdentByte(c byte) bool {
+	return c == '_' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// symbolOf returns the identifier of the grammar symbol t, which is an identifier or a literal.
+func (y *yaccImporter) symbolOf(t yaccToken) string {
+	switch t.kind {
+	case yaccChar:
+		c, ok := unquoteChar(strings.Trim(t.text, "'"))
+		if !ok {
+			y.issue(t.pos, "character literal %s isn't a single byte", t.text)
+		}
+		return y.names.literal(c)
+	case yaccString:
+		if id, ok := y.aliases[t.text]; ok {
+			return id
+		}
+
+		y.issue(t.pos, "string literal %s isn't declared as the alias of a token, so it is named after its characters", t.text)
+		return y.names.symbol(strings.Trim(t.text, "\""))
+	default:
+		return y.names.symbol(t.text)
+	}
+}
+
+// translateDeclarations translates the declarations section, returning the index of the first token of the rules.
+func (y *yaccImporter) translateDeclarations() int {
+	directive := yaccToken{}
+
+	// last is the last token declared, to which string aliases refer.
+	last := ""
+
+	i := 0
+	for ; i < len(y.tokens); i++ {
+		t := y.tokens[i]
+
+		switch t.kind {
+		case yaccSeparator:
+			return i + 1
+		case yaccDirective:
+			directive = t
+
+			switch t.text {
+			case "%{":
+				y.issue(t.pos, "the prologue is C code, and is dropped")
+			case "%token", "%term", "%nterm", "%type", "%start":
+			case "%left", "%right", "%nonassoc":
+				y.precedence = append(y.precedence, []string{t.text})
+			case "%precedence":
+				y.issue(t.pos, "%%precedence isn't supported, and is dropped: declare an associativity with %%left, %%right or %%nonassoc")
+			case "%union":
+				y.issue(t.pos, "%%union declares C types, and is dropped: declare the Go type of semantic values with %%type")
+			default:
+				y.issue(t.pos, "%s isn't supported, and is dropped", t.text)
+			}
+			continue
+		case yaccTag:
+			if directive.text == "%token" || directive.text == "%type" || slices.Contains([]string{"%left", "%right", "%nonassoc"}, directive.text) {
+				y.issue(t.pos, "type %s refers to a C type, and is dropped: declare the Go type of semantic values with %%type", t.text)
+			}
+			continue
+		case yaccString:
+			if directive.text == "%token" && last != "" {
+				y.aliases[t.text] = last
+			}
+			continue
+		case yaccIdent, yaccChar:
+		default:
+			continue
+		}
+
+		switch directive.text {
+		case "%token", "%term", "%nterm", "%type":
+			last = y.symbolOf(t)
+		case "%start":
+			y.axiom = y.symbolOf(t)
+		case "%left", "%right", "%nonassoc":
+			line := len(y.precedence) - 1
+			y.precedence[line] = append(y.precedence[line], y.symbolOf(t))
+		}
+	}
+
+	y.issue(len(y.src), "the grammar has no rules section")
+	return i
+}
+
+// translateRules translates the rules section beginning at token i.
+func (y *yaccImporter) translateRules(i int) {
+	for i < len(y.tokens) {
+		t := y.tokens[i]
+
+		switch {
+		case t.kind == yaccSeparator:
+			return
+		case t.kind == yaccSemicolon:
+			i++
+			continue
+		case t.kind != yaccIdent || i+1 >= len(y.tokens) || y.tokens[i+1].kind != yaccColon:
+			y.issue(t.pos, "unexpected %s, where a rule should begin", t.text)
+			i++
+			continue
+		}
+
+		lhs := y.symbolOf(t)
+		if !slices.Contains(y.lhs, lhs) {
+			y.lhs = append(y.lhs, lhs)
+		}
+		if y.axiom == "" {
+			y.axiom = lhs
+		}
+
+		i += 2
+		for {
+			var alt yaccAlternative
+			var ok bool
+
+			alt, ok, i = y.translateAlternative(lhs, i)
+			if ok {
+				y.alternatives[lhs] = append(y.alternatives[lhs], alt)
+			}
+
+			if i >= len(y.tokens) || y.tokens[i].kind != yaccPipe {
+				break
+			}
+			i++
+		}
+	}
+
+}
+
+// translateAlternative translates the alternative of a rule of lhs beginning at token i,
+// returning the index of the token following it. It reports false if the alternative is dropped.
+func (y *yaccImporter) translateAlternative(lhs string, i int) (yaccAlternative, bool, int) {
+	alt := yaccAlternative{symbols: make([]string, 0)}
+	if i < len(y.tokens) {
+		alt.line = lineAt(y.src, y.tokens[i].pos)
+	}
+
+	var action *yaccToken
+
+	// midActions holds the positions of the actions found in the middle of the alternative,
+	// which count as symbols when referring to values.
+	var midActions []int
+
+	dropped := false
+	begin := i
+
+loop:
+	for ; i < len(y.tokens); i++ {
+		t := y.tokens[i]
+
+		if y.isRuleStart(i) {
+			break
+		}
+
+		switch t.kind {
+		case yaccPipe, yaccSemicolon, yaccSeparator:
+			break loop
+		case yaccAction:
+			if action != nil {
+				midActions = append(midActions, len(alt.symbols)+len(midActions))
+				y.issue(action.pos, "actions in the middle of rules aren't supported, and are dropped")
+			}
+			action = &y.tokens[i]
+			continue
+		case yaccDirective:
+			switch t.text {
+			case "%empty":
+			case "%prec":
+				i++
+				if i < len(y.tokens) {
+					y.issue(t.pos, "%%prec isn't supported, and is dropped: the precedence of %s isn't given to the rule", y.tokens[i].text)
+				}
+			case "%dprec", "%merge", "%expect", "%expect-rr":
+				y.issue(t.pos, "%s is only meaningful to GLR parsers, and is dropped", t.text)
+				i++
+			default:
+				y.issue(t.pos, "%s isn't supported in rules, and is dropped", t.text)
+			}
+			continue
+		case yaccIdent, yaccChar, yaccString:
+		default:
+			y.issue(t.pos, "unexpected %s in a rule of %s", t.text, lhs)
+			continue
+		}
+
+		// An action followed by a symbol is in the middle of the alternative.
+		if action != nil {
+			midActions = append(midActions, len(alt.symbols)+len(midActions))
+			y.issue(action.pos, "actions in the middle of rules aren't supported, and are dropped")
+			action = nil
+		}
+
+		if t.kind == yaccIdent && t.text == "error" {
+			dropped = true
+
+			if i+1 < len(y.tokens) && (y.tokens[i+1].kind == yaccChar || y.tokens[i+1].kind == yaccIdent && !y.isRuleStart(i+1)) {
+				if next := y.symbolOf(y.tokens[i+1]); !slices.Contains(y.sync, next) {
+					y.sync = append(y.sync, next)
+				}
+			}
+			continue
+		}
+
+		alt.symbols = append(alt.symbols, y.symbolOf(t))
+	}
+
+	if dropped {
+		y.issue(y.tokens[begin].pos, "rules using the error token aren't supported, and are dropped: "+
+			"the terminals following it are declared with %%sync instead")
+		return alt, false, i
+	}
+
+	if len(alt.symbols) == 0 && !y.normalize {
+		y.issue(y.tokens[min(begin, len(y.tokens)-1)].pos, "empty rule of %s: generate the parser with -normalize to delete it", lhs)
+	}
+
+	if action == nil {
+		if len(alt.symbols) > 0 {
+			alt.action = "{\n\t$$.Value = $1.Value\n}"
+		} else {
+			alt.action = "{\n}"
+		}
+	} else {
+		alt.action = y.translateAction(*action, len(alt.symbols), midActions)
+	}
+
+	return alt, true, i
+}
+
+// isRuleStart reports whether token i begins a rule.
+func (y *yaccImporter) isRuleStart(i int) bool {
+	return i+1 < len(y.tokens) && y.tokens[i].kind == yaccIdent && y.tokens[i+1].kind == yaccColon
+}
+
+// translateAction translates the references to semantic values in an action of an alternative with n symbols,
+// whose actions at positions midActions were dropped. The code of the action is left as it is.
+func (y *yaccImporter) translateAction(action yaccToken, n int, midActions []int) string {
+	if !y.translatedActions {
+		y.issue(action.pos, "semantic actions are C code: their references to values are translated, but the rest is copied as it is and must be translated into Go by hand")
+		y.translatedActions = true
+	}
+
+	return yaccRefRegexp.ReplaceAllStringFunc(action.text, func(ref string) string {
+		match := yaccRefRegexp.FindStringSubmatch(ref)
+
+		if match[1] == "@" {
+			y.issue(action.pos, "location %s isn't supported, and is left as it is", ref)
+			return ref
+		}
+
+		if match[2] != "" {
+			y.issue(action.pos, "type %s of %s refers to a C type, and is dropped", match[2], ref)
+		}
+
+		if match[3] == "$" {
+			return "$$.Value"
+		}
+
+		pos, err := strconv.Atoi(match[3])
+		if err != nil {
+			y.issue(action.pos, "named reference %s isn't supported, and is left as it is", ref)
+			return ref
+		}
+
+		if pos < 1 || pos > n+len(midActions) {
+			y.issue(action.pos, "%s refers to a value outside of the rule, which isn't supported, and is left as it is", ref)
+			return ref
+		}
+
+		// References following the dropped actions are shifted back.
+		shifted := pos
+		for _, mid := range midActions {
+			switch {
+			case mid == pos-1:
+				y.issue(action.pos, "%s refers to the value of an action in the middle of the rule, which is dropped", ref)
+				return ref
+			case mid < pos-1:
+				shifted--
+			}
+		}
+
+		return fmt.Sprintf("$%d.Value", shifted)
+	})
+}
+
+// dropUnusedPrecedence deletes the terminals of the precedence declarations that aren't used in any rule,
+// since they are usually meant for %prec, which isn't supported.
+func (y *yaccImporter) dropUnusedPrecedence() {
+	lines := make([][]string, 0, len(y.precedence))
+
+	for _, line := range y.precedence {
+		kept := []string{line[0]}
+		for _, token := range line[1:] {
+			if y.uses(token) {
+				kept = append(kept, token)
+			} else {
+				y.issues = append(y.issues, ImportIssue{
+					Filename: y.filename,
+					Message:  fmt.Sprintf("%s declares the precedence of %s, which isn't used in any rule, so it is dropped", line[0], token),
+				})
+			}
+		}
+
+		if len(kept) > 1 {
+			lines = append(lines, kept)
+		}
+	}
+
+	y.precedence = lines
+}
+
+// uses reports whether the translated grammar uses symbol in the right-hand side of some rule.
+func (y *yaccImporter) uses(symbol string) bool {
+	for _, alternatives := range y.alternatives {
+		for _, alt := range alternatives {
+			if slices.Contains(alt.symbols, symbol) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// wrapAxiom gives the grammar a new axiom deriving the start symbol if the latter is used in some rule,
+// as Bison does, since the generator drops the rules of the axiom beginning with the axiom itself.
+func (y *yaccImporter) wrapAxiom() {
+	if y.axiom == "" || !y.uses(y.axiom) {
+		return
+	}
+
+	axiom := y.names.unique("Start")
+
+	y.lhs = append([]string{axiom}, y.lhs...)
+	y.alternatives[axiom] = []yaccAlternative{{
+		symbols: []string{y.axiom},
+		action:  "{\n\t$$.Value = $1.Value\n}",
+	}}
+	y.axiom = axiom
+}
+
+// description returns the translated parser description.
+func (y *yaccImporter) description() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%%axiom %s\n", y.axiom)
+	for _, line := range y.precedence {
+		fmt.Fprintf(&sb, "%s\n", strings.Join(line, " "))
+	}
+	if len(y.sync) > 0 {
+		fmt.Fprintf(&sb, "%%sync %s\n", strings.Join(y.sync, " "))
+	}
+
+	fmt.Fprintf(&sb, "\n%%%%\n")
+
+	for _, lhs := range y.lhs {
+		alternatives := y.alternatives[lhs]
+		if len(alternatives) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&sb, "\n%s :", lhs)
+		for i, alt := range alternatives {
+			if i > 0 {
+				sb.WriteString(" |")
+			}
+
+			for _, symbol := range alt.symbols {
+				fmt.Fprintf(&sb, " %s", symbol)
+			}
+			fmt.Fprintf(&sb, "\n%s", alt.action)
+		}
+		sb.WriteString(";\n")
+	}
+
+	fmt.Fprintf(&sb, "\n%%%%\n")
+
+	return sb.String()
+}
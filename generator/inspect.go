@@ -0,0 +1,382 @@
+package generator
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/giornetta/gopapageno"
+)
+
+// An InspectSection is a part of the analysis of a grammar printed by Inspect.
+type InspectSection string
+
+const (
+	// InspectMatrix is the precedence matrix of the terminals.
+	InspectMatrix InspectSection = "matrix"
+	// InspectSets are the left and right terminal sets of the nonterminals.
+	InspectSets InspectSection = "sets"
+	// InspectRules are the rules of the grammar, once transformed as the generator does.
+	InspectRules InspectSection = "rules"
+	// InspectTrie are the statistics of the compressed tries the parser matches rules with.
+	InspectTrie InspectSection = "trie"
+)
+
+// InspectSections are all the sections, in the order they are printed.
+var InspectSections = []InspectSection{InspectMatrix, InspectSets, InspectRules, InspectTrie}
+
+// An InspectFormat is the format of the tables printed by Inspect.
+type InspectFormat string
+
+const (
+	FormatTable InspectFormat = "table"
+	FormatCSV   InspectFormat = "csv"
+	FormatHTML  InspectFormat = "html"
+)
+
+// InspectOptions configures the analysis of a parser description.
+type InspectOptions struct {
+	ParserDescriptionFilename string
+
+	// Sections are the parts of the analysis to print, or all of them if empty.
+	Sections []InspectSection
+
+	Format InspectFormat
+
+	// Strategy and Normalize compile the grammar as the generator would.
+	Strategy  gopapageno.ParsingStrategy
+	Normalize bool
+
+	Output io.Writer
+	Logger *log.Logger
+}
+
+// Inspect compiles a parser description as the generator would,
+// and prints the precedence matrix, the terminal sets, the transformed rules and the rule trie statistics.
+func Inspect(opts *InspectOptions) error {
+	switch opts.Format {
+	case FormatTable, FormatCSV, FormatHTML:
+	default:
+		return fmt.Errorf("unknown format %q: must be one of %s, %s, %s", opts.Format, FormatTable, FormatCSV, FormatHTML)
+	}
+
+	sections := opts.Sections
+	if len(sections) == 0 {
+		sections = InspectSections
+	}
+
+	for _, section := range sections {
+		if !slices.Contains(InspectSections, section) {
+			return fmt.Errorf("unknown section %q", section)
+		}
+	}
+
+	parserFile, err := os.Open(opts.ParserDescriptionFilename)
+	if err != nil {
+		return fmt.Errorf("could not open parser file: %w", err)
+	}
+	defer parserFile.Close()
+
+	genOpts := &Options{
+		Strategy:  opts.Strategy,
+		Normalize: opts.Normalize,
+		Logger:    opts.Logger,
+	}
+
+	p, err := parseGrammarDescription(parserFile, genOpts)
+	if err != nil {
+		return fmt.Errorf("could not parse parser description: %w", err)
+	}
+
+	if err := p.compile(genOpts); err != nil {
+		return fmt.Errorf("could not compile parser: %w", err)
+	}
+
+	tables := make([]inspectTable, 0, len(sections))
+	for _, section := range InspectSections {
+		if !slices.Contains(sections, section) {
+			continue
+		}
+
+		var table inspectTable
+		switch section {
+		case InspectMatrix:
+			table = p.matrixTable()
+		case InspectSets:
+			table = p.terminalSetsTable()
+		case InspectRules:
+			table = p.rulesTable()
+		case InspectTrie:
+			if table, err = p.trieTable(opts.Strategy); err != nil {
+				return fmt.Errorf("could not build rule trie: %w", err)
+			}
+		}
+
+		tables = append(tables, table)
+	}
+
+	switch opts.Format {
+	case FormatCSV:
+		return writeCSVTables(opts.Output, tables)
+	case FormatHTML:
+		return writeHTMLTables(opts.Output, opts.ParserDescriptionFilename, tables)
+	default:
+		return writeTextTables(opts.Output, tables)
+	}
+}
+
+// An inspectTable is a section of the analysis of a grammar.
+type inspectTable struct {
+	title  string
+	header []string
+	rows   [][]string
+
+	// precedences tells whether the cells following the first column of each row are precedences.
+	precedences bool
+}
+
+// matrixTable returns the precedence matrix, whose rows are the left terminals and whose columns are the right ones.
+func (p *grammarDescription) matrixTable() inspectTable {
+	terminals := p.terminals.Slice()
+	// The matrix is indexed in this order, which can't fail since compile() built it.
+	_ = moveToFront(terminals, termToken)
+
+	table := inspectTable{
+		title:       "Precedence matrix",
+		header:      append([]string{""}, terminals...),
+		precedences: true,
+	}
+
+	for i, left := range terminals {
+		row := []string{left}
+		for j := range terminals {
+			row = append(row, p.precMatrix[i][j].String())
+		}
+
+		table.rows = append(table.rows, row)
+	}
+
+	return table
+}
+
+// terminalSetsTable returns the left and right terminal sets of the nonterminals.
+func (p *grammarDescription) terminalSetsTable() inspectTable {
+	table := inspectTable{
+		title:  "Terminal sets",
+		header: []string{"Nonterminal", "Left terminals", "Right terminals"},
+	}
+
+	for _, nonterminal := range p.nonterminals.Slice() {
+		if nonterminal == emptyToken {
+			continue
+		}
+
+		table.rows = append(table.rows, []string{
+			nonterminal,
+			strings.Join(p.leftTerminals[nonterminal].Slice(), " "),
+			strings.Join(p.rightTerminals[nonterminal].Slice(), " "),
+		})
+	}
+
+	return table
+}
+
+// rulesTable returns the rules the parser is generated with, numbered as semantic functions are.
+func (p *grammarDescription) rulesTable() inspectTable {
+	table := inspectTable{
+		title:  "Rules",
+		header: []string{"#", "Rule", "Flags"},
+	}
+
+	for i, rule := range p.rules {
+		table.rows = append(table.rows, []string{fmt.Sprint(i), rule.String(), rule.Flags.String()})
+	}
+
+	return table
+}
+
+// trieStats are the statistics of a rule trie and of its compressed form.
+type trieStats struct {
+	nodes       int
+	matches     int
+	depth       int
+	maxBranches int
+	compressed  int
+}
+
+// stats returns the statistics of the trie rooted at n, at the given depth.
+func (n *trieNode) stats(depth int) trieStats {
+	s := trieStats{
+		nodes:       1,
+		depth:       depth,
+		maxBranches: len(n.Branches),
+	}
+	if n.HasValue {
+		s.matches++
+	}
+
+	for _, branch := range n.Branches {
+		child := branch.Ptr.stats(depth + 1)
+
+		s.nodes += child.nodes
+		s.matches += child.matches
+		s.depth = max(s.depth, child.depth)
+		s.maxBranches = max(s.maxBranches, child.maxBranches)
+	}
+
+	return s
+}
+
+// trieTable returns the statistics of the tries of the rules and, with COPP, of their prefixes.
+func (p *grammarDescription) trieTable(strategy gopapageno.ParsingStrategy) (inspectTable, error) {
+	table := inspectTable{
+		title:  "Rule trie",
+		header: []string{"", "Rules"},
+	}
+
+	tries := make([]*trieNode, 0, 2)
+
+	rulesTrie, err := newRulesTrie(p.rules, p.nonterminals, p.terminals)
+	if err != nil {
+		return table, err
+	}
+	tries = append(tries, rulesTrie)
+
+	if strategy == gopapageno.COPP {
+		prefixesTrie, err := newPrefixesTrie(p.rules, p.nonterminals, p.terminals)
+		if err != nil {
+			return table, err
+		}
+
+		table.header = append(table.header, "Prefixes")
+		tries = append(tries, prefixesTrie)
+	}
+
+	stats := make([]trieStats, len(tries))
+	for i, trie := range tries {
+		stats[i] = trie.stats(0)
+		stats[i].compressed = len(trie.Compress(p.nonterminals, p.terminals))
+	}
+
+	for _, property := range []struct {
+		name  string
+		value func(s trieStats) any
+	}{
+		{"Nodes", func(s trieStats) any { return s.nodes }},
+		{"Matching nodes", func(s trieStats) any { return s.matches }},
+		{"Depth", func(s trieStats) any { return s.depth }},
+		{"Max branches", func(s trieStats) any { return s.maxBranches }},
+		{"Compressed length", func(s trieStats) any { return s.compressed }},
+		{"Compressed bytes", func(s trieStats) any { return s.compressed * 2 }},
+		// Compressed tries are indexed with uint16 offsets.
+		{"Offset limit used", func(s trieStats) any { return fmt.Sprintf("%.2f%%", float64(s.compressed)*100/(1<<16)) }},
+	} {
+		row := []string{property.name}
+		for _, s := range stats {
+			row = append(row, fmt.Sprint(property.value(s)))
+		}
+
+		table.rows = append(table.rows, row)
+	}
+
+	return table, nil
+}
+
+// writeTextTables writes tables as aligned text, one after the other.
+func writeTextTables(w io.Writer, tables []inspectTable) error {
+	for i, table := range tables {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "--- %s:\n", table.title)
+
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(table.header, "\t"))
+		for _, row := range table.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeCSVTables writes tables as CSV records, each one beginning with its title and its header,
+// and separated by an empty line.
+func writeCSVTables(w io.Writer, tables []inspectTable) error {
+	for i, table := range tables {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{table.title}); err != nil {
+			return err
+		}
+		if err := cw.Write(table.header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(table.rows); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeHTMLTables writes tables as an HTML document, coloring precedences.
+func writeHTMLTables(w io.Writer, title string, tables []inspectTable) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n", html.EscapeString(title))
+	sb.WriteString(`<style>
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; }
+td.Yields { background: #dbe9ff; }
+td.Equals { background: #e2f5dc; }
+td.Takes { background: #ffe3d6; }
+td.Empty { color: #aaa; }
+</style>
+</head>
+<body>
+`)
+
+	for _, table := range tables {
+		fmt.Fprintf(&sb, "<h2>%s</h2>\n<table>\n<tr>", html.EscapeString(table.title))
+		for _, cell := range table.header {
+			fmt.Fprintf(&sb, "<th>%s</th>", html.EscapeString(cell))
+		}
+		sb.WriteString("</tr>\n")
+
+		for _, row := range table.rows {
+			sb.WriteString("<tr>")
+			for j, cell := range row {
+				switch {
+				case j == 0:
+					fmt.Fprintf(&sb, "<th>%s</th>", html.EscapeString(cell))
+				case table.precedences:
+					fmt.Fprintf(&sb, "<td class=\"%s\">%s</td>", cell, cell)
+				default:
+					fmt.Fprintf(&sb, "<td>%s</td>", html.EscapeString(cell))
+				}
+			}
+			sb.WriteString("</tr>\n")
+		}
+
+		sb.WriteString("</table>\n")
+	}
+
+	sb.WriteString("</body>\n</html>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
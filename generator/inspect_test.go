@@ -0,0 +1,165 @@
+package generator
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/giornetta/gopapageno"
+)
+
+const inspectedGrammar = `%axiom S
+
+%%
+
+S : E
+{
+};
+
+E : E PLUS NUMBER
+{
+} | NUMBER
+{
+};
+
+%%`
+
+// inspect writes the grammar description src to a file and inspects it.
+func inspect(t *testing.T, src string, format InspectFormat, sections ...InspectSection) (string, error) {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "sum.g")
+	if err := os.WriteFile(filename, []byte(src), 0644); err != nil {
+		t.Fatalf("could not write grammar: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err := Inspect(&InspectOptions{
+		ParserDescriptionFilename: filename,
+		Sections:                  sections,
+		Format:                    format,
+		Strategy:                  gopapageno.OPP,
+		Output:                    &buf,
+		Logger:                    log.New(io.Discard, "", 0),
+	})
+
+	return buf.String(), err
+}
+
+func TestInspectFormats(t *testing.T) {
+	tests := []struct {
+		format   InspectFormat
+		expected string
+	}{
+		{FormatTable, `--- Precedence matrix:
+          __TERM__  NUMBER  PLUS
+__TERM__  Equals    Yields  Yields
+NUMBER    Takes     Empty   Takes
+PLUS      Takes     Empty   Empty
+
+--- Rules:
+#  Rule                Flags
+0  S -> E              RuleSimple
+1  E -> E PLUS NUMBER  RuleSimple
+2  E -> NUMBER         RuleSimple
+`},
+		{FormatCSV, `Precedence matrix
+,__TERM__,NUMBER,PLUS
+__TERM__,Equals,Yields,Yields
+NUMBER,Takes,Empty,Takes
+PLUS,Takes,Empty,Empty
+
+Rules
+#,Rule,Flags
+0,S -> E,RuleSimple
+1,E -> E PLUS NUMBER,RuleSimple
+2,E -> NUMBER,RuleSimple
+`},
+	}
+
+	for _, tt := range tests {
+		// Sections are printed in their own order, whatever the order they are asked in.
+		out, err := inspect(t, inspectedGrammar, tt.format, InspectRules, InspectMatrix)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.format, err)
+			continue
+		}
+
+		if out != tt.expected {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", tt.format, tt.expected, out)
+		}
+	}
+}
+
+func TestInspectHTML(t *testing.T) {
+	out, err := inspect(t, inspectedGrammar, FormatHTML, InspectMatrix, InspectRules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, s := range []string{
+		"<h2>Precedence matrix</h2>",
+		`<tr><th>NUMBER</th><td class="Takes">Takes</td><td class="Empty">Empty</td><td class="Takes">Takes</td></tr>`,
+		"<h2>Rules</h2>",
+		"<tr><th>1</th><td>E -&gt; E PLUS NUMBER</td><td>RuleSimple</td></tr>",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected the document to contain %q, got:\n%s", s, out)
+		}
+	}
+
+	if strings.Contains(out, "Terminal sets") {
+		t.Errorf("expected only the sections asked for, got:\n%s", out)
+	}
+
+	if !strings.HasPrefix(out, "<!DOCTYPE html>") || !strings.HasSuffix(out, "</html>\n") {
+		t.Errorf("expected a whole HTML document, got:\n%s", out)
+	}
+}
+
+func TestInspectSections(t *testing.T) {
+	out, err := inspect(t, inspectedGrammar, FormatTable)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	titles := []string{"--- Precedence matrix:", "--- Terminal sets:", "--- Rules:", "--- Rule trie:"}
+
+	last := -1
+	for _, title := range titles {
+		i := strings.Index(out, title)
+		if i <= last {
+			t.Errorf("expected section %q to follow the previous one, got:\n%s", title, out)
+		}
+		last = i
+	}
+
+	if !strings.Contains(out, "E            NUMBER PLUS     NUMBER") {
+		t.Errorf("expected the terminal sets of E, got:\n%s", out)
+	}
+
+	if !strings.Contains(out, "Matching nodes     3") {
+		t.Errorf("expected a trie node for each rule, got:\n%s", out)
+	}
+}
+
+func TestInspectErrors(t *testing.T) {
+	tests := []struct {
+		format   InspectFormat
+		sections []InspectSection
+		err      string
+	}{
+		{"xml", nil, `unknown format "xml"`},
+		{FormatTable, []InspectSection{InspectRules, "stats"}, `unknown section "stats"`},
+	}
+
+	for _, tt := range tests {
+		if _, err := inspect(t, inspectedGrammar, tt.format, tt.sections...); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("expected error %q, got %v", tt.err, err)
+		}
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"bufio"
 	"fmt"
 	"github.com/giornetta/gopapageno/generator/regex"
+	"go/scanner"
+	"go/token"
 	"io"
 	"log"
 	"os"
 	"path"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -19,6 +22,9 @@ var (
 
 	cutPointsRegex = regexp.MustCompile("^%cut\\s*(\\S.*)$")
 	preambleRegex  = regexp.MustCompile("^%preamble\\s*(\\S.*)$")
+	sourceRegex    = regexp.MustCompile("^%source\\s*(\\S.*)$")
+	threadRegex    = regexp.MustCompile("^%threadstate\\s*(\\S.*)$")
+	modesRegex     = regexp.MustCompile("^%([sx])\\s+(\\S.*)$")
 
 	definitionRegex = regexp.MustCompile("^([a-zA-Z][a-zA-Z0-9]*)\\s*(.+)$")
 )
@@ -30,23 +36,72 @@ type lexerDescriptor struct {
 
 	preambleFunc string
 
+	// sourceFunc is the function reading tokens by hand, declared with %source.
+	sourceFunc string
+
+	// threadStateFunc is the function allocating the state of each thread, declared with %threadstate.
+	threadStateFunc string
+
+	// modes holds the start conditions declared besides the initial one.
+	modes []lexMode
+
 	// dfa is nil until compile() is executed successfully.
 	dfa regex.Dfa
 
+	// modeDfas holds the DFA of each declared start condition after compile() is executed successfully.
+	modeDfas []regex.Dfa
+
 	// cutPointsDfa is nil until compile() is executed successfully.
 	cutPointsDfa regex.Dfa
+
+	// anchors holds the anchors of each rule after compile() is executed successfully.
+	// It is nil if no rule is anchored.
+	anchors []regex.Anchors
+}
+
+// initialMode is the name of the start condition in which lexing begins.
+const initialMode = "INITIAL"
+
+// A lexMode is a start condition declared with %s (inclusive) or %x (exclusive).
+// Rules without a start condition prefix are active in the initial start condition and in inclusive ones.
+type lexMode struct {
+	Name      string
+	Exclusive bool
 }
 
 // A lexRule matches a specific regex pattern to a semantic action to be performed during lexing.
 type lexRule struct {
 	Regex  string
 	Action string
+
+	// Modes holds the start conditions listed by the <STATE> prefix of the rule, or * if it is active in all of them.
+	// It is nil if the rule has no prefix.
+	Modes []string
 }
 
 func (r lexRule) String() string {
+	if r.Modes != nil {
+		return fmt.Sprintf("<%s>%s: %s", strings.Join(r.Modes, ","), r.Regex, r.Action)
+	}
+
 	return fmt.Sprintf("%s: %s", r.Regex, r.Action)
 }
 
+// activeIn reports whether the rule is active in the start condition.
+func (r lexRule) activeIn(mode lexMode) bool {
+	if r.Modes == nil {
+		return mode.Name == initialMode || !mode.Exclusive
+	}
+
+	for _, name := range r.Modes {
+		if name == "*" || name == mode.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
 func parseLexerDescription(r io.Reader, logger *log.Logger) (*lexerDescriptor, error) {
 	logger.Printf("Parsing lexer description file...\n")
 
@@ -55,6 +110,10 @@ func parseLexerDescription(r io.Reader, logger *log.Logger) (*lexerDescriptor, e
 	//Scan the definitions section
 	cutPoints := ""
 	preambleFunc := ""
+	sourceFunc := ""
+	threadStateFunc := ""
+
+	var modes []lexMode
 
 	definitions := make(map[string]string)
 
@@ -68,6 +127,21 @@ func parseLexerDescription(r io.Reader, logger *log.Logger) (*lexerDescriptor, e
 			cutPoints = match[1]
 		} else if match := preambleRegex.FindStringSubmatch(l); match != nil {
 			preambleFunc = match[1]
+		} else if match := sourceRegex.FindStringSubmatch(l); match != nil {
+			sourceFunc = match[1]
+		} else if match := threadRegex.FindStringSubmatch(l); match != nil {
+			threadStateFunc = match[1]
+		} else if match := modesRegex.FindStringSubmatch(l); match != nil {
+			for _, name := range strings.Fields(match[2]) {
+				if !isModeName(name) {
+					return nil, fmt.Errorf("invalid start condition name: %s", name)
+				}
+				if name == initialMode || slices.ContainsFunc(modes, func(m lexMode) bool { return m.Name == name }) {
+					return nil, fmt.Errorf("start condition %s is declared more than once", name)
+				}
+
+				modes = append(modes, lexMode{Name: name, Exclusive: match[1] == "x"})
+			}
 		} else if l != "" {
 			return nil, fmt.Errorf("unrecognized lexer option: %s", l)
 		}
@@ -75,6 +149,11 @@ func parseLexerDescription(r io.Reader, logger *log.Logger) (*lexerDescriptor, e
 
 	logger.Printf("Cut Points: %s\n", cutPoints)
 	logger.Printf("Preamble Func: %s\n", cutPoints)
+	logger.Printf("Source Func: %s\n", sourceFunc)
+	logger.Printf("Thread State Func: %s\n", threadStateFunc)
+	for _, mode := range modes {
+		logger.Printf("Start Condition: %s (exclusive: %t)\n", mode.Name, mode.Exclusive)
+	}
 
 	for scanner.Scan() {
 		l := scanner.Text()
@@ -105,7 +184,7 @@ func parseLexerDescription(r io.Reader, logger *log.Logger) (*lexerDescriptor, e
 		sb.WriteString("\n")
 	}
 
-	lexRules, err := parseLexRules(sb.String(), definitions)
+	lexRules, err := parseLexRules(sb.String(), definitions, modes)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse lexer rules: %w", err)
 	}
@@ -128,22 +207,31 @@ func parseLexerDescription(r io.Reader, logger *log.Logger) (*lexerDescriptor, e
 	code := sb.String()
 
 	return &lexerDescriptor{
-		rules:        lexRules,
-		cutPoints:    cutPoints,
-		code:         code,
-		preambleFunc: preambleFunc,
+		rules:           lexRules,
+		cutPoints:       cutPoints,
+		code:            code,
+		preambleFunc:    preambleFunc,
+		sourceFunc:      sourceFunc,
+		threadStateFunc: threadStateFunc,
+		modes:           modes,
 	}, nil
 }
 
-func parseLexRules(input string, definitions map[string]string) ([]lexRule, error) {
+func parseLexRules(input string, definitions map[string]string, modes []lexMode) ([]lexRule, error) {
 	lexRules := make([]lexRule, 0)
 
 	var pos int
 	skipSpaces(input, &pos)
 
 	var regexBuilder strings.Builder
+	var ruleModes []string
 
 	for pos < len(input) {
+		// A rule may begin with the list of start conditions in which it is active.
+		if regexBuilder.Len() == 0 && ruleModes == nil {
+			ruleModes = getStartConditions(input, &pos, modes)
+		}
+
 		startingPos := pos
 
 		// Read anything until a { is reached
@@ -160,6 +248,21 @@ func parseLexRules(input string, definitions map[string]string) ([]lexRule, erro
 		leftCurlyPos := pos
 
 		pos++
+
+		// A { followed by a digit begins a counted repetition, which is part of the regex.
+		if pos < len(input) && input[pos] >= '0' && input[pos] <= '9' {
+			for pos < len(input) && input[pos] != '}' {
+				pos++
+			}
+			if pos >= len(input) {
+				break
+			}
+
+			pos++
+			regexBuilder.WriteString(input[startingPos:pos])
+			continue
+		}
+
 		identifier := getIdentifier(input, &pos)
 
 		rightCurlyPos := pos
@@ -183,9 +286,11 @@ func parseLexRules(input string, definitions map[string]string) ([]lexRule, erro
 			lexRules = append(lexRules, lexRule{
 				Regex:  strings.Trim(regexBuilder.String(), " \t\r\n"),
 				Action: semFun,
+				Modes:  ruleModes,
 			})
 
 			regexBuilder.Reset()
+			ruleModes = nil
 
 			skipSpaces(input, &pos)
 		}
@@ -194,6 +299,44 @@ func parseLexRules(input string, definitions map[string]string) ([]lexRule, erro
 	return lexRules, nil
 }
 
+// getStartConditions reads the <STATE1,STATE2> prefix of a rule, if there is one at position pos.
+// A prefix only lists declared start conditions, INITIAL or *: anything else is the beginning of a regex, and is left unread.
+func getStartConditions(input string, pos *int, modes []lexMode) []string {
+	if *pos >= len(input) || input[*pos] != '<' {
+		return nil
+	}
+
+	end := strings.IndexByte(input[*pos:], '>')
+	if end < 0 {
+		return nil
+	}
+
+	names := strings.Split(input[*pos+1:*pos+end], ",")
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+
+		declared := slices.ContainsFunc(modes, func(m lexMode) bool { return m.Name == names[i] })
+		if !declared && names[i] != initialMode && names[i] != "*" {
+			return nil
+		}
+	}
+
+	*pos += end + 1
+	return names
+}
+
+// isModeName reports whether name is a valid start condition name, which must be a valid Go identifier.
+func isModeName(name string) bool {
+	for i, c := range name {
+		isLetter := c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		if !isLetter && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return name != ""
+}
+
 // compile builds a DFA from the rules defined in the description file.
 // It uses a Regexp parser generated by Gopapageno itself (don't ask me why, I tried refactoring this but it's hard, I'm hiding this here for now)
 // TODO: Build a hand-written Regexp Parser?
@@ -202,48 +345,99 @@ func (l *lexerDescriptor) compile() error {
 		return fmt.Errorf("the lexer descriptor does not contain any rules")
 	}
 
-	success, result := regex.ParseString([]byte(l.rules[0].Regex), 1)
-	if !success {
-		return fmt.Errorf("could not parse regular expression %s", l.rules[0].Regex)
-	}
-
-	nfa := result.Value.(*regex.Nfa)
-	nfa.AddAssociatedRule(0)
+	var anchors []regex.Anchors
 
-	for i := 1; i < len(l.rules); i++ {
-		success, result := regex.ParseString([]byte(l.rules[i].Regex), 1)
-		if !success {
-			return fmt.Errorf("could not parse regular expression %s", l.rules[i].Regex)
+	for i, rule := range l.rules {
+		// Anchors are checked by the scanner when a match is found, so they are stripped from the regex.
+		_, ruleAnchors := regex.SplitAnchors([]byte(rule.Regex))
+		if ruleAnchors != 0 && anchors == nil {
+			anchors = make([]regex.Anchors, len(l.rules))
+		}
+		if anchors != nil {
+			anchors[i] = ruleAnchors
 		}
+	}
 
-		curNfa := result.Value.(*regex.Nfa)
-		curNfa.AddAssociatedRule(i)
+	dfa, err := l.compileMode(lexMode{Name: initialMode})
+	if err != nil {
+		return err
+	}
 
-		nfa.Unite(*curNfa)
+	// Each start condition gets its own DFA, matching only the rules active in it.
+	modeDfas := make([]regex.Dfa, len(l.modes))
+	for i, mode := range l.modes {
+		if modeDfas[i], err = l.compileMode(mode); err != nil {
+			return err
+		}
 	}
 
-	dfa := nfa.ToDfa()
+	l.dfa = dfa
+	l.modeDfas = modeDfas
+	l.anchors = anchors
 
 	var cutPointsDfa regex.Dfa
 	if l.cutPoints == "" {
-		cutPointsNfa := regex.NewEmptyStringNfa()
-		cutPointsDfa = cutPointsNfa.ToDfa()
+		// Without a %cut directive, sources are cut before the bytes that always begin a token.
+		if cuts, found := l.inferCutPoints(); found {
+			cutPointsNfa := regex.NewCharClassNfa(cuts)
+			nfaDfa := cutPointsNfa.ToDfa()
+			cutPointsDfa = nfaDfa.Minimize()
+		} else {
+			// Sources are cut anywhere, and the scanner re-synchronizes the portions.
+			cutPointsNfa := regex.NewEmptyStringNfa()
+			cutPointsDfa = cutPointsNfa.ToDfa()
+		}
 	} else {
 		success, result := regex.ParseString([]byte(l.cutPoints), 1)
 		if !success {
-			return fmt.Errorf("could not parse regular expression %s", l.rules[0].Regex)
+			return fmt.Errorf("could not parse regular expression %s", l.cutPoints)
 		}
 
 		cutPointsNfa := result.Value.(*regex.Nfa)
-		cutPointsDfa = cutPointsNfa.ToDfa()
+		nfaDfa := cutPointsNfa.ToDfa()
+		cutPointsDfa = nfaDfa.Minimize()
 	}
 
-	l.dfa = dfa
 	l.cutPointsDfa = cutPointsDfa
 
 	return nil
 }
 
+// compileMode builds the minimal DFA matching the rules active in a start condition.
+// Its final states are associated to the numbers of the rules among all of them.
+func (l *lexerDescriptor) compileMode(mode lexMode) (regex.Dfa, error) {
+	var nfa *regex.Nfa
+
+	for i, rule := range l.rules {
+		if !rule.activeIn(mode) {
+			continue
+		}
+
+		expr, _ := regex.SplitAnchors([]byte(rule.Regex))
+
+		success, result := regex.ParseString(expr, 1)
+		if !success {
+			return regex.Dfa{}, fmt.Errorf("could not parse regular expression %s", rule.Regex)
+		}
+
+		curNfa := result.Value.(*regex.Nfa)
+		curNfa.AddAssociatedRule(i)
+
+		if nfa == nil {
+			nfa = curNfa
+		} else {
+			nfa.Unite(*curNfa)
+		}
+	}
+
+	if nfa == nil {
+		return regex.Dfa{}, fmt.Errorf("start condition %s does not contain any rules", mode.Name)
+	}
+
+	nfaDfa := nfa.ToDfa()
+	return nfaDfa.Minimize(), nil
+}
+
 func (l *lexerDescriptor) emit(opts *Options, packageName string) error {
 	lPath := path.Join(opts.OutputDirectory, GeneratedLexerFilename)
 	opts.Logger.Printf("Creating lexer file %s...\n", lPath)
@@ -265,6 +459,10 @@ func (l *lexerDescriptor) emit(opts *Options, packageName string) error {
 	 ********/
 	fmt.Fprintf(f, l.code)
 
+	if len(l.modes) > 0 {
+		emitModes(f, l.modes)
+	}
+
 	// NewLexer function starts here.
 	fmt.Fprintf(f, "\n\nfunc NewLexer() *gopapageno.Lexer {\n")
 
@@ -274,15 +472,36 @@ func (l *lexerDescriptor) emit(opts *Options, packageName string) error {
 	fmt.Fprintf(f, "\tautomaton := ")
 	emitAutomata(f, l.dfa)
 
+	for i, dfa := range l.modeDfas {
+		fmt.Fprintf(f, "\tmodeAutomaton%d := ", i+1)
+		emitAutomata(f, dfa)
+	}
+
 	fmt.Fprintf(f, "\tcutPointsAutomaton := ")
 	emitAutomata(f, l.cutPointsDfa)
 
 	/******************
 	 * Lexer Function *
 	 ******************/
-	fmt.Fprintf(f, "\tfn := func(ruleDescription int, text string, start int, end int, thread int, token *gopapageno.Token) gopapageno.LexResult {\n")
+	stateType, err := preambleStateType(l.code, l.preambleFunc)
+	if err != nil {
+		return fmt.Errorf("could not inspect lexer preamble: %w", err)
+	}
+
+	threadStateType, err := preambleStateType(l.code, l.threadStateFunc)
+	if err != nil {
+		return fmt.Errorf("could not inspect lexer thread state: %w", err)
+	}
+	if l.threadStateFunc != "" && threadStateType == "" {
+		return fmt.Errorf("thread state function %s must be declared in the code section and return a value", l.threadStateFunc)
+	}
+
+	fmt.Fprintf(f, "\tfn := func(ctx *gopapageno.LexContext) gopapageno.LexResult {\n")
+	emitContextLocals(f, l.rules, stateType != "", threadStateType != "")
+	emitStateAssertion(f, stateType)
+	emitThreadStateAssertion(f, threadStateType)
 	fmt.Fprintf(f, "\t\ttoken.Type = gopapageno.TokenTerm\n")
-	fmt.Fprintf(f, "\t\tswitch ruleDescription {\n")
+	fmt.Fprintf(f, "\t\tswitch ctx.Rule {\n")
 	for i, rule := range l.rules {
 		fmt.Fprintf(f, "\t\tcase %d:\n", i)
 		for _, line := range strings.Split(rule.Action, "\n") {
@@ -300,8 +519,33 @@ func (l *lexerDescriptor) emit(opts *Options, packageName string) error {
 	fmt.Fprintf(f, "\t\tCutPointsAutomaton: cutPointsAutomaton,\n")
 	fmt.Fprintf(f, "\t\tFunc: fn,\n")
 
+	if l.sourceFunc != "" {
+		fmt.Fprintf(f, "\t\tSource: gopapageno.TokenSourceFunc(%s),\n", l.sourceFunc)
+	}
+
+	if len(l.modeDfas) > 0 {
+		fmt.Fprintf(f, "\t\tModeAutomata: []gopapageno.LexerDFA{")
+		for i := range l.modeDfas {
+			if i > 0 {
+				fmt.Fprintf(f, ", ")
+			}
+			fmt.Fprintf(f, "modeAutomaton%d", i+1)
+		}
+		fmt.Fprintf(f, "},\n")
+	}
+
+	if l.anchors != nil {
+		emitAnchors(f, l.anchors)
+	}
+
 	if l.preambleFunc != "" {
-		fmt.Fprintf(f, "\t\tPreambleFunc: LexerPreallocMem,\n")
+		emitPreamble(f, l.preambleFunc, stateType)
+	}
+
+	if l.threadStateFunc != "" {
+		fmt.Fprintf(f, "\t\tThreadStateFunc: func(sourceLen, concurrency, _ int) any {\n")
+		fmt.Fprintf(f, "\t\t\treturn %s(sourceLen, concurrency)\n", l.threadStateFunc)
+		fmt.Fprintf(f, "\t\t},\n")
 	}
 
 	fmt.Fprintf(f, "\t}\n}\n")
@@ -309,26 +553,134 @@ func (l *lexerDescriptor) emit(opts *Options, packageName string) error {
 	return nil
 }
 
+// contextLocals lists the locals lexer actions can use, along with the field of the LexContext each one holds.
+var contextLocals = []struct {
+	name  string
+	field string
+}{
+	{"text", "Text"},
+	{"start", "Start"},
+	{"end", "End"},
+	{"thread", "Thread"},
+	{"token", "Token"},
+	{"prev", "Prev"},
+	{"mode", "Mode"},
+	{"runState", "State"},
+	{"runThreadState", "ThreadState"},
+}
+
+// emitContextLocals writes the locals of contextLocals used by the actions of rules, taking them from the LexContext.
+// The token is always used, as are the states when their types are asserted.
+func emitContextLocals(f io.Writer, rules []lexRule, state bool, threadState bool) {
+	used := map[string]bool{
+		"token":          true,
+		"runState":       state,
+		"runThreadState": threadState,
+	}
+
+	for _, rule := range rules {
+		var s scanner.Scanner
+		s.Init(token.NewFileSet().AddFile("", -1, len(rule.Action)), []byte(rule.Action), nil, 0)
+
+		// Identifiers following a period are selectors, which can't refer to the locals.
+		for prev := token.ILLEGAL; ; {
+			_, tok, lit := s.Scan()
+			if tok == token.EOF {
+				break
+			}
+
+			if tok == token.IDENT && prev != token.PERIOD {
+				used[lit] = true
+			}
+			prev = tok
+		}
+	}
+
+	for _, local := range contextLocals {
+		if used[local.name] {
+			fmt.Fprintf(f, "\t\t%s := ctx.%s\n", local.name, local.field)
+		}
+	}
+	fmt.Fprintf(f, "\n")
+}
+
+// emitModes writes a constant for each start condition, which actions can assign to their mode argument to switch to it.
+func emitModes(f io.Writer, modes []lexMode) {
+	fmt.Fprintf(f, "\n\nconst (\n")
+	fmt.Fprintf(f, "\tMode%s gopapageno.LexerMode = iota\n", initialMode)
+	for _, mode := range modes {
+		fmt.Fprintf(f, "\tMode%s\n", mode.Name)
+	}
+	fmt.Fprintf(f, ")\n")
+}
+
+// emitAnchors writes the field of a Lexer holding the anchors of each rule.
+func emitAnchors(f io.Writer, anchors []regex.Anchors) {
+	fmt.Fprintf(f, "\t\tAnchors: []gopapageno.LexerAnchors{")
+	for i, a := range anchors {
+		if i > 0 {
+			fmt.Fprintf(f, ", ")
+		}
+
+		switch a {
+		case regex.LineStart:
+			fmt.Fprintf(f, "gopapageno.AnchorLineStart")
+		case regex.LineEnd:
+			fmt.Fprintf(f, "gopapageno.AnchorLineEnd")
+		case regex.LineStart | regex.LineEnd:
+			fmt.Fprintf(f, "gopapageno.AnchorLineStart | gopapageno.AnchorLineEnd")
+		default:
+			fmt.Fprintf(f, "0")
+		}
+	}
+	fmt.Fprintf(f, "},\n")
+}
+
+// emitAutomata writes a LexerDFA, whose transitions are compressed by grouping bytes into equivalence classes.
 func emitAutomata(f io.Writer, dfa regex.Dfa) {
-	fmt.Fprintf(f, "[]gopapageno.LexerDFAState{\n")
+	fmt.Fprintf(f, "gopapageno.LexerDFA{\n")
+
+	classes, numClasses := dfa.ByteClasses()
+
+	fmt.Fprintf(f, "\t\tClasses: [256]uint8{")
+	for c, class := range classes {
+		if c > 0 {
+			fmt.Fprintf(f, ", ")
+		}
+		fmt.Fprintf(f, "%d", class)
+	}
+	fmt.Fprintf(f, "},\n")
+	fmt.Fprintf(f, "\t\tNumClasses: %d,\n", numClasses)
+
+	// Each class is emitted once, through the first byte belonging to it.
+	representatives := make([]int, numClasses)
+	for c := len(classes) - 1; c >= 0; c-- {
+		representatives[classes[c]] = c
+	}
 
 	states := dfa.GetStates()
+
+	fmt.Fprintf(f, "\t\tTransitions: []int32{\n")
 	for _, state := range states {
-		fmt.Fprintf(f, "\t\t{[256]int{")
-		for i := 0; i < len(state.Transitions)-1; i++ {
-			if state.Transitions[i] == nil {
-				fmt.Fprintf(f, "-1, ")
+		fmt.Fprintf(f, "\t\t\t")
+		for i, c := range representatives {
+			if i > 0 {
+				fmt.Fprintf(f, " ")
+			}
+
+			if state.Transitions[c] == nil {
+				fmt.Fprintf(f, "-1,")
 			} else {
-				fmt.Fprintf(f, "%d, ", state.Transitions[i].Num)
+				fmt.Fprintf(f, "%d,", state.Transitions[c].Num)
 			}
 		}
-		if state.Transitions[len(state.Transitions)-1] == nil {
-			fmt.Fprintf(f, "-1")
-		} else {
-			fmt.Fprintf(f, "%d", state.Transitions[len(state.Transitions)-1].Num)
-		}
+		fmt.Fprintf(f, "\n")
+	}
+	fmt.Fprintf(f, "\t\t},\n")
 
-		fmt.Fprintf(f, "}, %t, []int{", state.IsFinal)
+	fmt.Fprintf(f, "\t\tStates: []gopapageno.LexerDFAState{\n")
+	for _, state := range states {
+		fmt.Fprintf(f, "\t\t\t{%t, []int{", state.IsFinal)
 		sort.Ints(state.AssociatedRules)
 		for i := 0; i < len(state.AssociatedRules)-1; i++ {
 			fmt.Fprintf(f, "%d, ", state.AssociatedRules[i])
@@ -338,5 +690,6 @@ func emitAutomata(f io.Writer, dfa regex.Dfa) {
 		}
 		fmt.Fprintf(f, "}},\n")
 	}
+	fmt.Fprintf(f, "\t\t},\n")
 	fmt.Fprintf(f, "\t}\n\n")
 }
@@ -0,0 +1,188 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+
+	"github.com/giornetta/gopapageno/generator/regex"
+)
+
+// A lexConflictKind classifies the problems found among the rules of a lexer description.
+type lexConflictKind int
+
+const (
+	// lexShadowed rules can never fire, since an earlier rule wins every lexeme they match.
+	lexShadowed lexConflictKind = iota
+	// lexOverlap rules match a lexeme that an earlier rule matches too, which wins it.
+	lexOverlap
+	// lexEmptyMatch rules match the empty string, which never produces a token.
+	lexEmptyMatch
+)
+
+// A lexConflict describes a problem involving a rule of a lexer description.
+type lexConflict struct {
+	kind lexConflictKind
+
+	// rule is the number of the rule the conflict is about.
+	rule int
+	// winner is the number of the rule matching example in its place, or -1 if there is none.
+	winner int
+
+	// mode is the start condition in which the conflict was found.
+	mode string
+	// example is one of the shortest lexemes exhibiting the conflict.
+	example string
+}
+
+// conflicts returns the conflicts among the rules of the lexer, which must have been compiled.
+// When several rules match the longest lexeme, the one defined first wins: overlaps report which one,
+// and rules that never win any lexeme are reported as shadowed.
+func (l *lexerDescriptor) conflicts() []lexConflict {
+	modes, dfas := l.automata()
+
+	conflicts := make([]lexConflict, 0)
+
+	// canWin holds the rules that win at least one lexeme in some start condition,
+	// while beaten holds an example of a lexeme each rule loses.
+	canWin := make([]bool, len(l.rules))
+	beaten := make([]*lexConflict, len(l.rules))
+	matchesEmpty := make([]bool, len(l.rules))
+
+	overlaps := make(map[[2]int]bool)
+
+	for i := range dfas {
+		inputs := dfas[i].ShortestInputs()
+
+		for _, state := range dfas[i].GetStates() {
+			if !state.IsFinal {
+				continue
+			}
+
+			rules := append([]int(nil), state.AssociatedRules...)
+			sort.Ints(rules)
+
+			// The initial state is final for the rules matching the empty string, which the scanner never matches.
+			if state == dfas[i].Initial {
+				for _, rule := range rules {
+					if !matchesEmpty[rule] {
+						matchesEmpty[rule] = true
+						conflicts = append(conflicts, lexConflict{kind: lexEmptyMatch, rule: rule, winner: -1, mode: modes[i].Name})
+					}
+				}
+				continue
+			}
+
+			example := string(inputs[state.Num])
+
+			for j, rule := range rules {
+				// Anchored rules may fail to match, letting the following ones win.
+				if j == 0 || l.allAnchored(rules[:j]) {
+					canWin[rule] = true
+				} else if beaten[rule] == nil {
+					beaten[rule] = &lexConflict{kind: lexShadowed, rule: rule, winner: rules[0], mode: modes[i].Name, example: example}
+				}
+
+				if j > 0 && !overlaps[[2]int{rules[0], rule}] {
+					overlaps[[2]int{rules[0], rule}] = true
+					conflicts = append(conflicts, lexConflict{kind: lexOverlap, rule: rule, winner: rules[0], mode: modes[i].Name, example: example})
+				}
+			}
+		}
+	}
+
+	// Overlaps involving shadowed rules are already explained by reporting them.
+	conflicts = slices.DeleteFunc(conflicts, func(c lexConflict) bool {
+		return c.kind == lexOverlap && !canWin[c.rule]
+	})
+
+	for rule := range l.rules {
+		if canWin[rule] {
+			continue
+		}
+
+		if beaten[rule] != nil {
+			conflicts = append(conflicts, *beaten[rule])
+		} else {
+			// The rule only matches the empty string.
+			conflicts = append(conflicts, lexConflict{kind: lexShadowed, rule: rule, winner: -1})
+		}
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].rule < conflicts[j].rule
+	})
+
+	return conflicts
+}
+
+// allAnchored reports whether every one of the rules is anchored, so that a following rule may win when all of them fail.
+func (l *lexerDescriptor) allAnchored(rules []int) bool {
+	for _, rule := range rules {
+		if l.anchorsOf(rule) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// anchorsOf returns the anchors of a rule.
+func (l *lexerDescriptor) anchorsOf(rule int) regex.Anchors {
+	if l.anchors == nil {
+		return 0
+	}
+
+	return l.anchors[rule]
+}
+
+// describe returns a message explaining the conflict, referring to the rules of l.
+func (c lexConflict) describe(l *lexerDescriptor) string {
+	where := ""
+	if len(l.modes) > 0 && c.mode != "" {
+		where = fmt.Sprintf(" in start condition %s", c.mode)
+	}
+
+	rule := l.describeRule
+
+	switch c.kind {
+	case lexShadowed:
+		if c.winner < 0 {
+			return fmt.Sprintf("%s can never match, since it only matches the empty string", rule(c.rule))
+		}
+		return fmt.Sprintf("%s can never match: earlier rules win every lexeme it matches, such as %q%s, which %s matches",
+			rule(c.rule), c.example, where, rule(c.winner))
+	case lexOverlap:
+		return fmt.Sprintf("%s and %s both match %q%s: the first one wins", rule(c.winner), rule(c.rule), c.example, where)
+	case lexEmptyMatch:
+		return fmt.Sprintf("%s matches the empty string%s, which never produces a token", rule(c.rule), where)
+	default:
+		return fmt.Sprintf("%s conflicts with other rules", rule(c.rule))
+	}
+}
+
+// checkConflicts reports the conflicts among the rules of the lexer, which must have been compiled.
+// Shadowed rules are errors, rules matching the empty string are warnings, and overlaps are only logged.
+func (l *lexerDescriptor) checkConflicts(logger *log.Logger) error {
+	var errs []error
+
+	for _, c := range l.conflicts() {
+		switch c.kind {
+		case lexShadowed:
+			errs = append(errs, errors.New(c.describe(l)))
+		case lexEmptyMatch:
+			logger.Printf("Warning: %s.\n", c.describe(l))
+		default:
+			logger.Printf("%s.\n", c.describe(l))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// describeRule returns a reference to a rule for messages.
+func (l *lexerDescriptor) describeRule(n int) string {
+	return fmt.Sprintf("rule %d (%s)", n, l.rules[n].Regex)
+}
@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"strings"
+	"testing"
+)
+
+// compileLexer parses and compiles the lexer description src.
+func compileLexer(t *testing.T, src string) *lexerDescriptor {
+	t.Helper()
+
+	l, err := parseLexerDescription(strings.NewReader(src), log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("could not parse lexer description: %v", err)
+	}
+
+	if err := l.compile(); err != nil {
+		t.Fatalf("could not compile lexer: %v", err)
+	}
+
+	return l
+}
+
+const conflictingLexer = `%%
+
+%%
+
+if
+{
+    token.Type = IF
+}
+[a-z]+
+{
+    token.Type = IDENT
+}
+i[a-z]
+{
+    token.Type = SHORT
+}
+[0-9]*
+{
+    token.Type = NUMBER
+}
+`
+
+func TestLexerConflicts(t *testing.T) {
+	l := compileLexer(t, conflictingLexer)
+
+	expected := []lexConflict{
+		{kind: lexOverlap, rule: 1, winner: 0, example: "if"},
+		{kind: lexShadowed, rule: 2, winner: 1},
+		{kind: lexEmptyMatch, rule: 3, winner: -1},
+	}
+
+	conflicts := l.conflicts()
+	if len(conflicts) != len(expected) {
+		t.Fatalf("expected %d conflicts, got %v", len(expected), conflicts)
+	}
+
+	for i, c := range conflicts {
+		e := expected[i]
+		if c.kind != e.kind || c.rule != e.rule || c.winner != e.winner {
+			t.Errorf("conflict %d: expected kind %d for rule %d won by %d, got %+v", i, e.kind, e.rule, e.winner, c)
+		}
+
+		if e.example != "" && c.example != e.example {
+			t.Errorf("conflict %d: expected example %q, got %q", i, e.example, c.example)
+		}
+	}
+
+	// The shadowed rule loses a two-letter lexeme beginning with i.
+	if c := conflicts[1]; len(c.example) != 2 || c.example[0] != 'i' {
+		t.Errorf("expected the shadowed rule to lose a lexeme such as \"ia\", got %q", c.example)
+	}
+}
+
+func TestLexerConflictsAnchored(t *testing.T) {
+	l := compileLexer(t, `%%
+
+%%
+
+^[a-z]+
+{
+    token.Type = FIRST
+}
+[a-z]+
+{
+    token.Type = IDENT
+}
+`)
+
+	for _, c := range l.conflicts() {
+		if c.kind == lexShadowed {
+			t.Errorf("expected rules following anchored ones not to be shadowed, got %+v", c)
+		}
+	}
+}
+
+func TestCheckConflicts(t *testing.T) {
+	l := compileLexer(t, conflictingLexer)
+
+	var buf bytes.Buffer
+
+	err := l.checkConflicts(log.New(&buf, "", 0))
+	if err == nil || !strings.Contains(err.Error(), "rule 2 (i[a-z]) can never match") {
+		t.Errorf("expected the shadowed rule to be an error, got %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Warning: rule 3 ([0-9]*) matches the empty string") {
+		t.Errorf("expected the rule matching the empty string to be logged as a warning, got %q", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), `rule 0 (if) and rule 1 ([a-z]+) both match "if"`) {
+		t.Errorf("expected the overlap to be logged, got %q", buf.String())
+	}
+}
@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/giornetta/gopapageno/generator/regex"
+)
+
+// maxExplainedRules is the number of rules listed when explaining which lexemes a byte can occur in.
+const maxExplainedRules = 3
+
+// automata returns the start conditions of the lexer, the initial one first, along with their automata.
+// The lexer must have been compiled.
+func (l *lexerDescriptor) automata() ([]lexMode, []regex.Dfa) {
+	modes := append([]lexMode{{Name: initialMode}}, l.modes...)
+	dfas := append([]regex.Dfa{l.dfa}, l.modeDfas...)
+
+	return modes, dfas
+}
+
+// inferCutPoints returns the bytes before which sources can be cut safely: they begin a token in the initial start
+// condition, and no token of any start condition contains them past its first byte, so they always begin one.
+// It reports false if there is none.
+func (l *lexerDescriptor) inferCutPoints() ([256]bool, bool) {
+	cuts := l.dfa.StartBytes()
+	found := false
+
+	for c := range cuts {
+		if !cuts[c] {
+			continue
+		}
+
+		if _, _, _, ok := l.continuation(byte(c)); ok {
+			cuts[c] = false
+			continue
+		}
+
+		found = true
+	}
+
+	return cuts, found
+}
+
+// continuation returns one of the shortest lexemes containing c past its first byte, the rules it may be a prefix of
+// and the start condition it is read in. It returns false if c can only begin lexemes.
+func (l *lexerDescriptor) continuation(c byte) ([]byte, []int, string, bool) {
+	modes, dfas := l.automata()
+
+	for i := range dfas {
+		if example, state, ok := dfas[i].Continuation(c); ok {
+			return example, dfas[i].ReachableRules(state), modes[i].Name, true
+		}
+	}
+
+	return nil, nil, "", false
+}
+
+// explainCuts describes the cut points of the lexer, and why sources can be cut there safely or not.
+// The lexer must have been compiled.
+func (l *lexerDescriptor) explainCuts(w io.Writer) {
+	if l.cutPoints != "" {
+		fmt.Fprintf(w, "Cut points are the matches of %s, given by the %%cut directive. They begin with:\n", l.cutPoints)
+
+		for c, starts := range l.cutPointsDfa.StartBytes() {
+			if starts {
+				l.explainCut(w, byte(c))
+			}
+		}
+		return
+	}
+
+	cuts, found := l.inferCutPoints()
+	if !found {
+		fmt.Fprintf(w, "No %%cut directive was given, and no byte always begins a token: sources are cut anywhere, "+
+			"and portions cut within a token are lexed again.\n")
+		return
+	}
+
+	fmt.Fprintf(w, "No %%cut directive was given: sources are cut before these bytes, which always begin a token:\n")
+	for c, isCut := range cuts {
+		if isCut {
+			l.explainCut(w, byte(c))
+		}
+	}
+}
+
+// explainCut describes whether cutting a source before c is safe.
+func (l *lexerDescriptor) explainCut(w io.Writer, c byte) {
+	if example, rules, mode, ok := l.continuation(c); ok {
+		where := ""
+		if len(l.modes) > 0 {
+			where = fmt.Sprintf(" in start condition %s", mode)
+		}
+
+		fmt.Fprintf(w, "  %s is unsafe: it can occur within a lexeme of %s, as in one beginning with %q%s. Portions cut there are lexed again.\n",
+			quoteByte(c), l.describeRules(rules), example, where)
+		return
+	}
+
+	next := l.dfa.Initial.Transitions[c]
+	if next == nil {
+		fmt.Fprintf(w, "  %s doesn't occur in valid sources: no lexeme contains it.\n", quoteByte(c))
+		return
+	}
+
+	fmt.Fprintf(w, "  %s is safe: it begins lexemes of %s, and no lexeme contains it past its first byte.\n",
+		quoteByte(c), l.describeRules(l.dfa.ReachableRules(next)))
+}
+
+// quoteByte formats c for messages: ASCII characters are quoted, while other bytes, which are only part of
+// the encoding of a character, are written in hexadecimal.
+func quoteByte(c byte) string {
+	if c < utf8.RuneSelf {
+		return fmt.Sprintf("%q", c)
+	}
+
+	return fmt.Sprintf("0x%02X", c)
+}
+
+// describeRules returns a reference to the rules for messages, listing at most maxExplainedRules of them.
+func (l *lexerDescriptor) describeRules(rules []int) string {
+	descriptions := make([]string, 0, maxExplainedRules+1)
+	for i, rule := range rules {
+		if i == maxExplainedRules {
+			descriptions = append(descriptions, fmt.Sprintf("%d more", len(rules)-i))
+			break
+		}
+
+		descriptions = append(descriptions, l.describeRule(rule))
+	}
+
+	return strings.Join(descriptions, ", ")
+}
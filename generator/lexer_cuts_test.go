@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const cutsLexer = `%%
+
+%%
+
+;
+{
+    token.Type = SEMICOLON
+}
+\+
+{
+    token.Type = PLUS
+}
+[0-9]+
+{
+    token.Type = NUMBER
+}
+é
+{
+    token.Type = ACCENT
+}
+[ \t]+
+{
+    return gopapageno.LexSkip
+}
+"[^"]*"
+{
+    token.Type = STRING
+}
+`
+
+func TestInferCutPoints(t *testing.T) {
+	l := compileLexer(t, cutsLexer)
+
+	// Every byte may occur within strings, so none can be a cut point, even though some begin tokens.
+	if cuts, found := l.inferCutPoints(); found {
+		t.Errorf("expected no cut point to be found, got %v", cuts)
+	}
+
+	l = compileLexer(t, strings.ReplaceAll(cutsLexer, `"[^"]*"`, `"[a-z]*"`))
+
+	cuts, found := l.inferCutPoints()
+	if !found {
+		t.Fatalf("expected cut points to be found")
+	}
+
+	expected := map[byte]bool{';': true, '+': true, 0xC3: true}
+	for c := range cuts {
+		if cuts[c] != expected[byte(c)] {
+			t.Errorf("expected %s to be a cut point: %v, got %v", quoteByte(byte(c)), expected[byte(c)], cuts[c])
+		}
+	}
+}
+
+func TestExplainCuts(t *testing.T) {
+	l := compileLexer(t, strings.ReplaceAll(cutsLexer, `"[^"]*"`, `"[a-z]*"`))
+
+	var buf bytes.Buffer
+	l.explainCuts(&buf)
+
+	out := buf.String()
+	for _, line := range []string{
+		`';' is safe: it begins lexemes of rule 0 (;)`,
+		`0xC3 is safe: it begins lexemes of rule 3 (é)`,
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("expected explanation to contain %q, got:\n%s", line, out)
+		}
+	}
+
+	if strings.Contains(out, "Ã") {
+		t.Errorf("expected bytes that aren't ASCII characters to be written in hexadecimal, got:\n%s", out)
+	}
+
+	buf.Reset()
+	l.explainCut(&buf, '5')
+
+	if !strings.Contains(buf.String(), `'5' is unsafe: it can occur within a lexeme of rule 2 ([0-9]+)`) {
+		t.Errorf("expected '5' to be unsafe, got %q", buf.String())
+	}
+
+	buf.Reset()
+	l.explainCut(&buf, 0xA9)
+
+	if !strings.Contains(buf.String(), `0xA9 is unsafe: it can occur within a lexeme of rule 3 (é)`) {
+		t.Errorf("expected 0xA9 to be unsafe, got %q", buf.String())
+	}
+
+	buf.Reset()
+	l.explainCut(&buf, 0xFF)
+
+	if !strings.Contains(buf.String(), "0xFF doesn't occur in valid sources") {
+		t.Errorf("expected 0xFF not to occur in valid sources, got %q", buf.String())
+	}
+}
+
+func TestQuoteByte(t *testing.T) {
+	tests := map[byte]string{
+		';':  `';'`,
+		'\n': `'\n'`,
+		0x7F: `'\x7f'`,
+		0x80: "0x80",
+		0xC3: "0xC3",
+	}
+
+	for c, expected := range tests {
+		if got := quoteByte(c); got != expected {
+			t.Errorf("quoteByte(%d) = %s, expected %s", c, got, expected)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmitContextLocals(t *testing.T) {
+	tests := []struct {
+		actions     []string
+		state       bool
+		threadState bool
+		expected    []string
+	}{
+		{[]string{"{\n\ttoken.Type = NUMBER\n}"}, false, false, []string{"token"}},
+		{[]string{"{\n\ttoken.Value = text[1:]\n}", "{\n\t*mode = ModeCOMMENT\n}"}, false, false, []string{"text", "token", "mode"}},
+		{[]string{"{\n\tstate.start = end\n}"}, true, false, []string{"end", "token", "runState"}},
+		{[]string{"{\n\t// prev is left alone\n\tthreadState.Count++\n}"}, false, true, []string{"token", "runThreadState"}},
+	}
+
+	for _, tt := range tests {
+		rules := make([]lexRule, len(tt.actions))
+		for i, action := range tt.actions {
+			rules[i].Action = action
+		}
+
+		var sb strings.Builder
+		emitContextLocals(&sb, rules, tt.state, tt.threadState)
+
+		var got []string
+		for _, line := range strings.Split(strings.TrimSpace(sb.String()), "\n") {
+			got = append(got, strings.Fields(line)[0])
+		}
+
+		if strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+			t.Errorf("%q: expected locals %v, got:\n%s", tt.actions, tt.expected, sb.String())
+		}
+	}
+}
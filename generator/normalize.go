@@ -0,0 +1,563 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxNormalizedRules bounds the rules created while separating adjacent nonterminals,
+	// since substituting them can make grammars grow exponentially.
+	maxNormalizedRules = 10000
+
+	// maxNullableSymbols bounds the nullable symbols in a right-hand side, since a rule is created for each subset of them.
+	maxNullableSymbols = 12
+)
+
+// valueRefRegexp matches the references to semantic values in actions: $$ or $n, optionally followed by .Value.
+var valueRefRegexp = regexp.MustCompile(`\$(\$|[0-9]+)(\.Value\b)?`)
+
+// A normalizer rewrites a grammar into operator form, where no rule has an empty right-hand side
+// and no two nonterminals are adjacent.
+//
+// Both are achieved by substituting a nonterminal in a right-hand side with the right-hand side of one of its rules:
+// the semantic value the nonterminal would have is computed in the action of the new rule,
+// by running the action of the substituted rule into a local variable.
+type normalizer struct {
+	p      *grammarDescription
+	logger *log.Logger
+
+	// inlined counts the rules substituted so far, naming the variables holding their values.
+	inlined int
+
+	// unconverted holds the productions that couldn't be rewritten, along with the reason.
+	unconverted []error
+}
+
+// A normalizedRule is a rule created while normalizing, along with the production of the grammar it comes from.
+type normalizedRule struct {
+	rule   ruleDescription
+	origin ruleDescription
+
+	// substitutions counts how many times each nonterminal was substituted to separate adjacent nonterminals,
+	// which can't exceed its occurrences in origin: more would mean that substituting it never ends.
+	substitutions map[string]int
+}
+
+// normalize rewrites the rules of the grammar into operator form, whose tokens must have been inferred.
+// It reports every production that couldn't be converted.
+func (p *grammarDescription) normalize(logger *log.Logger) error {
+	n := &normalizer{p: p, logger: logger}
+
+	rules := make([]normalizedRule, len(p.rules))
+	for i, rule := range p.rules {
+		rules[i] = normalizedRule{rule: rule, origin: rule}
+	}
+
+	rules = n.eliminateEmptyRules(rules)
+	rules = n.separateNonterminals(rules)
+	rules = n.deleteUnreachableRules(rules)
+
+	if len(n.unconverted) > 0 {
+		return errors.Join(n.unconverted...)
+	}
+
+	p.rules = make([]ruleDescription, len(rules))
+	for i, r := range rules {
+		p.rules[i] = r.rule
+	}
+
+	p.inferTokens()
+
+	if !p.isAxiomUsed() {
+		return fmt.Errorf("axiom %s only derives the empty string", p.axiom)
+	}
+
+	return nil
+}
+
+// fail records that the production origin couldn't be converted.
+func (n *normalizer) fail(origin ruleDescription, format string, args ...any) {
+	err := fmt.Errorf("production %s can't be converted to operator form: %s", origin, fmt.Sprintf(format, args...))
+
+	for _, other := range n.unconverted {
+		if other.Error() == err.Error() {
+			return
+		}
+	}
+
+	n.unconverted = append(n.unconverted, err)
+}
+
+// eliminateEmptyRules deletes the rules with an empty right-hand side. Every rule is replaced by the ones
+// omitting any subset of its nullable symbols, which derive the empty string, computing their values in its action.
+// Since parsers delete the actions of renaming rules, whose right-hand side is a single nonterminal,
+// the renaming rules left by omitting symbols have that nonterminal substituted with the right-hand sides of its rules.
+func (n *normalizer) eliminateEmptyRules(rules []normalizedRule) []normalizedRule {
+	empty := n.emptyDerivations(rules)
+	if len(empty) == 0 {
+		return rules
+	}
+
+	if _, ok := empty[n.p.axiom]; ok {
+		n.logger.Printf("Warning: axiom %s derives the empty string, which generated parsers won't accept.\n", n.p.axiom)
+	}
+
+	result := make([]normalizedRule, 0, len(rules))
+	seen := make(map[string]bool)
+
+	// renamings holds the renaming rules left by omitting symbols.
+	renamings := make([]normalizedRule, 0)
+
+	for _, r := range rules {
+		if len(r.rule.RHS) == 0 {
+			n.logger.Printf("Deleted empty rule %s.\n", r.rule)
+			continue
+		}
+
+		nullable := make([]int, 0)
+		for i, token := range r.rule.RHS {
+			if _, ok := empty[token]; ok {
+				nullable = append(nullable, i)
+			}
+		}
+
+		if len(nullable) > maxNullableSymbols {
+			n.fail(r.origin, "it has more than %d nullable symbols", maxNullableSymbols)
+			continue
+		}
+
+		for omitted := 0; omitted < 1<<len(nullable); omitted++ {
+			rule := r.rule
+
+			// Symbols are omitted from the last one, so that the positions of the others don't change.
+			var err error
+			for i := len(nullable) - 1; i >= 0 && err == nil; i-- {
+				if omitted&(1<<i) == 0 {
+					continue
+				}
+
+				if e := empty[rule.RHS[nullable[i]]]; e.err != nil {
+					err = e.err
+				} else {
+					rule, err = n.inline(rule, nullable[i], e.rule)
+				}
+			}
+
+			if err != nil {
+				n.fail(r.origin, "%v", err)
+				continue
+			}
+
+			// Rules deriving nothing, or only their left-hand side, are useless.
+			if len(rule.RHS) == 0 || (len(rule.RHS) == 1 && rule.RHS[0] == rule.LHS) {
+				continue
+			}
+
+			if seen[rule.String()] {
+				n.logger.Printf("Rule %s is derived more than once while deleting empty rules: only the first one is kept.\n", rule)
+				continue
+			}
+			seen[rule.String()] = true
+
+			if omitted != 0 && n.isRenaming(rule) {
+				renamings = append(renamings, normalizedRule{rule: rule, origin: r.origin})
+				continue
+			}
+
+			result = append(result, normalizedRule{rule: rule, origin: r.origin})
+		}
+	}
+
+	return n.substituteRenamings(result, renamings)
+}
+
+// isRenaming reports whether the right-hand side of rule is a single nonterminal.
+func (n *normalizer) isRenaming(rule ruleDescription) bool {
+	return len(rule.RHS) == 1 && n.p.nonterminals.Contains(rule.RHS[0])
+}
+
+// substituteRenamings adds to rules the ones obtained by substituting the nonterminal of each renaming rule
+// with the right-hand sides of its rules, until none of them is a renaming rule.
+func (n *normalizer) substituteRenamings(rules []normalizedRule, renamings []normalizedRule) []normalizedRule {
+	if len(renamings) == 0 {
+		return rules
+	}
+
+	byLHS := make(map[string][]ruleDescription)
+	for _, r := range slices.Concat(rules, renamings) {
+		byLHS[r.rule.LHS] = append(byLHS[r.rule.LHS], r.rule)
+	}
+
+	type renaming struct {
+		r normalizedRule
+
+		// renamed holds the nonterminals the rule was obtained through, to detect cycles.
+		renamed []string
+	}
+
+	queue := make([]renaming, len(renamings))
+	for i, r := range renamings {
+		queue[i] = renaming{r: r, renamed: []string{r.rule.LHS}}
+	}
+
+	seen := make(map[string]bool)
+	for _, r := range rules {
+		seen[r.rule.String()] = true
+	}
+
+	for len(queue) > 0 {
+		q := queue[0]
+		queue = queue[1:]
+
+		substituted := q.r.rule.RHS[0]
+		if slices.Contains(q.renamed, substituted) {
+			n.fail(q.r.origin, "deleting empty rules leaves renaming rule %s, but substituting %s never ends", q.r.rule, substituted)
+			continue
+		}
+
+		n.logger.Printf("Substituted %s in renaming rule %s left by deleting empty rules, whose action would be deleted.\n", substituted, q.r.rule)
+
+		for _, sub := range byLHS[substituted] {
+			rule, err := n.inline(q.r.rule, 0, sub)
+			if err != nil {
+				n.fail(q.r.origin, "%v", err)
+				continue
+			}
+
+			if n.isRenaming(rule) {
+				queue = append(queue, renaming{r: normalizedRule{rule: rule, origin: q.r.origin}, renamed: append(slices.Clone(q.renamed), substituted)})
+				continue
+			}
+
+			if seen[rule.String()] {
+				n.logger.Printf("Rule %s is derived more than once while deleting empty rules: only the first one is kept.\n", rule)
+				continue
+			}
+			seen[rule.String()] = true
+
+			rules = append(rules, normalizedRule{rule: rule, origin: q.r.origin})
+		}
+
+		if len(queue)+len(rules) > maxNormalizedRules {
+			n.fail(q.r.origin, "substituting renaming rules creates more than %d rules", maxNormalizedRules)
+			return rules
+		}
+	}
+
+	return rules
+}
+
+// An emptyDerivation is a rule with an empty right-hand side, whose action computes the value of a nullable
+// nonterminal when it derives the empty string. err is set if that value can't be computed.
+type emptyDerivation struct {
+	rule ruleDescription
+	err  error
+}
+
+// emptyDerivations finds the nonterminals deriving the empty string, and how.
+func (n *normalizer) emptyDerivations(rules []normalizedRule) map[string]emptyDerivation {
+	empty := make(map[string]emptyDerivation)
+
+	for modified := true; modified; {
+		modified = false
+
+		for _, r := range rules {
+			if _, ok := empty[r.rule.LHS]; ok {
+				continue
+			}
+
+			derivesEmpty := true
+			for _, token := range r.rule.RHS {
+				if _, ok := empty[token]; !ok {
+					derivesEmpty = false
+					break
+				}
+			}
+			if !derivesEmpty {
+				continue
+			}
+
+			e := emptyDerivation{rule: r.rule}
+			for i := len(r.rule.RHS) - 1; i >= 0 && e.err == nil; i-- {
+				if other := empty[r.rule.RHS[i]]; other.err != nil {
+					e.err = other.err
+				} else {
+					e.rule, e.err = n.inline(e.rule, i, other.rule)
+				}
+			}
+
+			empty[r.rule.LHS] = e
+			modified = true
+		}
+	}
+
+	return empty
+}
+
+// separateNonterminals substitutes adjacent nonterminals with the right-hand sides of their rules,
+// until none is left. The right one is substituted unless it's left-recursive, which would never end,
+// otherwise the left one is, unless it's right-recursive too.
+func (n *normalizer) separateNonterminals(rules []normalizedRule) []normalizedRule {
+	byLHS := make(map[string][]ruleDescription)
+	for _, r := range rules {
+		byLHS[r.rule.LHS] = append(byLHS[r.rule.LHS], r.rule)
+	}
+
+	leftRecursive := n.recursiveNonterminals(rules, func(rhs []string) string { return rhs[0] })
+	rightRecursive := n.recursiveNonterminals(rules, func(rhs []string) string { return rhs[len(rhs)-1] })
+
+	result := make([]normalizedRule, 0, len(rules))
+
+	queue := slices.Clone(rules)
+	for len(queue) > 0 {
+		r := queue[0]
+		queue = queue[1:]
+
+		i := n.adjacentNonterminals(r.rule.RHS)
+		if i < 0 {
+			result = append(result, r)
+			continue
+		}
+
+		x, y := r.rule.RHS[i], r.rule.RHS[i+1]
+
+		pos, substituted := i+1, y
+		switch {
+		case !leftRecursive[y]:
+		case !rightRecursive[x]:
+			pos, substituted = i, x
+		default:
+			n.fail(r.origin, "nonterminals %s and %s are adjacent, but %s is left-recursive and %s is right-recursive, so neither can be substituted", x, y, y, x)
+			continue
+		}
+
+		if r.substitutions[substituted] >= max(1, countTokens(r.origin.RHS, substituted)) {
+			if pos == i {
+				n.fail(r.origin, "nonterminals %s and %s are adjacent, but %s is left-recursive and substituting %s never ends", x, y, y, x)
+			} else {
+				n.fail(r.origin, "nonterminals %s and %s are adjacent, but substituting %s never ends", x, y, y)
+			}
+			continue
+		}
+
+		substitutions := maps.Clone(r.substitutions)
+		if substitutions == nil {
+			substitutions = make(map[string]int)
+		}
+		substitutions[substituted]++
+
+		for _, sub := range byLHS[substituted] {
+			rule, err := n.inline(r.rule, pos, sub)
+			if err != nil {
+				n.fail(r.origin, "%v", err)
+				continue
+			}
+
+			queue = append(queue, normalizedRule{rule: rule, origin: r.origin, substitutions: substitutions})
+		}
+
+		if len(queue)+len(result) > maxNormalizedRules {
+			n.fail(r.origin, "separating its nonterminals creates more than %d rules", maxNormalizedRules)
+			return result
+		}
+	}
+
+	return result
+}
+
+// adjacentNonterminals returns the position of the first of two adjacent nonterminals in rhs, or -1 if there are none.
+func (n *normalizer) adjacentNonterminals(rhs []string) int {
+	for i := 0; i < len(rhs)-1; i++ {
+		if n.p.nonterminals.Contains(rhs[i]) && n.p.nonterminals.Contains(rhs[i+1]) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// countTokens returns the occurrences of token in rhs.
+func countTokens(rhs []string, token string) int {
+	count := 0
+	for _, t := range rhs {
+		if t == token {
+			count++
+		}
+	}
+
+	return count
+}
+
+// recursiveNonterminals returns the nonterminals deriving a string beginning, or ending, with themselves,
+// depending on whether edge returns the first or the last symbol of a right-hand side.
+func (n *normalizer) recursiveNonterminals(rules []normalizedRule, edge func([]string) string) map[string]bool {
+	derived := make(map[string]*set[string])
+	for _, r := range rules {
+		if derived[r.rule.LHS] == nil {
+			derived[r.rule.LHS] = newSet[string]()
+		}
+
+		if token := edge(r.rule.RHS); n.p.nonterminals.Contains(token) {
+			derived[r.rule.LHS].Add(token)
+		}
+	}
+
+	for modified := true; modified; {
+		modified = false
+
+		for _, tokens := range derived {
+			for _, token := range tokens.Copy().Iter {
+				if derived[token] == nil {
+					continue
+				}
+
+				for _, other := range derived[token].Iter {
+					if !tokens.Contains(other) {
+						tokens.Add(other)
+						modified = true
+					}
+				}
+			}
+		}
+	}
+
+	recursive := make(map[string]bool)
+	for nonterminal, tokens := range derived {
+		recursive[nonterminal] = tokens.Contains(nonterminal)
+	}
+
+	return recursive
+}
+
+// deleteUnreachableRules deletes the rules of the nonterminals the axiom doesn't derive,
+// which substitutions may have left unused.
+func (n *normalizer) deleteUnreachableRules(rules []normalizedRule) []normalizedRule {
+	reachable := newSet[string]()
+	reachable.Add(n.p.axiom)
+
+	for modified := true; modified; {
+		modified = false
+
+		for _, r := range rules {
+			if !reachable.Contains(r.rule.LHS) {
+				continue
+			}
+
+			for _, token := range r.rule.RHS {
+				if n.p.nonterminals.Contains(token) && !reachable.Contains(token) {
+					reachable.Add(token)
+					modified = true
+				}
+			}
+		}
+	}
+
+	return slices.DeleteFunc(rules, func(r normalizedRule) bool {
+		if reachable.Contains(r.rule.LHS) {
+			return false
+		}
+
+		n.logger.Printf("Deleted rule %s, since %s is no longer used.\n", r.rule, r.rule.LHS)
+		return true
+	})
+}
+
+// inline substitutes the symbol at position k of the right-hand side of rule with the right-hand side of sub,
+// one of its rules. The action of sub stores the value of the symbol in a local variable, which the action of rule
+// reads in its place: both actions must only refer to the value of that symbol through $$.Value and $n.Value.
+func (n *normalizer) inline(rule ruleDescription, k int, sub ruleDescription) (ruleDescription, error) {
+	n.inlined++
+	v := fmt.Sprintf("inlined%s%d", sub.LHS, n.inlined)
+
+	subAction, ok := rewriteValueRefs(sub.Action, func(pos int, value bool) (string, bool) {
+		if pos == 0 {
+			return v, value
+		}
+		return valueRef(k+pos, value), true
+	})
+	if !ok {
+		return ruleDescription{}, fmt.Errorf("the action of rule %s refers to $$ other than through $$.Value", sub)
+	}
+
+	action, ok := rewriteValueRefs(rule.Action, func(pos int, value bool) (string, bool) {
+		switch {
+		case pos <= k:
+			return valueRef(pos, value), true
+		case pos == k+1:
+			return v, value
+		default:
+			return valueRef(pos+len(sub.RHS)-1, value), true
+		}
+	})
+	if !ok {
+		return ruleDescription{}, fmt.Errorf("the action of rule %s refers to $%d other than through $%d.Value", rule, k+1, k+1)
+	}
+
+	typ := "any"
+	if t, ok := n.p.types[sub.LHS]; ok {
+		typ = t
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "{\n\tvar %s %s\n", v, typ)
+	fmt.Fprintf(&sb, "%s\n", indentAction(subAction))
+	fmt.Fprintf(&sb, "\t_ = %s\n\n", v)
+	fmt.Fprintf(&sb, "%s\n}", indentAction(action))
+
+	return ruleDescription{
+		LHS:    rule.LHS,
+		RHS:    slices.Concat(rule.RHS[:k], sub.RHS, rule.RHS[k+1:]),
+		Action: sb.String(),
+		Flags:  rule.Flags,
+	}, nil
+}
+
+// rewriteValueRefs replaces the references to semantic values in action with the result of replace,
+// which receives the position they refer to, 0 for $$, and whether they are followed by .Value.
+// It reports false if replace does so for any of them.
+func rewriteValueRefs(action string, replace func(pos int, value bool) (string, bool)) (string, bool) {
+	ok := true
+
+	rewritten := valueRefRegexp.ReplaceAllStringFunc(action, func(ref string) string {
+		match := valueRefRegexp.FindStringSubmatch(ref)
+
+		pos := 0
+		if match[1] != "$" {
+			pos, _ = strconv.Atoi(match[1])
+		}
+
+		s, replaced := replace(pos, match[2] != "")
+		ok = ok && replaced
+
+		return s
+	})
+
+	return rewritten, ok
+}
+
+// valueRef returns a reference to the token at position pos of a rule, 0 being its left-hand side,
+// or to its value.
+func valueRef(pos int, value bool) string {
+	ref := "$$"
+	if pos > 0 {
+		ref = "$" + strconv.Itoa(pos)
+	}
+
+	if value {
+		ref += ".Value"
+	}
+
+	return ref
+}
+
+// indentAction indents every line of action by a tab.
+func indentAction(action string) string {
+	return "\t" + strings.ReplaceAll(action, "\n", "\n\t")
+}
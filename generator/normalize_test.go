@@ -0,0 +1,279 @@
+package generator
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/giornetta/gopapageno"
+)
+
+// normalizeGrammar parses the grammar description src and normalizes it, logging to logger.
+func normalizeGrammar(t *testing.T, src string, logger *log.Logger) (*grammarDescription, error) {
+	t.Helper()
+
+	p, err := parseGrammarDescription(strings.NewReader(src), &Options{Strategy: gopapageno.OPP, Logger: logger})
+	if err != nil {
+		t.Fatalf("could not parse grammar description: %v", err)
+	}
+
+	p.inferTokens()
+
+	return p, p.normalize(logger)
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		rules []string
+		err   string
+	}{
+		{
+			name: "adjacent",
+			src: `%axiom S
+
+%%
+
+S : A B
+{
+	$$.Value = $2.Value
+};
+
+A : NUMBER
+{
+};
+
+B : PLUS NUMBER
+{
+	$$.Value = $2.Value
+};
+
+%%`,
+			rules: []string{"A -> NUMBER", "S -> A PLUS NUMBER"},
+		},
+		{
+			name: "empty",
+			src: `%axiom S
+
+%%
+
+S : A PLUS NUMBER
+{
+	$$.Value = $1.Value
+};
+
+A : NUMBER
+{
+	$$.Value = $1.Value
+} |
+{
+	$$.Value = 100
+};
+
+%%`,
+			rules: []string{"S -> A PLUS NUMBER", "S -> PLUS NUMBER", "A -> NUMBER"},
+		},
+		{
+			name: "renaming",
+			src: `%axiom S
+
+%%
+
+S : A B
+{
+	$$.Value = $1.Value
+};
+
+A : NUMBER
+{
+	$$.Value = $1.Value
+} |
+{
+	$$.Value = 100
+};
+
+B : PLUS NUMBER
+{
+};
+
+%%`,
+			rules: []string{"A -> NUMBER", "S -> PLUS NUMBER", "S -> A PLUS NUMBER"},
+		},
+		{
+			name: "renaming cycle",
+			src: `%axiom S
+
+%%
+
+S : A B
+{
+};
+
+A : B
+{
+} |
+{
+};
+
+B : A
+{
+} | NUMBER
+{
+};
+
+%%`,
+			err: "production S -> A B can't be converted to operator form: deleting empty rules leaves renaming rule S -> B, but substituting B never ends",
+		},
+		{
+			name: "recursive",
+			src: `%axiom S
+
+%%
+
+S : A B
+{
+};
+
+A : NUMBER A
+{
+} | NUMBER
+{
+};
+
+B : B PLUS
+{
+} | PLUS
+{
+};
+
+%%`,
+			err: "nonterminals A and B are adjacent, but B is left-recursive and A is right-recursive, so neither can be substituted",
+		},
+		{
+			name: "value reference",
+			src: `%axiom S
+
+%%
+
+S : A PLUS
+{
+	$$.Value = $1
+};
+
+A : NUMBER
+{
+} |
+{
+};
+
+%%`,
+			err: "the action of rule S -> A PLUS refers to $1 other than through $1.Value",
+		},
+		{
+			name: "empty axiom",
+			src: `%axiom S
+
+%%
+
+S :
+{
+};
+
+%%`,
+			err: "axiom S only derives the empty string",
+		},
+	}
+
+	for _, tt := range tests {
+		p, err := normalizeGrammar(t, tt.src, log.New(io.Discard, "", 0))
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: expected error %q, got %v", tt.name, tt.err, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+
+		rules := make([]string, len(p.rules))
+		for i, rule := range p.rules {
+			rules[i] = rule.String()
+		}
+
+		if !slices.Equal(rules, tt.rules) {
+			t.Errorf("%s: expected rules %v, got %v", tt.name, tt.rules, rules)
+		}
+	}
+}
+
+func TestNormalizeKeepsRenamedActions(t *testing.T) {
+	p, err := normalizeGrammar(t, `%axiom S
+%type S int64
+%type A int64
+%type B int64
+%type NUMBER int64
+
+%%
+
+S : A B
+{
+	$$.Value = $1.Value + $2.Value
+};
+
+A : NUMBER
+{
+	$$.Value = $1.Value
+} |
+{
+	$$.Value = 100
+};
+
+B : PLUS NUMBER
+{
+	$$.Value = $2.Value
+};
+
+%%`, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	i := slices.IndexFunc(p.rules, func(rule ruleDescription) bool { return rule.String() == "S -> PLUS NUMBER" })
+	if i < 0 {
+		t.Fatalf("expected renaming rule S -> B to be substituted with S -> PLUS NUMBER, got %v", p.rules)
+	}
+
+	action := p.rules[i].Action
+	for _, s := range []string{"= 100", "= $2.Value", "$$.Value = inlinedA1 + inlinedB2"} {
+		if !strings.Contains(action, s) {
+			t.Errorf("expected the action of S -> PLUS NUMBER to contain %q, got:\n%s", s, action)
+		}
+	}
+}
+
+func TestNormalizeLogsEmptyAxiom(t *testing.T) {
+	var buf bytes.Buffer
+
+	_, _ = normalizeGrammar(t, `%axiom S
+
+%%
+
+S : NUMBER
+{
+} |
+{
+};
+
+%%`, log.New(&buf, "", 0))
+
+	if !strings.Contains(buf.String(), "Warning: axiom S derives the empty string") {
+		t.Errorf("expected the axiom deriving the empty string to be logged as a warning, got %q", buf.String())
+	}
+}
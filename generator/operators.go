@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/giornetta/gopapageno"
+)
+
+// An associativity determines how operators with the same precedence level group.
+type associativity int
+
+const (
+	// assocLeft operators group from the left: a - b - c is (a - b) - c.
+	assocLeft associativity = iota
+	// assocRight operators group from the right: a ^ b ^ c is a ^ (b ^ c).
+	assocRight
+	// assocNone operators can't follow each other: a == b == c is a syntax error.
+	assocNone
+)
+
+func (a associativity) String() string {
+	switch a {
+	case assocLeft:
+		return "%left"
+	case assocRight:
+		return "%right"
+	default:
+		return "%nonassoc"
+	}
+}
+
+// An operatorPrecedence is the precedence level and the associativity of a terminal,
+// declared with %left, %right or %nonassoc. Terminals declared on later lines have higher levels, so they bind tighter.
+type operatorPrecedence struct {
+	level int
+	assoc associativity
+}
+
+// operatorPrecedences maps terminals to their declared precedence.
+type operatorPrecedences map[string]operatorPrecedence
+
+// declare gives the terminals a new precedence level, higher than the ones declared so far.
+func (o operatorPrecedences) declare(assoc associativity, terminals []string) error {
+	level := 0
+	for _, op := range o {
+		level = max(level, op.level+1)
+	}
+
+	for _, t := range terminals {
+		if _, ok := o[t]; ok {
+			return fmt.Errorf("precedence of terminal %s is declared more than once", t)
+		}
+
+		o[t] = operatorPrecedence{level: level, assoc: assoc}
+	}
+
+	return nil
+}
+
+// resolve returns the precedence between terminals a and b, when b follows a, according to their declared precedence.
+// Operators with a higher level are reduced first, while ones with the same level are grouped by their associativity.
+// It reports false if the precedence of either terminal isn't declared.
+func (o operatorPrecedences) resolve(a string, b string) (gopapageno.Precedence, bool) {
+	opA, okA := o[a]
+	opB, okB := o[b]
+	if !okA || !okB {
+		return gopapageno.PrecEmpty, false
+	}
+
+	switch {
+	case opA.level > opB.level:
+		return gopapageno.PrecTakes, true
+	case opA.level < opB.level:
+		return gopapageno.PrecYields, true
+	}
+
+	switch opA.assoc {
+	case assocLeft:
+		return gopapageno.PrecTakes, true
+	case assocRight:
+		return gopapageno.PrecYields, true
+	default:
+		return gopapageno.PrecEmpty, true
+	}
+}
+
+// A conflictResolver settles the conflicts between the Takes and Yields precedences of pairs of terminals
+// through their declared precedence, once every precedence has been computed.
+type conflictResolver struct {
+	operators operatorPrecedences
+
+	// resolved holds the pairs of terminals whose conflicts can be settled.
+	resolved map[[2]string]bool
+}
+
+func newConflictResolver(operators operatorPrecedences) *conflictResolver {
+	return &conflictResolver{
+		operators: operators,
+		resolved:  make(map[[2]string]bool),
+	}
+}
+
+// postpone reports whether the conflict between the precedences found for terminals a and b can be settled
+// through their declared precedence, recording it if so.
+func (r *conflictResolver) postpone(a string, b string, found gopapageno.Precedence, computed gopapageno.Precedence) bool {
+	if found == gopapageno.PrecEquals || computed == gopapageno.PrecEquals {
+		return false
+	}
+
+	if _, ok := r.operators.resolve(a, b); !ok {
+		return false
+	}
+
+	r.resolved[[2]string{a, b}] = true
+	return true
+}
+
+// apply settles the recorded conflicts in m.
+func (r *conflictResolver) apply(m precedenceMap, logger *log.Logger) {
+	for pair := range r.resolved {
+		prec, _ := r.operators.resolve(pair[0], pair[1])
+		m[pair[0]][pair[1]] = prec
+
+		logResolvedConflict(logger, pair[0], pair[1], prec)
+	}
+}
+
+// logResolvedConflict reports that the conflict between terminals a and b was settled as prec.
+func logResolvedConflict(logger *log.Logger, a string, b string, prec gopapageno.Precedence) {
+	logger.Printf("Precedence conflict on terminals %s and %s resolved as %v by their declared precedence.\n", a, b, prec)
+}
+
+// checkOperators verifies that the terminals whose precedence is declared are used in some rule.
+func (p *grammarDescription) checkOperators() error {
+	for t, op := range p.operators {
+		if !p.terminals.Contains(t) {
+			return fmt.Errorf("terminal %s declared with %v isn't used in any rule", t, op.assoc)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/giornetta/gopapageno"
+)
+
+func TestOperatorPrecedencesResolve(t *testing.T) {
+	o := make(operatorPrecedences)
+
+	for _, d := range []struct {
+		assoc     associativity
+		terminals []string
+	}{
+		{assocNone, []string{"EQ"}},
+		{assocLeft, []string{"PLUS", "MINUS"}},
+		{assocLeft, []string{"TIMES"}},
+		{assocRight, []string{"POW"}},
+	} {
+		if err := o.declare(d.assoc, d.terminals); err != nil {
+			t.Fatalf("could not declare %v: %v", d.terminals, err)
+		}
+	}
+
+	tests := []struct {
+		a, b     string
+		expected gopapageno.Precedence
+		ok       bool
+	}{
+		{"PLUS", "TIMES", gopapageno.PrecYields, true},
+		{"TIMES", "PLUS", gopapageno.PrecTakes, true},
+		{"PLUS", "PLUS", gopapageno.PrecTakes, true},
+		{"PLUS", "MINUS", gopapageno.PrecTakes, true},
+		{"MINUS", "PLUS", gopapageno.PrecTakes, true},
+		{"POW", "POW", gopapageno.PrecYields, true},
+		{"TIMES", "POW", gopapageno.PrecYields, true},
+		{"POW", "TIMES", gopapageno.PrecTakes, true},
+		{"EQ", "EQ", gopapageno.PrecEmpty, true},
+		{"EQ", "PLUS", gopapageno.PrecYields, true},
+		{"PLUS", "EQ", gopapageno.PrecTakes, true},
+		{"PLUS", "NUMBER", gopapageno.PrecEmpty, false},
+		{"NUMBER", "PLUS", gopapageno.PrecEmpty, false},
+	}
+
+	for _, tt := range tests {
+		prec, ok := o.resolve(tt.a, tt.b)
+		if prec != tt.expected || ok != tt.ok {
+			t.Errorf("resolve(%s, %s) = %v, %v, expected %v, %v", tt.a, tt.b, prec, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestOperatorPrecedencesDeclareTwice(t *testing.T) {
+	o := make(operatorPrecedences)
+
+	if err := o.declare(assocLeft, []string{"PLUS"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := o.declare(assocRight, []string{"POW", "PLUS"}); err == nil {
+		t.Errorf("expected an error declaring the precedence of PLUS twice")
+	}
+}
@@ -1,11 +1,14 @@
 package generator
 
 import (
-	"fmt"
+	"log"
 	"strings"
 )
 
-func (p *grammarDescription) deleteCopyRules(rulesDict *rulesDictionary) {
+// axiomSemAction is the semantic action of the rules added to derive the axiom from merged nonterminals.
+const axiomSemAction = "{\n\t$$.Value = $1.Value\n}"
+
+func (p *grammarDescription) deleteCopyRules(rulesDict *rulesDictionary, logger *log.Logger) {
 	copySets := make(map[string]*set[string], p.nonterminals.Len())
 	for _, nonterminal := range p.nonterminals.Iter {
 		copySets[nonterminal] = newSet[string]()
@@ -23,7 +26,7 @@ func (p *grammarDescription) deleteCopyRules(rulesDict *rulesDictionary) {
 		if len(rule.RHS) == 1 && p.nonterminals.Contains(rule.RHS[0]) {
 			copySets[rule.LHS].Add(rule.RHS[0])
 			if len(rule.Action) > 2 {
-				fmt.Println("WARNING: semantic action is deleted:", rule.LHS, rule.RHS)
+				logger.Printf("Warning: semantic action is deleted: %s %v\n", rule.LHS, rule.RHS)
 			}
 			rulesDict.Remove(rule.RHS)
 		} else {
@@ -68,7 +71,7 @@ func (p *grammarDescription) deleteCopyRules(rulesDict *rulesDictionary) {
 	}
 }
 
-func (p *grammarDescription) deleteRepeatedRHS() {
+func (p *grammarDescription) deleteRepeatedRHS(logger *log.Logger) {
 	newRules := make([]ruleDescription, 0)
 
 	// Create a rules dictionary and add every parsed ruleDescription to it.
@@ -103,7 +106,7 @@ func (p *grammarDescription) deleteRepeatedRHS() {
 	// if hasCopyRules && hasAxiomWithRrhs {
 	//     p.deleteCopyRules(dictRules)
 	// }
-	p.deleteCopyRules(dictRules)
+	p.deleteCopyRules(dictRules, logger)
 
 	V := dictRules.LHSSets()
 
@@ -179,15 +182,13 @@ func (p *grammarDescription) deleteRepeatedRHS() {
 	axiomSet := newSet[string]()
 	axiomSet.Add(p.axiom)
 
-	axiomSemAction := "{\n\t$$.Value = $1.Value\n}"
-
 	V = append(V, axiomSet)
 
 	for _, nontermSet := range V {
 		if nontermSet.Contains(p.axiom) {
 			newRulesDict.Add(&ruleDescription{
 				LHS:    p.axiom,
-				RHS:    []string{strings.Join(nontermSet.Slice(), "_")},
+				RHS:    []string{p.mergeNonterminals(nontermSet)},
 				Action: axiomSemAction,
 			})
 		}
@@ -209,7 +210,7 @@ func (p *grammarDescription) deleteRepeatedRHS() {
 		prefixes = newPrefixes
 
 		newRules = append(newRules, ruleDescription{
-			LHS:      strings.Join(valueLHS.Slice(), "_"),
+			LHS:      p.mergeNonterminals(valueLHS),
 			RHS:      keyRHS,
 			Action:   *semAction,
 			Flags:    flags,
@@ -222,6 +223,20 @@ func (p *grammarDescription) deleteRepeatedRHS() {
 	p.inferTokens()
 }
 
+// mergeNonterminals returns the name of the nonterminal replacing the ones in nonterminals,
+// recording where it comes from.
+func (p *grammarDescription) mergeNonterminals(nonterminals *set[string]) string {
+	names := nonterminals.Slice()
+	name := strings.Join(names, "_")
+
+	if p.merged == nil {
+		p.merged = make(map[string][]string)
+	}
+	p.merged[name] = names
+
+	return name
+}
+
 func (p *grammarDescription) extractTerminalRules(dictRules *rulesDictionary, newRulesDict *rulesDictionary) {
 	// Range over the current rules, check if the RHS contains any nonterminal
 	// If it doesn't (i.e. it is a *terminal ruleDescription*), add it to the new rules dictionary and remove it from the old dict.
@@ -269,7 +284,7 @@ func (p *grammarDescription) replaceTokenNames(keyRHS []string, newNonterminals
 		if p.nonterminals.Contains(token) {
 			for _, nonTermSuperSet := range newNonterminals {
 				if nonTermSuperSet.Contains(token) {
-					newTokens = append(newTokens, strings.Join(nonTermSuperSet.Slice(), "_"))
+					newTokens = append(newTokens, p.mergeNonterminals(nonTermSuperSet))
 					rec(tokens[1:], newTokens)
 
 					newTokensCopy := make([]string, len(newTokens)-1)
@@ -66,7 +66,7 @@ func (m precedenceMap) computeEqualsPrecedence(s gopapageno.ParsingStrategy, rul
 	return nil
 }
 
-func (m precedenceMap) computeTakesPrecedence(s gopapageno.ParsingStrategy, rules []ruleDescription, terminals []string, nonterminals *set[string], rts map[string]*set[string]) error {
+func (m precedenceMap) computeTakesPrecedence(s gopapageno.ParsingStrategy, rules []ruleDescription, terminals []string, nonterminals *set[string], rts map[string]*set[string], r *conflictResolver) error {
 	for _, rule := range rules {
 		for _, term1 := range terminals {
 			for _, term2 := range terminals {
@@ -91,7 +91,12 @@ func (m precedenceMap) computeTakesPrecedence(s gopapageno.ParsingStrategy, rule
 
 					if rts[rule.RHS[i2-1]].Contains(term1) {
 						if m[term1][term2] != gopapageno.PrecEmpty {
-							return fmt.Errorf("precedence conflict on terminals %s and %s (%v, %v)", term1, term2, m[term1][term2], gopapageno.PrecTakes)
+							if !r.postpone(term1, term2, m[term1][term2], gopapageno.PrecTakes) {
+								return precedenceConflictError(term1, term2, m[term1][term2], gopapageno.PrecTakes)
+							}
+
+							success = true
+							continue
 						}
 
 						m[term1][term2] = gopapageno.PrecTakes
@@ -115,7 +120,7 @@ func allIndices[T comparable](slice []T, el T) []int {
 	return indices
 }
 
-func (m precedenceMap) computeYieldsPrecedence(s gopapageno.ParsingStrategy, rules []ruleDescription, terminals []string, nonterminals *set[string], lts map[string]*set[string]) error {
+func (m precedenceMap) computeYieldsPrecedence(s gopapageno.ParsingStrategy, rules []ruleDescription, terminals []string, nonterminals *set[string], lts map[string]*set[string], r *conflictResolver) error {
 	for _, rule := range rules {
 		for _, term1 := range terminals {
 			for _, term2 := range terminals {
@@ -144,7 +149,12 @@ func (m precedenceMap) computeYieldsPrecedence(s gopapageno.ParsingStrategy, rul
 
 					if lts[rule.RHS[i1+1]].Contains(term2) {
 						if m[term1][term2] != gopapageno.PrecEmpty {
-							return fmt.Errorf("precedence conflict on terminals %s and %s (%v, %v)", term1, term2, m[term1][term2], gopapageno.PrecYields)
+							if !r.postpone(term1, term2, m[term1][term2], gopapageno.PrecYields) {
+								return precedenceConflictError(term1, term2, m[term1][term2], gopapageno.PrecYields)
+							}
+
+							success = true
+							continue
 						}
 						m[term1][term2] = gopapageno.PrecYields
 						success = true
@@ -157,6 +167,16 @@ func (m precedenceMap) computeYieldsPrecedence(s gopapageno.ParsingStrategy, rul
 	return nil
 }
 
+// precedenceConflictError describes a conflict between two precedences found for terminals term1 and term2.
+func precedenceConflictError(term1 string, term2 string, found gopapageno.Precedence, computed gopapageno.Precedence) error {
+	if found == gopapageno.PrecEquals || computed == gopapageno.PrecEquals {
+		return fmt.Errorf("precedence conflict on terminals %s and %s (%v, %v)", term1, term2, found, computed)
+	}
+
+	return fmt.Errorf("precedence conflict on terminals %s and %s (%v, %v): declare their precedence with %%left, %%right or %%nonassoc to settle it",
+		term1, term2, found, computed)
+}
+
 func (m precedenceMap) buildMatrix(terminals []string) (precedenceMatrix, error) {
 	for _, terminal := range terminals {
 		if terminal != termToken {
@@ -186,24 +206,27 @@ func (p *grammarDescription) newPrecedenceMatrix(opts *Options) (matrix preceden
 
 	// TODO: Remove this when refactoring AOPP matrix creation.
 	if opts.Strategy == gopapageno.AOPP {
-		matrix, err = p.newAssociativePrecedenceMatrix()
+		matrix, err = p.newAssociativePrecedenceMatrix(opts)
 	} else {
 		lts, rts := p.getTerminalSets()
 
 		m := newPrecedenceMap(terminals)
+		r := newConflictResolver(p.operators)
 
 		if err := m.computeEqualsPrecedence(opts.Strategy, p.rules, terminals, p.nonterminals); err != nil {
 			return nil, err
 		}
 
-		if err := m.computeTakesPrecedence(opts.Strategy, p.rules, terminals, p.nonterminals, rts); err != nil {
+		if err := m.computeTakesPrecedence(opts.Strategy, p.rules, terminals, p.nonterminals, rts, r); err != nil {
 			return nil, err
 		}
 
-		if err := m.computeYieldsPrecedence(opts.Strategy, p.rules, terminals, p.nonterminals, lts); err != nil {
+		if err := m.computeYieldsPrecedence(opts.Strategy, p.rules, terminals, p.nonterminals, lts, r); err != nil {
 			return nil, err
 		}
 
+		r.apply(m, opts.Logger)
+
 		matrix, err = m.buildMatrix(terminals)
 	}
 
@@ -241,7 +264,7 @@ type conflict struct {
 	j    int
 }
 
-func (p *grammarDescription) newAssociativePrecedenceMatrix() (precedenceMatrix, error) {
+func (p *grammarDescription) newAssociativePrecedenceMatrix(opts *Options) (precedenceMatrix, error) {
 	m := make(map[string]map[string]map[gopapageno.Precedence][]conflict)
 	nonOP := make([]conflict, 0)
 
@@ -366,24 +389,34 @@ func (p *grammarDescription) newAssociativePrecedenceMatrix() (precedenceMatrix,
 				prec = gopapageno.PrecYields
 			}
 
+			// Conflicts between Takes and Yields on terminals whose precedence is declared are settled by it.
+			if _, ok := conflicts[gopapageno.PrecEquals]; len(conflicts) > 1 && !ok {
+				if prec, ok := p.operators.resolve(term, term2); ok {
+					precMatrix[i][j] = prec
+					logResolvedConflict(opts.Logger, term, term2, prec)
+					continue
+				}
+			}
+
 			// Handle conflicts.
 			// If `n : n T n` is present, it might be an associative conflict.
 			if len(conflicts) > 1 {
 				ok := false
-				for p, cc := range conflicts {
-					if p == gopapageno.PrecEquals {
+				for _, prec := range sortedKeys(conflicts) {
+					cc := conflicts[prec]
+					if prec == gopapageno.PrecEquals {
 						return nil, fmt.Errorf("strong precedence conflict: %v", cc)
 					}
 
 					// This is NOT enough, but we can leave it as is for testing purposes
 
 					for _, c := range cc {
-						if len(c.rule.RHS) == 3 && c.rule.RHS[0] == c.rule.LHS && c.rule.RHS[2] == c.rule.LHS && slices.Contains(terminals, c.rule.RHS[1]) {
+						if p.isAssociative(c.rule) {
 							precMatrix[i][j] = gopapageno.PrecAssociative
 							ok = true
 							break
 						} else {
-							LogPrecedenceConflict(p, c)
+							logPrecedenceConflict(opts.Logger, prec, c)
 						}
 					}
 
@@ -407,6 +440,13 @@ func (p *grammarDescription) newAssociativePrecedenceMatrix() (precedenceMatrix,
 	return precMatrix, nil
 }
 
+// isAssociative reports whether rule has the form n : n T n, T being a terminal,
+// whose conflicts the AOPP strategy settles as associative.
+func (p *grammarDescription) isAssociative(rule ruleDescription) bool {
+	rhs := rule.RHS
+	return len(rhs) == 3 && rhs[0] == rule.LHS && rhs[2] == rule.LHS && p.terminals.Contains(rhs[1])
+}
+
 // getTerminalSets returns two maps mapping nonterminal tokens to possible terminal productions.
 func (p *grammarDescription) getTerminalSets() (lts map[string]*set[string], rts map[string]*set[string]) {
 	lts = make(map[string]*set[string], p.nonterminals.Len())
@@ -0,0 +1,42 @@
+package regex
+
+/*
+Anchors constrain the positions at which the matches of a regular expression may begin and end.
+*/
+type Anchors uint8
+
+const (
+	// LineStart requires matches to begin at the start of a line.
+	LineStart Anchors = 1 << iota
+	// LineEnd requires matches to end at the end of a line.
+	LineEnd
+)
+
+/*
+SplitAnchors strips the ^ anchor beginning a regular expression and the $ anchor ending it.
+It returns the remaining expression and the anchors that were found.
+Anchors can't be matched by an automaton, so they must be checked separately when a match is found.
+*/
+func SplitAnchors(expr []byte) ([]byte, Anchors) {
+	var anchors Anchors
+
+	if len(expr) > 0 && expr[0] == '^' {
+		anchors |= LineStart
+		expr = expr[1:]
+	}
+
+	if len(expr) > 0 && expr[len(expr)-1] == '$' {
+		// A $ preceded by an odd number of backslashes is escaped.
+		backslashes := 0
+		for i := len(expr) - 2; i >= 0 && expr[i] == '\\'; i-- {
+			backslashes++
+		}
+
+		if backslashes%2 == 0 {
+			anchors |= LineEnd
+			expr = expr[:len(expr)-1]
+		}
+	}
+
+	return expr, anchors
+}
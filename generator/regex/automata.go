@@ -236,7 +236,12 @@ func (nfa *Nfa) ToDfa() Dfa {
 
 	dfa := Dfa{&initialDfaState, make([]*DfaState, 0), 1}
 
-	genStates = append(genStates, nfaStateSetPtr{nfa.Initial.EpsilonClosure(), &initialDfaState})
+	initialClosure := nfa.Initial.EpsilonClosure()
+	for _, curNfaState := range initialClosure {
+		initialDfaState.AssociatedRules = append(initialDfaState.AssociatedRules, curNfaState.AssociatedRules...)
+	}
+
+	genStates = append(genStates, nfaStateSetPtr{initialClosure, &initialDfaState})
 
 	search := func(gStates []nfaStateSetPtr, stateSet []*NfaState) *nfaStateSetPtr {
 		for _, curGState := range gStates {
@@ -0,0 +1,108 @@
+package regex
+
+import "sort"
+
+/*
+NewCharClassNfa returns an automaton matching any single byte of the class.
+*/
+func NewCharClassNfa(chars [256]bool) Nfa {
+	return newNfaFromCharClass(chars)
+}
+
+/*
+StartBytes reports, for each byte, whether a match of the dfa can begin with it.
+*/
+func (dfa *Dfa) StartBytes() [256]bool {
+	var bytes [256]bool
+	for c, next := range dfa.Initial.Transitions {
+		bytes[c] = next != nil
+	}
+
+	return bytes
+}
+
+/*
+Continuation returns one of the shortest inputs after which the dfa can read c without beginning a new match,
+along with the state it reaches. It returns false if c can only begin matches.
+*/
+func (dfa *Dfa) Continuation(c byte) ([]byte, *DfaState, bool) {
+	inputs := dfa.ShortestInputs()
+
+	var shortest []byte
+	var target *DfaState
+
+	// Only the states reached after reading some input are in the middle of a match.
+	for _, state := range dfa.GetStates() {
+		if state.Transitions[c] == nil || !dfa.isReentered(state) {
+			continue
+		}
+
+		if target == nil || len(inputs[state.Num]) < len(shortest) {
+			shortest = inputs[state.Num]
+			target = state.Transitions[c]
+		}
+	}
+
+	if target == nil {
+		return nil, nil, false
+	}
+
+	return append(append([]byte(nil), shortest...), c), target, true
+}
+
+/*
+isReentered reports whether the state can be reached after reading some input.
+Every state but the initial one is, while the initial one is if some transition leads back to it.
+*/
+func (dfa *Dfa) isReentered(state *DfaState) bool {
+	if state != dfa.Initial {
+		return true
+	}
+
+	for _, s := range dfa.GetStates() {
+		for _, next := range s.Transitions {
+			if next == dfa.Initial {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+/*
+ReachableRules returns the sorted rules associated to the final states that can be reached from state, including itself.
+*/
+func (dfa *Dfa) ReachableRules(state *DfaState) []int {
+	visited := make([]bool, dfa.NumStates)
+	found := make(map[int]bool)
+
+	stack := []*DfaState{state}
+	visited[state.Num] = true
+
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if s.IsFinal {
+			for _, rule := range s.AssociatedRules {
+				found[rule] = true
+			}
+		}
+
+		for _, next := range s.Transitions {
+			if next != nil && !visited[next.Num] {
+				visited[next.Num] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+
+	rules := make([]int, 0, len(found))
+	for rule := range found {
+		rules = append(rules, rule)
+	}
+	sort.Ints(rules)
+
+	return rules
+}
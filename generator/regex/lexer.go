@@ -2,6 +2,8 @@ package regex
 
 import (
 	"errors"
+	"fmt"
+	"unicode/utf8"
 )
 
 const (
@@ -16,6 +18,9 @@ lexer contains the file data and the current position.
 type lexer struct {
 	data []byte
 	pos  int
+
+	// err describes why the last token couldn't be read.
+	err error
 }
 
 /*
@@ -24,8 +29,6 @@ lexerPreallocMem initializes all the memory pools required by the lexer.
 func lexerPreallocMem(inputSize int, numThreads int) {
 }
 
-var insideSet bool = false
-
 /*
 yyLex reads a token from the lexer and creates a new symbol, saving it in genSym.
 It returns one of the following codes:
@@ -34,6 +37,23 @@ END_OF_FILE if there's no more data to lex
 LEX_CORRECT if a token was successfully read
 */
 func (l *lexer) yyLex(genSym *symbol) int {
+	res := l.lexSymbol(genSym)
+	if res != _LEX_CORRECT || (genSym.Token != char && genSym.Token != anyc) {
+		return res
+	}
+
+	if err := l.quantify(genSym); err != nil {
+		l.err = err
+		return _ERROR
+	}
+
+	return _LEX_CORRECT
+}
+
+/*
+lexSymbol reads a token from the lexer, without applying the quantifiers that may follow it.
+*/
+func (l *lexer) lexSymbol(genSym *symbol) int {
 	for l.pos < len(l.data) {
 		curChar := l.data[l.pos]
 
@@ -41,17 +61,33 @@ func (l *lexer) yyLex(genSym *symbol) int {
 
 		switch {
 		case curChar == '(':
+			// Quantified groups are matched as a whole, since the grammar only allows * and + to follow them.
+			nfa, err := l.lexGroup()
+			if err != nil {
+				l.err = err
+				return _ERROR
+			} else if nfa != nil {
+				*genSym = symbol{anyc, 0, nfa, nil, nil}
+				return _LEX_CORRECT
+			}
+
 			*genSym = symbol{lpar, 0, nil, nil, nil}
 			return _LEX_CORRECT
 		case curChar == ')':
 			*genSym = symbol{rpar, 0, nil, nil, nil}
 			return _LEX_CORRECT
 		case curChar == '[':
-			insideSet = true
-			*genSym = symbol{squarelpar, 0, nil, nil, nil}
+			// Classes are matched as a whole, since their code points may be encoded by more than one byte.
+			set, err := l.lexClass()
+			if err != nil {
+				l.err = err
+				return _ERROR
+			}
+
+			newNfa := newNfaFromRuneSet(set)
+			*genSym = symbol{anyc, 0, &newNfa, nil, nil}
 			return _LEX_CORRECT
 		case curChar == ']':
-			insideSet = false
 			*genSym = symbol{squarerpar, 0, nil, nil, nil}
 			return _LEX_CORRECT
 		case curChar == '*':
@@ -60,9 +96,6 @@ func (l *lexer) yyLex(genSym *symbol) int {
 		case curChar == '+':
 			*genSym = symbol{plus, 0, nil, nil, nil}
 			return _LEX_CORRECT
-		case curChar == '-':
-			*genSym = symbol{dash, 0, nil, nil, nil}
-			return _LEX_CORRECT
 		case curChar == '|':
 			*genSym = symbol{pipe, 0, nil, nil, nil}
 			return _LEX_CORRECT
@@ -70,65 +103,96 @@ func (l *lexer) yyLex(genSym *symbol) int {
 			*genSym = symbol{caret, 0, nil, nil, nil}
 			return _LEX_CORRECT
 		case curChar == '.':
-			var anyCharClass [256]bool
-			//Skip the first char (empty transition)
-			for i := 1; i < len(anyCharClass); i++ {
-				anyCharClass[i] = true
-			}
-			anyCharClass['\n'] = false
-			anyCharClass['\r'] = false
-			newNfa := newNfaFromCharClass(anyCharClass)
+			newNfa := newNfaFromRuneSet(anyCharSet())
 			*genSym = symbol{anyc, 0, &newNfa, nil, nil}
 			return _LEX_CORRECT
+		case l.quantifierAt(l.pos - 1):
+			l.err = fmt.Errorf("quantifier at position %d doesn't follow a character, class or group", l.pos-1)
+			return _ERROR
 		case curChar == '\t' || curChar == '\r' || curChar == '\n':
 			//Do nothing
 		case curChar == '\\':
-			escapedChar := l.data[l.pos]
-			l.pos++
-			switch escapedChar {
-			case '(', ')', '[', ']', '*', '+', '-', '|', '^', '.', '\\':
-				token := uint16(char)
-				if insideSet {
-					token = charinset
-				}
-				*genSym = symbol{token, 0, escapedChar, nil, nil}
-				return _LEX_CORRECT
-			case 't':
-				token := uint16(char)
-				if insideSet {
-					token = charinset
-				}
-				*genSym = symbol{token, 0, byte('\t'), nil, nil}
-				return _LEX_CORRECT
-			case 'r':
-				token := uint16(char)
-				if insideSet {
-					token = charinset
-				}
-				*genSym = symbol{token, 0, byte('\r'), nil, nil}
-				return _LEX_CORRECT
-			case 'n':
-				token := uint16(char)
-				if insideSet {
-					token = charinset
-				}
-				*genSym = symbol{token, 0, byte('\n'), nil, nil}
+			l.pos--
+
+			if set, ok, err := l.lexClassEscape(); err != nil {
+				l.err = err
+				return _ERROR
+			} else if ok {
+				newNfa := newNfaFromRuneSet(set)
+				*genSym = symbol{anyc, 0, &newNfa, nil, nil}
 				return _LEX_CORRECT
-			default:
+			}
+
+			l.pos++
+
+			r, err := l.lexEscapedRune()
+			if err != nil {
+				l.err = err
 				return _ERROR
 			}
-		default:
-			token := uint16(char)
-			if insideSet {
-				token = charinset
+
+			*genSym = l.runeSymbol(r)
+			return _LEX_CORRECT
+		case curChar >= utf8.RuneSelf:
+			l.pos--
+
+			r, err := l.lexRune()
+			if err != nil {
+				l.err = err
+				return _ERROR
 			}
-			*genSym = symbol{token, 0, curChar, nil, nil}
+
+			*genSym = l.runeSymbol(r)
+			return _LEX_CORRECT
+		default:
+			*genSym = symbol{char, 0, curChar, nil, nil}
 			return _LEX_CORRECT
 		}
 	}
 	return _END_OF_FILE
 }
 
+/*
+quantify applies the ? and {m,n} quantifiers following the character or the automaton of genSym.
+Since the grammar has no rules for them, the quantified atom becomes an automaton of its own.
+*/
+func (l *lexer) quantify(genSym *symbol) error {
+	if !l.quantifierAt(l.pos) {
+		return nil
+	}
+
+	var nfa *Nfa
+	if genSym.Token == char {
+		newNfa := newNfaFromChar(genSym.Value.(byte))
+		nfa = &newNfa
+	} else {
+		nfa = genSym.Value.(*Nfa)
+	}
+
+	if err := l.lexQuantifiers(nfa); err != nil {
+		return err
+	}
+
+	*genSym = symbol{anyc, 0, nfa, nil, nil}
+	return nil
+}
+
+/*
+runeSymbol returns the symbol matching the code point r.
+Characters encoded by more than one byte are matched by an automaton of their own.
+*/
+func (l *lexer) runeSymbol(r rune) symbol {
+	if r < utf8.RuneSelf {
+		return symbol{char, 0, byte(r), nil, nil}
+	}
+
+	set := runeSet{}
+	set.addRune(r)
+
+	newNfa := newNfaFromRuneSet(set)
+	return symbol{anyc, 0, &newNfa, nil, nil}
+}
+
 /*
 lex reads an input string as a slice of byte and lexes it, pushing each symbol in a LOS.
 It returns a LOS containing all the lexed symbols.
@@ -139,7 +203,7 @@ func lex(input []byte, stackPool *stackPool) (LOS, error) {
 
 	sym := symbol{}
 
-	lexer := lexer{input, 0}
+	lexer := lexer{data: input}
 
 	//Lex the first symbol
 	res := lexer.yyLex(&sym)
@@ -147,6 +211,9 @@ func lex(input []byte, stackPool *stackPool) (LOS, error) {
 	//Keep lexing until the end of the file is reached or an error occurs
 	for res != _END_OF_FILE {
 		if res == _ERROR {
+			if lexer.err != nil {
+				return los, fmt.Errorf("lexing error: %w", lexer.err)
+			}
 			return los, errors.New("lexing error")
 		}
 		los.Push(&sym)
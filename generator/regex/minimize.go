@@ -0,0 +1,242 @@
+package regex
+
+import (
+	"fmt"
+	"sort"
+)
+
+/*
+Minimize returns the minimal automaton equivalent to the dfa, built with Hopcroft's partition refinement algorithm.
+Final states are only merged when they are associated to the same rules, so that matches are still told apart.
+The initial state of the minimal automaton is numbered 0, and the others are numbered in breadth-first order.
+*/
+func (dfa *Dfa) Minimize() Dfa {
+	states := dfa.GetStates()
+
+	// The automaton is completed by a dead state, reached by every missing transition.
+	numStates := len(states) + 1
+	dead := len(states)
+
+	target := func(state int, c int) int {
+		if state == dead || states[state].Transitions[c] == nil {
+			return dead
+		}
+		return states[state].Transitions[c].Num
+	}
+
+	// inverse[c][t] holds the states reaching t through c.
+	inverse := make([][][]int, 256)
+	for c := 1; c < 256; c++ {
+		inverse[c] = make([][]int, numStates)
+		for s := 0; s < numStates; s++ {
+			t := target(s, c)
+			inverse[c][t] = append(inverse[c][t], s)
+		}
+	}
+
+	// States are initially partitioned by the rules they are associated to.
+	// The dead state is equivalent to any state that can't lead to a match, which are therefore dropped.
+	blockOf := make([]int, numStates)
+	blocks := make([][]int, 0)
+
+	keys := make(map[string]int)
+	for s := 0; s < numStates; s++ {
+		key := fmt.Sprint(false, []int{})
+		if s != dead {
+			rules := append([]int(nil), states[s].AssociatedRules...)
+			sort.Ints(rules)
+			key = fmt.Sprint(states[s].IsFinal, rules)
+		}
+
+		block, ok := keys[key]
+		if !ok {
+			block = len(blocks)
+			keys[key] = block
+			blocks = append(blocks, nil)
+		}
+
+		blockOf[s] = block
+		blocks[block] = append(blocks[block], s)
+	}
+
+	waiting := make([]int, 0, len(blocks))
+	isWaiting := make([]bool, len(blocks), numStates)
+	for b := range blocks {
+		waiting = append(waiting, b)
+		isWaiting[b] = true
+	}
+
+	marked := make([]bool, numStates)
+	for len(waiting) > 0 {
+		splitter := blocks[waiting[len(waiting)-1]]
+		isWaiting[waiting[len(waiting)-1]] = false
+		waiting = waiting[:len(waiting)-1]
+
+		// The splitter is copied, since its block may be split while it's being used.
+		splitter = append([]int(nil), splitter...)
+
+		for c := 1; c < 256; c++ {
+			// Mark the states reaching the splitter through c, and collect the blocks they belong to.
+			touched := make([]int, 0)
+			for _, t := range splitter {
+				for _, s := range inverse[c][t] {
+					if !marked[s] {
+						marked[s] = true
+						touched = append(touched, blockOf[s])
+					}
+				}
+			}
+
+			for _, b := range touched {
+				in := make([]int, 0)
+				out := make([]int, 0)
+				for _, s := range blocks[b] {
+					if marked[s] {
+						in = append(in, s)
+					} else {
+						out = append(out, s)
+					}
+				}
+
+				if len(in) == 0 || len(out) == 0 {
+					continue
+				}
+
+				// The marked states keep the block, the others are moved to a new one.
+				newBlock := len(blocks)
+				blocks[b] = in
+				blocks = append(blocks, out)
+				isWaiting = append(isWaiting, false)
+				for _, s := range out {
+					blockOf[s] = newBlock
+				}
+
+				if isWaiting[b] || len(out) <= len(in) {
+					waiting = append(waiting, newBlock)
+					isWaiting[newBlock] = true
+				} else {
+					waiting = append(waiting, b)
+					isWaiting[b] = true
+				}
+			}
+
+			for _, t := range splitter {
+				for _, s := range inverse[c][t] {
+					marked[s] = false
+				}
+			}
+		}
+	}
+
+	// Build the minimal automaton, skipping the block of the dead state.
+	minStates := make([]*DfaState, len(blocks))
+	minDfa := Dfa{Final: make([]*DfaState, 0)}
+
+	queue := make([]int, 0)
+
+	newState := func(block int) *DfaState {
+		if minStates[block] == nil {
+			representative := states[blocks[block][0]]
+
+			minStates[block] = &DfaState{
+				Num:             minDfa.NumStates,
+				IsFinal:         representative.IsFinal,
+				AssociatedRules: append([]int(nil), representative.AssociatedRules...),
+			}
+			minDfa.NumStates++
+
+			if representative.IsFinal {
+				minDfa.Final = append(minDfa.Final, minStates[block])
+			}
+
+			queue = append(queue, block)
+		}
+		return minStates[block]
+	}
+
+	minDfa.Initial = newState(blockOf[dfa.Initial.Num])
+
+	for len(queue) > 0 {
+		block := queue[0]
+		queue = queue[1:]
+
+		representative := blocks[block][0]
+		for c := 1; c < 256; c++ {
+			t := target(representative, c)
+			if blockOf[t] == blockOf[dead] {
+				continue
+			}
+
+			minStates[block].Transitions[c] = newState(blockOf[t])
+		}
+	}
+
+	return minDfa
+}
+
+/*
+ByteClasses partitions the bytes into equivalence classes: two bytes belong to the same class if every state of the dfa
+reaches the same state through both of them.
+It returns the class of each byte and the number of classes. Classes are numbered in order of their first byte.
+*/
+func (dfa *Dfa) ByteClasses() ([256]int, int) {
+	states := dfa.GetStates()
+
+	var classes [256]int
+	numClasses := 0
+
+	signatures := make(map[string]int)
+	signature := make([]byte, 0, len(states)*4)
+
+	for c := 0; c < 256; c++ {
+		signature = signature[:0]
+		for _, state := range states {
+			t := -1
+			if next := state.Transitions[c]; next != nil {
+				t = next.Num
+			}
+			signature = fmt.Appendf(signature, "%d,", t)
+		}
+
+		class, ok := signatures[string(signature)]
+		if !ok {
+			class = numClasses
+			signatures[string(signature)] = class
+			numClasses++
+		}
+
+		classes[c] = class
+	}
+
+	return classes, numClasses
+}
+
+/*
+ShortestInputs returns, for each state of the dfa, one of the shortest inputs leading the initial state to it,
+indexed by state number. Inputs are built in breadth-first order, preferring lower bytes.
+*/
+func (dfa *Dfa) ShortestInputs() [][]byte {
+	inputs := make([][]byte, dfa.NumStates)
+	inputs[dfa.Initial.Num] = []byte{}
+
+	queue := []*DfaState{dfa.Initial}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+
+		for c, next := range state.Transitions {
+			if next == nil || inputs[next.Num] != nil {
+				continue
+			}
+
+			input := make([]byte, len(inputs[state.Num])+1)
+			copy(input, inputs[state.Num])
+			input[len(input)-1] = byte(c)
+
+			inputs[next.Num] = input
+			queue = append(queue, next)
+		}
+	}
+
+	return inputs
+}
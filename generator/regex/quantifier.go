@@ -0,0 +1,234 @@
+package regex
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+/*
+maxRepetitions is the maximum bound of a counted repetition.
+Each repetition copies the automaton of the repeated expression, so bounds must be kept reasonably small.
+*/
+const maxRepetitions = 1000
+
+var errUnterminatedGroup = errors.New("unterminated group")
+
+/*
+clone returns a copy of the automaton that doesn't share any state with the original one.
+*/
+func (nfa *Nfa) clone() Nfa {
+	copies := make(map[*NfaState]*NfaState)
+
+	var copyState func(state *NfaState) *NfaState
+	copyState = func(state *NfaState) *NfaState {
+		if newState, ok := copies[state]; ok {
+			return newState
+		}
+
+		newState := &NfaState{}
+		copies[state] = newState
+
+		if state.AssociatedRules != nil {
+			newState.AssociatedRules = append([]int(nil), state.AssociatedRules...)
+		}
+
+		for c, nextStates := range state.Transitions {
+			for _, nextState := range nextStates {
+				newState.AddTransition(byte(c), copyState(nextState))
+			}
+		}
+
+		return newState
+	}
+
+	return Nfa{
+		Initial:   copyState(nfa.Initial),
+		Final:     copyState(nfa.Final),
+		NumStates: nfa.NumStates,
+	}
+}
+
+/*
+Repeat turns the automaton into one matching from min to max consecutive matches of the original one.
+A negative max leaves the number of repetitions unbounded.
+*/
+func (nfa *Nfa) Repeat(min int, max int) {
+	result := NewEmptyStringNfa()
+
+	for i := 0; i < min; i++ {
+		repetition := nfa.clone()
+		result.Concatenate(repetition)
+	}
+
+	if max < 0 {
+		repetition := nfa.clone()
+		repetition.KleeneStar()
+		result.Concatenate(repetition)
+	} else {
+		for i := min; i < max; i++ {
+			repetition := nfa.clone()
+			repetition.ZeroOrOne()
+			result.Concatenate(repetition)
+		}
+	}
+
+	*nfa = result
+}
+
+/*
+quantifierAt reports whether a ? or {m,n} quantifier begins at position pos.
+*/
+func (l *lexer) quantifierAt(pos int) bool {
+	if pos >= len(l.data) {
+		return false
+	}
+	if l.data[pos] == '?' {
+		return true
+	}
+
+	_, _, _, ok, err := l.repetitionAt(pos)
+	return ok || err != nil
+}
+
+/*
+lexQuantifiers applies the ? and {m,n} quantifiers following an atom to its automaton.
+*/
+func (l *lexer) lexQuantifiers(nfa *Nfa) error {
+	for l.quantifierAt(l.pos) {
+		if l.data[l.pos] == '?' {
+			l.pos++
+
+			nfa.ZeroOrOne()
+			continue
+		}
+
+		min, max, end, _, err := l.repetitionAt(l.pos)
+		if err != nil {
+			return err
+		}
+
+		l.pos = end
+		nfa.Repeat(min, max)
+	}
+
+	return nil
+}
+
+/*
+repetitionAt reads a counted repetition such as {m}, {m,} or {m,n}, if one begins at position pos.
+It returns its bounds and the position following it. The maximum is negative if the repetition is unbounded.
+A brace that doesn't begin a counted repetition is a character.
+*/
+func (l *lexer) repetitionAt(pos int) (int, int, int, bool, error) {
+	if pos >= len(l.data) || l.data[pos] != '{' {
+		return 0, 0, 0, false, nil
+	}
+	pos++
+
+	readNumber := func() (int, bool) {
+		start := pos
+		for pos < len(l.data) && l.data[pos] >= '0' && l.data[pos] <= '9' {
+			pos++
+		}
+		if pos == start {
+			return 0, false
+		}
+
+		n, err := strconv.Atoi(string(l.data[start:pos]))
+		if err != nil {
+			return maxRepetitions + 1, true
+		}
+		return n, true
+	}
+
+	min, ok := readNumber()
+	if !ok {
+		return 0, 0, 0, false, nil
+	}
+
+	max := min
+	if pos < len(l.data) && l.data[pos] == ',' {
+		pos++
+
+		if max, ok = readNumber(); !ok {
+			max = -1
+		}
+	}
+
+	if pos >= len(l.data) || l.data[pos] != '}' {
+		return 0, 0, 0, false, nil
+	}
+
+	if min > maxRepetitions || max > maxRepetitions {
+		return 0, 0, 0, false, fmt.Errorf("repetition count exceeds the maximum of %d", maxRepetitions)
+	}
+	if max >= 0 && max < min {
+		return 0, 0, 0, false, fmt.Errorf("invalid repetition {%d,%d}: the minimum exceeds the maximum", min, max)
+	}
+
+	return min, max, pos + 1, true, nil
+}
+
+/*
+lexGroup reads a parenthesized group, whose opening parenthesis has already been read, if a quantifier follows it.
+Quantified groups are parsed on their own, so that the quantifier can be applied to their automaton.
+It returns nil if the group isn't quantified, and must then be parsed as part of the enclosing expression.
+*/
+func (l *lexer) lexGroup() (*Nfa, error) {
+	end, err := l.groupEnd()
+	if err != nil {
+		return nil, err
+	}
+
+	if !l.quantifierAt(end + 1) {
+		return nil, nil
+	}
+
+	inner := l.data[l.pos:end]
+
+	success, result := ParseString(inner, 1)
+	if !success || result == nil {
+		return nil, fmt.Errorf("invalid group (%s)", inner)
+	}
+
+	l.pos = end + 1
+
+	nfa := result.Value.(*Nfa)
+	if err := l.lexQuantifiers(nfa); err != nil {
+		return nil, err
+	}
+
+	return nfa, nil
+}
+
+/*
+groupEnd returns the position of the parenthesis closing the group that begins at the current position.
+Escaped parentheses and those inside character classes are ignored.
+*/
+func (l *lexer) groupEnd() (int, error) {
+	depth := 0
+	insideClass := false
+
+	for pos := l.pos; pos < len(l.data); pos++ {
+		switch c := l.data[pos]; {
+		case c == '\\':
+			pos++
+		case insideClass:
+			if c == ']' {
+				insideClass = false
+			}
+		case c == '[':
+			insideClass = true
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth == 0 {
+				return pos, nil
+			}
+			depth--
+		}
+	}
+
+	return 0, errUnterminatedGroup
+}
@@ -1,15 +1,21 @@
 // Code generated by Gopapageno; DO NOT EDIT.
 package refactored
 
-import "github.com/giornetta/gopapageno"
+import (
+	"github.com/giornetta/gopapageno"
+	"strings"
+	"fmt"
+	"os"
+)
+
 
 // Non-terminals
 const (
-	CONCATENATION_RE_SIMPLE_RE = gopapageno.TokenEmpty + 1 + iota
-	NEW_AXIOM
-	RE_SIMPLE_RE
-	RE_UNION
+	CONCATENATION_SIMPLE_RE = gopapageno.TokenEmpty + 1 + iota
+	RE
 	SET_ITEMS
+	SIMPLE_RE
+	UNION
 )
 
 // Terminals
@@ -28,566 +34,769 @@ const (
 	Star
 )
 
-func NewParser(opts ...gopapageno.ParserOpt) *gopapageno.Grammar {
+func DumpGraph[ValueType any](root *gopapageno.Token, f *os.File) {
+	sb := strings.Builder{}
+	sb.WriteString("digraph parse_tree {\n")
+	sb.WriteString("ratio = fill;\n")
+	sb.WriteString("node [style=filled];\n")
+
+	var graphPrintRec func(t *gopapageno.Token, p *gopapageno.Token, sb *strings.Builder, i int)
+	graphPrintRec = func(t *gopapageno.Token, p *gopapageno.Token, sb *strings.Builder, i int) {
+		if t == nil {
+			return
+		}
+
+		if p == nil {
+			graphPrintRec(t.Child, t, sb, i+1)
+			return
+		}
+
+		var t_name, t_color, p_name, p_color string
+
+		switch p.Type {
+		case CONCATENATION_SIMPLE_RE:
+			p_name, p_color = "CONCATENATION_SIMPLE_RE", "0.408 0.498 1.000"
+		case RE:
+			p_name, p_color = "RE", "0.408 0.498 1.000"
+		case SET_ITEMS:
+			p_name, p_color = "SET_ITEMS", "0.408 0.498 1.000"
+		case SIMPLE_RE:
+			p_name, p_color = "SIMPLE_RE", "0.408 0.498 1.000"
+		case UNION:
+			p_name, p_color = "UNION", "0.408 0.498 1.000"
+		case gopapageno.TokenEmpty:
+			p_name, p_color = "__EMPTY__", "0.408 0.498 1.000"
+		case Any:
+			p_name, p_color = "Any", "0.641 0.212 1.000"
+		case Caret:
+			p_name, p_color = "Caret", "0.641 0.212 1.000"
+		case Char:
+			p_name, p_color = "Char", "0.641 0.212 1.000"
+		case CharInSet:
+			p_name, p_color = "CharInSet", "0.641 0.212 1.000"
+		case Dash:
+			p_name, p_color = "Dash", "0.641 0.212 1.000"
+		case LPar:
+			p_name, p_color = "LPar", "0.641 0.212 1.000"
+		case Pipe:
+			p_name, p_color = "Pipe", "0.641 0.212 1.000"
+		case Plus:
+			p_name, p_color = "Plus", "0.641 0.212 1.000"
+		case RPar:
+			p_name, p_color = "RPar", "0.641 0.212 1.000"
+		case SquareLPar:
+			p_name, p_color = "SquareLPar", "0.641 0.212 1.000"
+		case SquareRPar:
+			p_name, p_color = "SquareRPar", "0.641 0.212 1.000"
+		case Star:
+			p_name, p_color = "Star", "0.641 0.212 1.000"
+		case gopapageno.TokenTerm:
+			p_name, p_color = "__TERM__", "0.641 0.212 1.000"
+		}
+
+		switch t.Type {
+		case CONCATENATION_SIMPLE_RE:
+			t_name, t_color = "CONCATENATION_SIMPLE_RE", "0.408 0.498 1.000"
+		case RE:
+			t_name, t_color = "RE", "0.408 0.498 1.000"
+		case SET_ITEMS:
+			t_name, t_color = "SET_ITEMS", "0.408 0.498 1.000"
+		case SIMPLE_RE:
+			t_name, t_color = "SIMPLE_RE", "0.408 0.498 1.000"
+		case UNION:
+			t_name, t_color = "UNION", "0.408 0.498 1.000"
+		case gopapageno.TokenEmpty:
+			t_name, t_color = "__EMPTY__", "0.408 0.498 1.000"
+		case Any:
+			t_name, t_color = "Any", "0.641 0.212 1.000"
+		case Caret:
+			t_name, t_color = "Caret", "0.641 0.212 1.000"
+		case Char:
+			t_name, t_color = "Char", "0.641 0.212 1.000"
+		case CharInSet:
+			t_name, t_color = "CharInSet", "0.641 0.212 1.000"
+		case Dash:
+			t_name, t_color = "Dash", "0.641 0.212 1.000"
+		case LPar:
+			t_name, t_color = "LPar", "0.641 0.212 1.000"
+		case Pipe:
+			t_name, t_color = "Pipe", "0.641 0.212 1.000"
+		case Plus:
+			t_name, t_color = "Plus", "0.641 0.212 1.000"
+		case RPar:
+			t_name, t_color = "RPar", "0.641 0.212 1.000"
+		case SquareLPar:
+			t_name, t_color = "SquareLPar", "0.641 0.212 1.000"
+		case SquareRPar:
+			t_name, t_color = "SquareRPar", "0.641 0.212 1.000"
+		case Star:
+			t_name, t_color = "Star", "0.641 0.212 1.000"
+		case gopapageno.TokenTerm:
+			t_name, t_color = "__TERM__", "0.641 0.212 1.000"
+		}
+
+		sb.WriteString(fmt.Sprintf("\"%p\" -> \"%p\";\n", p, t))
+		sb.WriteString(fmt.Sprintf("\"%p\" [label=\"%s\" color=\"%s\"];\n", p, p_name, p_color))
+		sb.WriteString(fmt.Sprintf("\"%p\" [label=\"%s\" color=\"%s\"];\n", t, t_name, t_color))
+
+		graphPrintRec(t.Child, t, sb, i+1)
+		graphPrintRec(t.Next, p, sb, i)
+	}
+	graphPrintRec(root, nil, &sb, 0)
+	sb.WriteString("}\n")
+
+	fmt.Fprint(f, sb.String())
+}
+
+
+func SprintToken[ValueType any](root *gopapageno.Token) string {
+	var sprintRec func(t *gopapageno.Token, sb *strings.Builder, indent string)
+
+	sprintRec = func(t *gopapageno.Token, sb *strings.Builder, indent string) {
+		if t == nil {
+			return
+		}
+
+		sb.WriteString(indent)
+		if t.Next == nil {
+			sb.WriteString("└── ")
+			indent += "    "
+		} else {
+			sb.WriteString("├── ")
+			indent += "|   "
+		}
+
+		switch t.Type {
+		case CONCATENATION_SIMPLE_RE:
+			sb.WriteString("CONCATENATION_SIMPLE_RE")
+		case RE:
+			sb.WriteString("RE")
+		case SET_ITEMS:
+			sb.WriteString("SET_ITEMS")
+		case SIMPLE_RE:
+			sb.WriteString("SIMPLE_RE")
+		case UNION:
+			sb.WriteString("UNION")
+		case gopapageno.TokenEmpty:
+			sb.WriteString("Empty")
+		case Any:
+			sb.WriteString("Any")
+		case Caret:
+			sb.WriteString("Caret")
+		case Char:
+			sb.WriteString("Char")
+		case CharInSet:
+			sb.WriteString("CharInSet")
+		case Dash:
+			sb.WriteString("Dash")
+		case LPar:
+			sb.WriteString("LPar")
+		case Pipe:
+			sb.WriteString("Pipe")
+		case Plus:
+			sb.WriteString("Plus")
+		case RPar:
+			sb.WriteString("RPar")
+		case SquareLPar:
+			sb.WriteString("SquareLPar")
+		case SquareRPar:
+			sb.WriteString("SquareRPar")
+		case Star:
+			sb.WriteString("Star")
+		case gopapageno.TokenTerm:
+			sb.WriteString("Term")
+		default:
+			sb.WriteString("Unknown")
+		}
+
+		if t.Value != nil {
+			if v, ok := any(t.Value).(*ValueType); ok {
+				sb.WriteString(fmt.Sprintf(": %v", *v))
+			} else {
+				sb.WriteString(fmt.Sprintf("%v", v))
+			}
+		}
+		
+		sb.WriteString("\n")
+		
+		sprintRec(t.Child, sb, indent)
+		sprintRec(t.Next, sb, indent[:len(indent)-4])
+	}
+
+	var sb strings.Builder
+	
+	sprintRec(root, &sb, "")
+	
+	return sb.String()
+}
+
+func NewGrammar() *gopapageno.Grammar {
 	numTerminals := uint16(13)
 	numNonTerminals := uint16(6)
 
 	maxRHSLen := 6
 	rules := []gopapageno.Rule{
-		{NEW_AXIOM, []gopapageno.TokenType{CONCATENATION_RE_SIMPLE_RE}},
-		{RE_UNION, []gopapageno.TokenType{CONCATENATION_RE_SIMPLE_RE, Pipe, CONCATENATION_RE_SIMPLE_RE}},
-		{RE_UNION, []gopapageno.TokenType{CONCATENATION_RE_SIMPLE_RE, Pipe, RE_SIMPLE_RE}},
-		{NEW_AXIOM, []gopapageno.TokenType{RE_SIMPLE_RE}},
-		{RE_UNION, []gopapageno.TokenType{RE_SIMPLE_RE, Pipe, CONCATENATION_RE_SIMPLE_RE}},
-		{RE_UNION, []gopapageno.TokenType{RE_SIMPLE_RE, Pipe, RE_SIMPLE_RE}},
-		{NEW_AXIOM, []gopapageno.TokenType{RE_UNION}},
-		{RE_UNION, []gopapageno.TokenType{RE_UNION, Pipe, CONCATENATION_RE_SIMPLE_RE}},
-		{RE_UNION, []gopapageno.TokenType{RE_UNION, Pipe, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{Any}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{Any, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{Any, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{Any, Plus}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{Any, Plus, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{Any, Plus, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{Any, Star}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{Any, Star, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{Any, Star, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{Char}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{Char, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{Char, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{Char, Plus}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{Char, Plus, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{Char, Plus, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{Char, Star}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{Char, Star, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{Char, Star, RE_SIMPLE_RE}},
-		{SET_ITEMS, []gopapageno.TokenType{CharInSet}},
-		{SET_ITEMS, []gopapageno.TokenType{CharInSet, SET_ITEMS}},
-		{SET_ITEMS, []gopapageno.TokenType{CharInSet, Dash, CharInSet}},
-		{SET_ITEMS, []gopapageno.TokenType{CharInSet, Dash, CharInSet, SET_ITEMS}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{LPar, CONCATENATION_RE_SIMPLE_RE, RPar}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, CONCATENATION_RE_SIMPLE_RE, RPar, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, CONCATENATION_RE_SIMPLE_RE, RPar, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{LPar, CONCATENATION_RE_SIMPLE_RE, RPar, Plus}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, CONCATENATION_RE_SIMPLE_RE, RPar, Plus, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, CONCATENATION_RE_SIMPLE_RE, RPar, Plus, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{LPar, CONCATENATION_RE_SIMPLE_RE, RPar, Star}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, CONCATENATION_RE_SIMPLE_RE, RPar, Star, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, CONCATENATION_RE_SIMPLE_RE, RPar, Star, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_SIMPLE_RE, RPar}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_SIMPLE_RE, RPar, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_SIMPLE_RE, RPar, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_SIMPLE_RE, RPar, Plus}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_SIMPLE_RE, RPar, Plus, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_SIMPLE_RE, RPar, Plus, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_SIMPLE_RE, RPar, Star}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_SIMPLE_RE, RPar, Star, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_SIMPLE_RE, RPar, Star, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_UNION, RPar}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_UNION, RPar, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_UNION, RPar, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_UNION, RPar, Plus}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_UNION, RPar, Plus, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_UNION, RPar, Plus, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_UNION, RPar, Star}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_UNION, RPar, Star, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{LPar, RE_UNION, RPar, Star, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, Plus}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, Plus, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, Plus, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, Star}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, Star, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, Star, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, Plus}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, Plus, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, Plus, RE_SIMPLE_RE}},
-		{RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, Star}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, Star, CONCATENATION_RE_SIMPLE_RE}},
-		{CONCATENATION_RE_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, Star, RE_SIMPLE_RE}},
+		{RE, []gopapageno.TokenType{CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{UNION, []gopapageno.TokenType{RE, Pipe, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{UNION, []gopapageno.TokenType{RE, Pipe, SIMPLE_RE}, gopapageno.RuleSimple},
+		{RE, []gopapageno.TokenType{SIMPLE_RE}, gopapageno.RuleSimple},
+		{RE, []gopapageno.TokenType{UNION}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{Any}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{Any, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{Any, SIMPLE_RE}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{Any, Plus}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{Any, Plus, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{Any, Plus, SIMPLE_RE}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{Any, Star}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{Any, Star, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{Any, Star, SIMPLE_RE}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{Char}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{Char, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{Char, SIMPLE_RE}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{Char, Plus}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{Char, Plus, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{Char, Plus, SIMPLE_RE}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{Char, Star}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{Char, Star, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{Char, Star, SIMPLE_RE}, gopapageno.RuleSimple},
+		{SET_ITEMS, []gopapageno.TokenType{CharInSet}, gopapageno.RuleSimple},
+		{SET_ITEMS, []gopapageno.TokenType{CharInSet, SET_ITEMS}, gopapageno.RuleSimple},
+		{SET_ITEMS, []gopapageno.TokenType{CharInSet, Dash, CharInSet}, gopapageno.RuleSimple},
+		{SET_ITEMS, []gopapageno.TokenType{CharInSet, Dash, CharInSet, SET_ITEMS}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{LPar, RE, RPar}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{LPar, RE, RPar, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{LPar, RE, RPar, SIMPLE_RE}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{LPar, RE, RPar, Plus}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{LPar, RE, RPar, Plus, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{LPar, RE, RPar, Plus, SIMPLE_RE}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{LPar, RE, RPar, Star}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{LPar, RE, RPar, Star, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{LPar, RE, RPar, Star, SIMPLE_RE}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, SIMPLE_RE}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, Plus}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, Plus, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, Plus, SIMPLE_RE}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, Star}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, Star, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, SET_ITEMS, SquareRPar, Star, SIMPLE_RE}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, SIMPLE_RE}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, Plus}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, Plus, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, Plus, SIMPLE_RE}, gopapageno.RuleSimple},
+		{SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, Star}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, Star, CONCATENATION_SIMPLE_RE}, gopapageno.RuleSimple},
+		{CONCATENATION_SIMPLE_RE, []gopapageno.TokenType{SquareLPar, Caret, SET_ITEMS, SquareRPar, Star, SIMPLE_RE}, gopapageno.RuleSimple},
 	}
-	compressedRules := []uint16{0, 0, 8, 1, 19, 3, 37, 4, 55, 32769, 73, 32771, 116, 32772, 159, 32774, 182, 32778, 335, 2, 0, 1, 32775, 24, 0, 0, 2, 1, 31, 3, 34, 4, 1, 0, 4, 2, 0, 2, 3, 1, 32775, 42, 0, 0, 2, 1, 49, 3, 52, 4, 4, 0, 4, 5, 0, 2, 6, 1, 32775, 60, 0, 0, 2, 1, 67, 3, 70, 4, 7, 0, 4, 8, 0, 3, 9, 4, 1, 84, 3, 87, 32776, 90, 32780, 103, 1, 10, 0, 1, 11, 0, 3, 12, 2, 1, 97, 3, 100, 1, 13, 0, 1, 14, 0, 3, 15, 2, 1, 110, 3, 113, 1, 16, 0, 1, 17, 0, 3, 18, 4, 1, 127, 3, 130, 32776, 133, 32780, 146, 1, 19, 0, 1, 20, 0, 3, 21, 2, 1, 140, 3, 143, 1, 22, 0, 1, 23, 0, 3, 24, 2, 1, 153, 3, 156, 1, 25, 0, 1, 26, 0, 5, 27, 2, 5, 166, 32773, 169, 5, 28, 0, 0, 0, 1, 32772, 174, 5, 29, 1, 5, 179, 5, 30, 0, 0, 0, 3, 1, 191, 3, 239, 4, 287, 0, 0, 1, 32777, 196, 3, 31, 4, 1, 207, 3, 210, 32776, 213, 32780, 226, 1, 32, 0, 1, 33, 0, 3, 34, 2, 1, 220, 3, 223, 1, 35, 0, 1, 36, 0, 3, 37, 2, 1, 233, 3, 236, 1, 38, 0, 1, 39, 0, 0, 0, 1, 32777, 244, 3, 40, 4, 1, 255, 3, 258, 32776, 261, 32780, 274, 1, 41, 0, 1, 42, 0, 3, 43, 2, 1, 268, 3, 271, 1, 44, 0, 1, 45, 0, 3, 46, 2, 1, 281, 3, 284, 1, 47, 0, 1, 48, 0, 0, 0, 1, 32777, 292, 3, 49, 4, 1, 303, 3, 306, 32776, 309, 32780, 322, 1, 50, 0, 1, 51, 0, 3, 52, 2, 1, 316, 3, 319, 1, 53, 0, 1, 54, 0, 3, 55, 2, 1, 329, 3, 332, 1, 56, 0, 1, 57, 0, 0, 0, 2, 5, 342, 32770, 390, 0, 0, 1, 32779, 347, 3, 58, 4, 1, 358, 3, 361, 32776, 364, 32780, 377, 1, 59, 0, 1, 60, 0, 3, 61, 2, 1, 371, 3, 374, 1, 62, 0, 1, 63, 0, 3, 64, 2, 1, 384, 3, 387, 1, 65, 0, 1, 66, 0, 0, 0, 1, 5, 395, 0, 0, 1, 32779, 400, 3, 67, 4, 1, 411, 3, 414, 32776, 417, 32780, 430, 1, 68, 0, 1, 69, 0, 3, 70, 2, 1, 424, 3, 427, 1, 71, 0, 1, 72, 0, 3, 73, 2, 1, 437, 3, 440, 1, 74, 0, 1, 75, 0}
+	compressedRules := []uint16{0, 0, 9, 1, 21, 2, 24, 4, 42, 5, 45, 32769, 48, 32771, 91, 32772, 134, 32774, 157, 32778, 210, 2, 0, 0, 0, 0, 1, 32775, 29, 0, 0, 2, 1, 36, 4, 39, 5, 1, 0, 5, 2, 0, 2, 3, 0, 2, 4, 0, 4, 5, 4, 1, 59, 4, 62, 32776, 65, 32780, 78, 1, 6, 0, 1, 7, 0, 4, 8, 2, 1, 72, 4, 75, 1, 9, 0, 1, 10, 0, 4, 11, 2, 1, 85, 4, 88, 1, 12, 0, 1, 13, 0, 4, 14, 4, 1, 102, 4, 105, 32776, 108, 32780, 121, 1, 15, 0, 1, 16, 0, 4, 17, 2, 1, 115, 4, 118, 1, 18, 0, 1, 19, 0, 4, 20, 2, 1, 128, 4, 131, 1, 21, 0, 1, 22, 0, 3, 23, 2, 3, 141, 32773, 144, 3, 24, 0, 0, 0, 1, 32772, 149, 3, 25, 1, 3, 154, 3, 26, 0, 0, 0, 1, 2, 162, 0, 0, 1, 32777, 167, 4, 27, 4, 1, 178, 4, 181, 32776, 184, 32780, 197, 1, 28, 0, 1, 29, 0, 4, 30, 2, 1, 191, 4, 194, 1, 31, 0, 1, 32, 0, 4, 33, 2, 1, 204, 4, 207, 1, 34, 0, 1, 35, 0, 0, 0, 2, 3, 217, 32770, 265, 0, 0, 1, 32779, 222, 4, 36, 4, 1, 233, 4, 236, 32776, 239, 32780, 252, 1, 37, 0, 1, 38, 0, 4, 39, 2, 1, 246, 4, 249, 1, 40, 0, 1, 41, 0, 4, 42, 2, 1, 259, 4, 262, 1, 43, 0, 1, 44, 0, 0, 0, 1, 3, 270, 0, 0, 1, 32779, 275, 4, 45, 4, 1, 286, 4, 289, 32776, 292, 32780, 305, 1, 46, 0, 1, 47, 0, 4, 48, 2, 1, 299, 4, 302, 1, 49, 0, 1, 50, 0, 4, 51, 2, 1, 312, 4, 315, 1, 52, 0, 1, 53, 0	}
 
 	precMatrix := [][]gopapageno.Precedence{
 		{gopapageno.PrecEquals, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields},
-		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecTakes, gopapageno.PrecEquals, gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEquals},
-		{gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEquals, gopapageno.PrecEmpty},
-		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecTakes, gopapageno.PrecEquals, gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEquals},
-		{gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEquals, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecTakes, gopapageno.PrecEmpty},
-		{gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEquals, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty},
-		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEquals, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty},
 		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty},
+		{gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEquals, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEquals, gopapageno.PrecEquals},
+		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty},
+		{gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEquals, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecTakes, gopapageno.PrecEmpty},
+		{gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty},
+		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecEquals, gopapageno.PrecEquals, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEquals},
+		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty},
+		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty},
+		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty},
+		{gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEquals, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEquals, gopapageno.PrecEquals},
 		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty},
-		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecTakes, gopapageno.PrecEquals, gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEquals},
-		{gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecEquals, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecEquals, gopapageno.PrecEmpty},
-		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecTakes, gopapageno.PrecEquals, gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEquals},
 		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty, gopapageno.PrecYields, gopapageno.PrecTakes, gopapageno.PrecEmpty, gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecEmpty, gopapageno.PrecEmpty},
 	}
 	bitPackedMatrix := []uint64{
-		14980984727966580737, 13831960122815723519, 14752790669143570427, 2755301147185181411, 3635077580152884711, 248719,
+		1162036735599007060, 9223374262252961792, 1261294886989070344, 7079966582981920292, 5045760090095751200, 268432, 
 	}
 
-	fn := func(rule uint16, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int) {
-		switch rule {
+	tokenNames := map[gopapageno.TokenType]string{
+		CONCATENATION_SIMPLE_RE: "CONCATENATION_SIMPLE_RE",
+		RE: "RE",
+		SET_ITEMS: "SET_ITEMS",
+		SIMPLE_RE: "SIMPLE_RE",
+		UNION: "UNION",
+		Any: "Any",
+		Caret: "Caret",
+		Char: "Char",
+		CharInSet: "CharInSet",
+		Dash: "Dash",
+		LPar: "LPar",
+		Pipe: "Pipe",
+		Plus: "Plus",
+		RPar: "RPar",
+		SquareLPar: "SquareLPar",
+		SquareRPar: "SquareRPar",
+		Star: "Star",
+	}
+
+	syncTerminals := []gopapageno.TokenType{}
+
+	fn := func(ruleDescription uint16, ruleFlags gopapageno.RuleFlags, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int, runState any){
+		switch ruleDescription {
 		case 0:
-			NEW_AXIOM0 := lhs
-			CONCATENATION_RE_SIMPLE_RE1 := rhs[0]
+			RE0 := lhs
+			CONCATENATION_SIMPLE_RE1 := rhs[0]
 
-			NEW_AXIOM0.Child = CONCATENATION_RE_SIMPLE_RE1
+			RE0.Child = CONCATENATION_SIMPLE_RE1
+			RE0.LastChild = CONCATENATION_SIMPLE_RE1
 
 			{
-				NEW_AXIOM0.Value = CONCATENATION_RE_SIMPLE_RE1.Value
+				RE0.Value = CONCATENATION_SIMPLE_RE1.Value
 			}
+			_ = CONCATENATION_SIMPLE_RE1
 		case 1:
-			RE_UNION0 := lhs
-			CONCATENATION_RE_SIMPLE_RE1 := rhs[0]
+			UNION0 := lhs
+			RE1 := rhs[0]
 			Pipe2 := rhs[1]
-			CONCATENATION_RE_SIMPLE_RE3 := rhs[2]
+			CONCATENATION_SIMPLE_RE3 := rhs[2]
 
-			RE_UNION0.Child = CONCATENATION_RE_SIMPLE_RE1
-			CONCATENATION_RE_SIMPLE_RE1.Next = Pipe2
-			Pipe2.Next = CONCATENATION_RE_SIMPLE_RE3
+			UNION0.Child = RE1
+			RE1.Next = Pipe2
+			Pipe2.Next = CONCATENATION_SIMPLE_RE3
+			UNION0.LastChild = CONCATENATION_SIMPLE_RE3
 
 			{
-				leftNfa := CONCATENATION_RE_SIMPLE_RE1.Value.(*Nfa)
-				rightNfa := CONCATENATION_RE_SIMPLE_RE3.Value.(*Nfa)
-
+				leftNfa := RE1.Value.(*Nfa)
+				rightNfa := CONCATENATION_SIMPLE_RE3.Value.(*Nfa)
+				
 				leftNfa.Unite(rightNfa)
-
-				RE_UNION0.Value = leftNfa
+				
+				UNION0.Value = leftNfa
 			}
+			_ = RE1
+			_ = Pipe2
+			_ = CONCATENATION_SIMPLE_RE3
 		case 2:
-			RE_UNION0 := lhs
-			CONCATENATION_RE_SIMPLE_RE1 := rhs[0]
+			UNION0 := lhs
+			RE1 := rhs[0]
 			Pipe2 := rhs[1]
-			RE_SIMPLE_RE3 := rhs[2]
+			SIMPLE_RE3 := rhs[2]
 
-			RE_UNION0.Child = CONCATENATION_RE_SIMPLE_RE1
-			CONCATENATION_RE_SIMPLE_RE1.Next = Pipe2
-			Pipe2.Next = RE_SIMPLE_RE3
+			UNION0.Child = RE1
+			RE1.Next = Pipe2
+			Pipe2.Next = SIMPLE_RE3
+			UNION0.LastChild = SIMPLE_RE3
 
 			{
-				leftNfa := CONCATENATION_RE_SIMPLE_RE1.Value.(*Nfa)
-				rightNfa := RE_SIMPLE_RE3.Value.(*Nfa)
-
+				leftNfa := RE1.Value.(*Nfa)
+				rightNfa := SIMPLE_RE3.Value.(*Nfa)
+				
 				leftNfa.Unite(rightNfa)
-
-				RE_UNION0.Value = leftNfa
+				
+				UNION0.Value = leftNfa
 			}
+			_ = RE1
+			_ = Pipe2
+			_ = SIMPLE_RE3
 		case 3:
-			NEW_AXIOM0 := lhs
-			RE_SIMPLE_RE1 := rhs[0]
+			RE0 := lhs
+			SIMPLE_RE1 := rhs[0]
 
-			NEW_AXIOM0.Child = RE_SIMPLE_RE1
+			RE0.Child = SIMPLE_RE1
+			RE0.LastChild = SIMPLE_RE1
 
 			{
-				NEW_AXIOM0.Value = RE_SIMPLE_RE1.Value
+				RE0.Value = SIMPLE_RE1.Value
 			}
+			_ = SIMPLE_RE1
 		case 4:
-			RE_UNION0 := lhs
-			RE_SIMPLE_RE1 := rhs[0]
-			Pipe2 := rhs[1]
-			CONCATENATION_RE_SIMPLE_RE3 := rhs[2]
+			RE0 := lhs
+			UNION1 := rhs[0]
 
-			RE_UNION0.Child = RE_SIMPLE_RE1
-			RE_SIMPLE_RE1.Next = Pipe2
-			Pipe2.Next = CONCATENATION_RE_SIMPLE_RE3
+			RE0.Child = UNION1
+			RE0.LastChild = UNION1
 
 			{
-				leftNfa := RE_SIMPLE_RE1.Value.(*Nfa)
-				rightNfa := CONCATENATION_RE_SIMPLE_RE3.Value.(*Nfa)
-
-				leftNfa.Unite(rightNfa)
-
-				RE_UNION0.Value = leftNfa
+				RE0.Value = UNION1.Value
 			}
+			_ = UNION1
 		case 5:
-			RE_UNION0 := lhs
-			RE_SIMPLE_RE1 := rhs[0]
-			Pipe2 := rhs[1]
-			RE_SIMPLE_RE3 := rhs[2]
-
-			RE_UNION0.Child = RE_SIMPLE_RE1
-			RE_SIMPLE_RE1.Next = Pipe2
-			Pipe2.Next = RE_SIMPLE_RE3
-
-			{
-				leftNfa := RE_SIMPLE_RE1.Value.(*Nfa)
-				rightNfa := RE_SIMPLE_RE3.Value.(*Nfa)
-
-				leftNfa.Unite(rightNfa)
-
-				RE_UNION0.Value = leftNfa
-			}
-		case 6:
-			NEW_AXIOM0 := lhs
-			RE_UNION1 := rhs[0]
-
-			NEW_AXIOM0.Child = RE_UNION1
-
-			{
-				NEW_AXIOM0.Value = RE_UNION1.Value
-			}
-		case 7:
-			RE_UNION0 := lhs
-			RE_UNION1 := rhs[0]
-			Pipe2 := rhs[1]
-			CONCATENATION_RE_SIMPLE_RE3 := rhs[2]
-
-			RE_UNION0.Child = RE_UNION1
-			RE_UNION1.Next = Pipe2
-			Pipe2.Next = CONCATENATION_RE_SIMPLE_RE3
-
-			{
-				leftNfa := RE_UNION1.Value.(*Nfa)
-				rightNfa := CONCATENATION_RE_SIMPLE_RE3.Value.(*Nfa)
-
-				leftNfa.Unite(rightNfa)
-
-				RE_UNION0.Value = leftNfa
-			}
-		case 8:
-			RE_UNION0 := lhs
-			RE_UNION1 := rhs[0]
-			Pipe2 := rhs[1]
-			RE_SIMPLE_RE3 := rhs[2]
-
-			RE_UNION0.Child = RE_UNION1
-			RE_UNION1.Next = Pipe2
-			Pipe2.Next = RE_SIMPLE_RE3
-
-			{
-				leftNfa := RE_UNION1.Value.(*Nfa)
-				rightNfa := RE_SIMPLE_RE3.Value.(*Nfa)
-
-				leftNfa.Unite(rightNfa)
-
-				RE_UNION0.Value = leftNfa
-			}
-		case 9:
-			RE_SIMPLE_RE0 := lhs
+			SIMPLE_RE0 := lhs
 			Any1 := rhs[0]
 
-			RE_SIMPLE_RE0.Child = Any1
+			SIMPLE_RE0.Child = Any1
+			SIMPLE_RE0.LastChild = Any1
 
 			{
-				RE_SIMPLE_RE0.Value = Any1.Value
+				SIMPLE_RE0.Value = Any1.Value
 			}
-		case 10:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = Any1
+		case 6:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			Any1 := rhs[0]
-			CONCATENATION_RE_SIMPLE_RE2 := rhs[1]
+			CONCATENATION_SIMPLE_RE2 := rhs[1]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = Any1
-			Any1.Next = CONCATENATION_RE_SIMPLE_RE2
+			CONCATENATION_SIMPLE_RE0.Child = Any1
+			Any1.Next = CONCATENATION_SIMPLE_RE2
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE2
 
 			{
 				leftNfa := Any1.Value.(*Nfa)
-				rightNfa := CONCATENATION_RE_SIMPLE_RE2.Value.(*Nfa)
-
+				rightNfa := CONCATENATION_SIMPLE_RE2.Value.(*Nfa)
+				
 				leftNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = leftNfa
+				
+				CONCATENATION_SIMPLE_RE0.Value = leftNfa
 			}
-		case 11:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = Any1
+			_ = CONCATENATION_SIMPLE_RE2
+		case 7:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			Any1 := rhs[0]
-			RE_SIMPLE_RE2 := rhs[1]
+			SIMPLE_RE2 := rhs[1]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = Any1
-			Any1.Next = RE_SIMPLE_RE2
+			CONCATENATION_SIMPLE_RE0.Child = Any1
+			Any1.Next = SIMPLE_RE2
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE2
 
 			{
 				leftNfa := Any1.Value.(*Nfa)
-				rightNfa := RE_SIMPLE_RE2.Value.(*Nfa)
-
+				rightNfa := SIMPLE_RE2.Value.(*Nfa)
+				
 				leftNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = leftNfa
+				
+				CONCATENATION_SIMPLE_RE0.Value = leftNfa
 			}
-		case 12:
-			RE_SIMPLE_RE0 := lhs
+			_ = Any1
+			_ = SIMPLE_RE2
+		case 8:
+			SIMPLE_RE0 := lhs
 			Any1 := rhs[0]
 			Plus2 := rhs[1]
 
-			RE_SIMPLE_RE0.Child = Any1
+			SIMPLE_RE0.Child = Any1
 			Any1.Next = Plus2
+			SIMPLE_RE0.LastChild = Plus2
 
 			{
 				nfaAny := Any1.Value.(*Nfa)
 				nfaAny.KleenePlus()
-
-				RE_SIMPLE_RE0.Value = nfaAny
+				
+				SIMPLE_RE0.Value = nfaAny
 			}
-		case 13:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = Any1
+			_ = Plus2
+		case 9:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			Any1 := rhs[0]
 			Plus2 := rhs[1]
-			CONCATENATION_RE_SIMPLE_RE3 := rhs[2]
+			CONCATENATION_SIMPLE_RE3 := rhs[2]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = Any1
+			CONCATENATION_SIMPLE_RE0.Child = Any1
 			Any1.Next = Plus2
-			Plus2.Next = CONCATENATION_RE_SIMPLE_RE3
+			Plus2.Next = CONCATENATION_SIMPLE_RE3
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE3
 
 			{
 				nfaAny := Any1.Value.(*Nfa)
 				nfaAny.KleenePlus()
-				rightNfa := CONCATENATION_RE_SIMPLE_RE3.Value.(*Nfa)
-
+				rightNfa := CONCATENATION_SIMPLE_RE3.Value.(*Nfa)
+				
 				nfaAny.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaAny
+				
+				CONCATENATION_SIMPLE_RE0.Value = nfaAny
 			}
-		case 14:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = Any1
+			_ = Plus2
+			_ = CONCATENATION_SIMPLE_RE3
+		case 10:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			Any1 := rhs[0]
 			Plus2 := rhs[1]
-			RE_SIMPLE_RE3 := rhs[2]
+			SIMPLE_RE3 := rhs[2]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = Any1
+			CONCATENATION_SIMPLE_RE0.Child = Any1
 			Any1.Next = Plus2
-			Plus2.Next = RE_SIMPLE_RE3
+			Plus2.Next = SIMPLE_RE3
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE3
 
 			{
 				nfaAny := Any1.Value.(*Nfa)
 				nfaAny.KleenePlus()
-				rightNfa := RE_SIMPLE_RE3.Value.(*Nfa)
-
+				rightNfa := SIMPLE_RE3.Value.(*Nfa)
+				
 				nfaAny.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaAny
+				
+				CONCATENATION_SIMPLE_RE0.Value = nfaAny
 			}
-		case 15:
-			RE_SIMPLE_RE0 := lhs
+			_ = Any1
+			_ = Plus2
+			_ = SIMPLE_RE3
+		case 11:
+			SIMPLE_RE0 := lhs
 			Any1 := rhs[0]
 			Star2 := rhs[1]
 
-			RE_SIMPLE_RE0.Child = Any1
+			SIMPLE_RE0.Child = Any1
 			Any1.Next = Star2
+			SIMPLE_RE0.LastChild = Star2
 
 			{
 				nfaAny := Any1.Value.(*Nfa)
 				nfaAny.KleeneStar()
-
-				RE_SIMPLE_RE0.Value = nfaAny
+				
+				SIMPLE_RE0.Value = nfaAny
 			}
-		case 16:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = Any1
+			_ = Star2
+		case 12:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			Any1 := rhs[0]
 			Star2 := rhs[1]
-			CONCATENATION_RE_SIMPLE_RE3 := rhs[2]
+			CONCATENATION_SIMPLE_RE3 := rhs[2]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = Any1
+			CONCATENATION_SIMPLE_RE0.Child = Any1
 			Any1.Next = Star2
-			Star2.Next = CONCATENATION_RE_SIMPLE_RE3
+			Star2.Next = CONCATENATION_SIMPLE_RE3
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE3
 
 			{
 				nfaAny := Any1.Value.(*Nfa)
 				nfaAny.KleeneStar()
-				rightNfa := CONCATENATION_RE_SIMPLE_RE3.Value.(*Nfa)
-
+				rightNfa := CONCATENATION_SIMPLE_RE3.Value.(*Nfa)
+				
 				nfaAny.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaAny
+				
+				CONCATENATION_SIMPLE_RE0.Value = nfaAny
 			}
-		case 17:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = Any1
+			_ = Star2
+			_ = CONCATENATION_SIMPLE_RE3
+		case 13:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			Any1 := rhs[0]
 			Star2 := rhs[1]
-			RE_SIMPLE_RE3 := rhs[2]
+			SIMPLE_RE3 := rhs[2]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = Any1
+			CONCATENATION_SIMPLE_RE0.Child = Any1
 			Any1.Next = Star2
-			Star2.Next = RE_SIMPLE_RE3
+			Star2.Next = SIMPLE_RE3
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE3
 
 			{
 				nfaAny := Any1.Value.(*Nfa)
 				nfaAny.KleeneStar()
-				rightNfa := RE_SIMPLE_RE3.Value.(*Nfa)
-
+				rightNfa := SIMPLE_RE3.Value.(*Nfa)
+				
 				nfaAny.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaAny
+				
+				CONCATENATION_SIMPLE_RE0.Value = nfaAny
 			}
-		case 18:
-			RE_SIMPLE_RE0 := lhs
+			_ = Any1
+			_ = Star2
+			_ = SIMPLE_RE3
+		case 14:
+			SIMPLE_RE0 := lhs
 			Char1 := rhs[0]
 
-			RE_SIMPLE_RE0.Child = Char1
+			SIMPLE_RE0.Child = Char1
+			SIMPLE_RE0.LastChild = Char1
 
 			{
 				newNfa := newNfaFromChar(Char1.Value.(byte))
-
-				RE_SIMPLE_RE0.Value = &newNfa
+				
+				SIMPLE_RE0.Value = &newNfa
 			}
-		case 19:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = Char1
+		case 15:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			Char1 := rhs[0]
-			CONCATENATION_RE_SIMPLE_RE2 := rhs[1]
+			CONCATENATION_SIMPLE_RE2 := rhs[1]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = Char1
-			Char1.Next = CONCATENATION_RE_SIMPLE_RE2
+			CONCATENATION_SIMPLE_RE0.Child = Char1
+			Char1.Next = CONCATENATION_SIMPLE_RE2
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE2
 
 			{
 				newNfa := newNfaFromChar(Char1.Value.(byte))
-				rightNfa := CONCATENATION_RE_SIMPLE_RE2.Value.(*Nfa)
-
+				rightNfa := CONCATENATION_SIMPLE_RE2.Value.(*Nfa)
+			
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
 			}
-		case 20:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = Char1
+			_ = CONCATENATION_SIMPLE_RE2
+		case 16:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			Char1 := rhs[0]
-			RE_SIMPLE_RE2 := rhs[1]
+			SIMPLE_RE2 := rhs[1]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = Char1
-			Char1.Next = RE_SIMPLE_RE2
+			CONCATENATION_SIMPLE_RE0.Child = Char1
+			Char1.Next = SIMPLE_RE2
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE2
 
 			{
 				newNfa := newNfaFromChar(Char1.Value.(byte))
-				rightNfa := RE_SIMPLE_RE2.Value.(*Nfa)
-
+				rightNfa := SIMPLE_RE2.Value.(*Nfa)
+			
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
 			}
-		case 21:
-			RE_SIMPLE_RE0 := lhs
+			_ = Char1
+			_ = SIMPLE_RE2
+		case 17:
+			SIMPLE_RE0 := lhs
 			Char1 := rhs[0]
 			Plus2 := rhs[1]
 
-			RE_SIMPLE_RE0.Child = Char1
+			SIMPLE_RE0.Child = Char1
 			Char1.Next = Plus2
+			SIMPLE_RE0.LastChild = Plus2
 
 			{
 				newNfa := newNfaFromChar(Char1.Value.(byte))
 				newNfa.KleenePlus()
-
-				RE_SIMPLE_RE0.Value = &newNfa
+				
+				SIMPLE_RE0.Value = &newNfa
 			}
-		case 22:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = Char1
+			_ = Plus2
+		case 18:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			Char1 := rhs[0]
 			Plus2 := rhs[1]
-			CONCATENATION_RE_SIMPLE_RE3 := rhs[2]
+			CONCATENATION_SIMPLE_RE3 := rhs[2]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = Char1
+			CONCATENATION_SIMPLE_RE0.Child = Char1
 			Char1.Next = Plus2
-			Plus2.Next = CONCATENATION_RE_SIMPLE_RE3
+			Plus2.Next = CONCATENATION_SIMPLE_RE3
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE3
 
 			{
 				newNfa := newNfaFromChar(Char1.Value.(byte))
 				newNfa.KleenePlus()
 				rightNfa := Plus2.Value.(*Nfa)
-
+			
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
 			}
-		case 23:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = Char1
+			_ = Plus2
+			_ = CONCATENATION_SIMPLE_RE3
+		case 19:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			Char1 := rhs[0]
 			Plus2 := rhs[1]
-			RE_SIMPLE_RE3 := rhs[2]
+			SIMPLE_RE3 := rhs[2]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = Char1
+			CONCATENATION_SIMPLE_RE0.Child = Char1
 			Char1.Next = Plus2
-			Plus2.Next = RE_SIMPLE_RE3
+			Plus2.Next = SIMPLE_RE3
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE3
 
 			{
 				newNfa := newNfaFromChar(Char1.Value.(byte))
 				newNfa.KleenePlus()
 				rightNfa := Plus2.Value.(*Nfa)
-
+			
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
 			}
-		case 24:
-			RE_SIMPLE_RE0 := lhs
+			_ = Char1
+			_ = Plus2
+			_ = SIMPLE_RE3
+		case 20:
+			SIMPLE_RE0 := lhs
 			Char1 := rhs[0]
 			Star2 := rhs[1]
 
-			RE_SIMPLE_RE0.Child = Char1
+			SIMPLE_RE0.Child = Char1
 			Char1.Next = Star2
+			SIMPLE_RE0.LastChild = Star2
 
 			{
 				newNfa := newNfaFromChar(Char1.Value.(byte))
 				newNfa.KleeneStar()
-
-				RE_SIMPLE_RE0.Value = &newNfa
+				
+				SIMPLE_RE0.Value = &newNfa
 			}
-		case 25:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = Char1
+			_ = Star2
+		case 21:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			Char1 := rhs[0]
 			Star2 := rhs[1]
-			CONCATENATION_RE_SIMPLE_RE3 := rhs[2]
+			CONCATENATION_SIMPLE_RE3 := rhs[2]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = Char1
+			CONCATENATION_SIMPLE_RE0.Child = Char1
 			Char1.Next = Star2
-			Star2.Next = CONCATENATION_RE_SIMPLE_RE3
+			Star2.Next = CONCATENATION_SIMPLE_RE3
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE3
 
 			{
 				newNfa := newNfaFromChar(Char1.Value.(byte))
 				newNfa.KleeneStar()
 				rightNfa := Star2.Value.(*Nfa)
-
+			
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
 			}
-		case 26:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = Char1
+			_ = Star2
+			_ = CONCATENATION_SIMPLE_RE3
+		case 22:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			Char1 := rhs[0]
 			Star2 := rhs[1]
-			RE_SIMPLE_RE3 := rhs[2]
+			SIMPLE_RE3 := rhs[2]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = Char1
+			CONCATENATION_SIMPLE_RE0.Child = Char1
 			Char1.Next = Star2
-			Star2.Next = RE_SIMPLE_RE3
+			Star2.Next = SIMPLE_RE3
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE3
 
 			{
 				newNfa := newNfaFromChar(Char1.Value.(byte))
 				newNfa.KleeneStar()
 				rightNfa := Star2.Value.(*Nfa)
-
+			
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
 			}
-		case 27:
+			_ = Char1
+			_ = Star2
+			_ = SIMPLE_RE3
+		case 23:
 			SET_ITEMS0 := lhs
 			CharInSet1 := rhs[0]
 
 			SET_ITEMS0.Child = CharInSet1
+			SET_ITEMS0.LastChild = CharInSet1
 
 			{
 				var charSet [256]bool
 				charSet[CharInSet1.Value.(byte)] = true
-
+				
 				SET_ITEMS0.Value = charSet
 			}
-		case 28:
+			_ = CharInSet1
+		case 24:
 			SET_ITEMS0 := lhs
 			CharInSet1 := rhs[0]
 			SET_ITEMS2 := rhs[1]
 
 			SET_ITEMS0.Child = CharInSet1
 			CharInSet1.Next = SET_ITEMS2
+			SET_ITEMS0.LastChild = SET_ITEMS2
 
 			{
 				charSet := SET_ITEMS2.Value.([256]bool)
 				charSet[CharInSet1.Value.(byte)] = true
-
+				
 				SET_ITEMS0.Value = charSet
 			}
-		case 29:
+			_ = CharInSet1
+			_ = SET_ITEMS2
+		case 25:
 			SET_ITEMS0 := lhs
 			CharInSet1 := rhs[0]
 			Dash2 := rhs[1]
@@ -596,25 +805,29 @@ func NewParser(opts ...gopapageno.ParserOpt) *gopapageno.Grammar {
 			SET_ITEMS0.Child = CharInSet1
 			CharInSet1.Next = Dash2
 			Dash2.Next = CharInSet3
+			SET_ITEMS0.LastChild = CharInSet3
 
 			{
 				charStart := CharInSet1.Value.(byte)
 				charEnd := CharInSet3.Value.(byte)
-
+				
 				if charStart > charEnd {
 					temp := charStart
 					charStart = charEnd
 					charEnd = temp
 				}
-
+				
 				var charSet [256]bool
 				for i := charStart; i <= charEnd; i++ {
 					charSet[i] = true
 				}
-
+				
 				SET_ITEMS0.Value = charSet
 			}
-		case 30:
+			_ = CharInSet1
+			_ = Dash2
+			_ = CharInSet3
+		case 26:
 			SET_ITEMS0 := lhs
 			CharInSet1 := rhs[0]
 			Dash2 := rhs[1]
@@ -625,1040 +838,830 @@ func NewParser(opts ...gopapageno.ParserOpt) *gopapageno.Grammar {
 			CharInSet1.Next = Dash2
 			Dash2.Next = CharInSet3
 			CharInSet3.Next = SET_ITEMS4
+			SET_ITEMS0.LastChild = SET_ITEMS4
 
 			{
 				charStart := CharInSet1.Value.(byte)
 				charEnd := CharInSet3.Value.(byte)
 				charSet := SET_ITEMS4.Value.([256]bool)
-
+				
 				if charStart > charEnd {
 					temp := charStart
 					charStart = charEnd
 					charEnd = temp
 				}
-
+				
 				for i := charStart; i <= charEnd; i++ {
 					charSet[i] = true
 				}
-
+				
 				SET_ITEMS0.Value = charSet
 			}
-		case 31:
-			RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			CONCATENATION_RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-
-			RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = CONCATENATION_RE_SIMPLE_RE2
-			CONCATENATION_RE_SIMPLE_RE2.Next = RPar3
-
-			{
-				RE_SIMPLE_RE0.Value = CONCATENATION_RE_SIMPLE_RE2.Value
-			}
-		case 32:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			CONCATENATION_RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			CONCATENATION_RE_SIMPLE_RE4 := rhs[3]
-
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = CONCATENATION_RE_SIMPLE_RE2
-			CONCATENATION_RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = CONCATENATION_RE_SIMPLE_RE4
-
-			{
-				nfaEnclosed := CONCATENATION_RE_SIMPLE_RE2.Value.(*Nfa)
-				rightNfa := CONCATENATION_RE_SIMPLE_RE4.Value.(*Nfa)
-
-				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 33:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			CONCATENATION_RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			RE_SIMPLE_RE4 := rhs[3]
-
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = CONCATENATION_RE_SIMPLE_RE2
-			CONCATENATION_RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = RE_SIMPLE_RE4
-
-			{
-				nfaEnclosed := CONCATENATION_RE_SIMPLE_RE2.Value.(*Nfa)
-				rightNfa := RE_SIMPLE_RE4.Value.(*Nfa)
-
-				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 34:
-			RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			CONCATENATION_RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			Plus4 := rhs[3]
-
-			RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = CONCATENATION_RE_SIMPLE_RE2
-			CONCATENATION_RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = Plus4
-
-			{
-				nfaEnclosed := CONCATENATION_RE_SIMPLE_RE2.Value.(*Nfa)
-
-				nfaEnclosed.KleenePlus()
-
-				RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 35:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			CONCATENATION_RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			Plus4 := rhs[3]
-			CONCATENATION_RE_SIMPLE_RE5 := rhs[4]
-
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = CONCATENATION_RE_SIMPLE_RE2
-			CONCATENATION_RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = Plus4
-			Plus4.Next = CONCATENATION_RE_SIMPLE_RE5
-
-			{
-				nfaEnclosed := CONCATENATION_RE_SIMPLE_RE2.Value.(*Nfa)
-				nfaEnclosed.KleenePlus()
-				rightNfa := CONCATENATION_RE_SIMPLE_RE5.Value.(*Nfa)
-
-				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 36:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			CONCATENATION_RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			Plus4 := rhs[3]
-			RE_SIMPLE_RE5 := rhs[4]
-
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = CONCATENATION_RE_SIMPLE_RE2
-			CONCATENATION_RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = Plus4
-			Plus4.Next = RE_SIMPLE_RE5
-
-			{
-				nfaEnclosed := CONCATENATION_RE_SIMPLE_RE2.Value.(*Nfa)
-				nfaEnclosed.KleenePlus()
-				rightNfa := RE_SIMPLE_RE5.Value.(*Nfa)
-
-				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 37:
-			RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			CONCATENATION_RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			Star4 := rhs[3]
-
-			RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = CONCATENATION_RE_SIMPLE_RE2
-			CONCATENATION_RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = Star4
-
-			{
-				nfaEnclosed := CONCATENATION_RE_SIMPLE_RE2.Value.(*Nfa)
-
-				nfaEnclosed.KleeneStar()
-
-				RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 38:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			CONCATENATION_RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			Star4 := rhs[3]
-			CONCATENATION_RE_SIMPLE_RE5 := rhs[4]
-
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = CONCATENATION_RE_SIMPLE_RE2
-			CONCATENATION_RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = Star4
-			Star4.Next = CONCATENATION_RE_SIMPLE_RE5
-
-			{
-				nfaEnclosed := CONCATENATION_RE_SIMPLE_RE2.Value.(*Nfa)
-				nfaEnclosed.KleeneStar()
-				rightNfa := CONCATENATION_RE_SIMPLE_RE5.Value.(*Nfa)
-
-				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 39:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			CONCATENATION_RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			Star4 := rhs[3]
-			RE_SIMPLE_RE5 := rhs[4]
-
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = CONCATENATION_RE_SIMPLE_RE2
-			CONCATENATION_RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = Star4
-			Star4.Next = RE_SIMPLE_RE5
-
-			{
-				nfaEnclosed := CONCATENATION_RE_SIMPLE_RE2.Value.(*Nfa)
-				nfaEnclosed.KleeneStar()
-				rightNfa := RE_SIMPLE_RE5.Value.(*Nfa)
-
-				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 40:
-			RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-
-			RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_SIMPLE_RE2
-			RE_SIMPLE_RE2.Next = RPar3
-
-			{
-				RE_SIMPLE_RE0.Value = RE_SIMPLE_RE2.Value
-			}
-		case 41:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			CONCATENATION_RE_SIMPLE_RE4 := rhs[3]
-
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_SIMPLE_RE2
-			RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = CONCATENATION_RE_SIMPLE_RE4
-
-			{
-				nfaEnclosed := RE_SIMPLE_RE2.Value.(*Nfa)
-				rightNfa := CONCATENATION_RE_SIMPLE_RE4.Value.(*Nfa)
-
-				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 42:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			RE_SIMPLE_RE4 := rhs[3]
-
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_SIMPLE_RE2
-			RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = RE_SIMPLE_RE4
-
-			{
-				nfaEnclosed := RE_SIMPLE_RE2.Value.(*Nfa)
-				rightNfa := RE_SIMPLE_RE4.Value.(*Nfa)
-
-				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 43:
-			RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			Plus4 := rhs[3]
-
-			RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_SIMPLE_RE2
-			RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = Plus4
-
-			{
-				nfaEnclosed := RE_SIMPLE_RE2.Value.(*Nfa)
-
-				nfaEnclosed.KleenePlus()
-
-				RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 44:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			Plus4 := rhs[3]
-			CONCATENATION_RE_SIMPLE_RE5 := rhs[4]
-
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_SIMPLE_RE2
-			RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = Plus4
-			Plus4.Next = CONCATENATION_RE_SIMPLE_RE5
-
-			{
-				nfaEnclosed := RE_SIMPLE_RE2.Value.(*Nfa)
-				nfaEnclosed.KleenePlus()
-				rightNfa := CONCATENATION_RE_SIMPLE_RE5.Value.(*Nfa)
-
-				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 45:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			Plus4 := rhs[3]
-			RE_SIMPLE_RE5 := rhs[4]
-
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_SIMPLE_RE2
-			RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = Plus4
-			Plus4.Next = RE_SIMPLE_RE5
-
-			{
-				nfaEnclosed := RE_SIMPLE_RE2.Value.(*Nfa)
-				nfaEnclosed.KleenePlus()
-				rightNfa := RE_SIMPLE_RE5.Value.(*Nfa)
-
-				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 46:
-			RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			Star4 := rhs[3]
-
-			RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_SIMPLE_RE2
-			RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = Star4
-
-			{
-				nfaEnclosed := RE_SIMPLE_RE2.Value.(*Nfa)
-
-				nfaEnclosed.KleeneStar()
-
-				RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 47:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			Star4 := rhs[3]
-			CONCATENATION_RE_SIMPLE_RE5 := rhs[4]
-
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_SIMPLE_RE2
-			RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = Star4
-			Star4.Next = CONCATENATION_RE_SIMPLE_RE5
-
-			{
-				nfaEnclosed := RE_SIMPLE_RE2.Value.(*Nfa)
-				nfaEnclosed.KleeneStar()
-				rightNfa := CONCATENATION_RE_SIMPLE_RE5.Value.(*Nfa)
-
-				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 48:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
-			LPar1 := rhs[0]
-			RE_SIMPLE_RE2 := rhs[1]
-			RPar3 := rhs[2]
-			Star4 := rhs[3]
-			RE_SIMPLE_RE5 := rhs[4]
-
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_SIMPLE_RE2
-			RE_SIMPLE_RE2.Next = RPar3
-			RPar3.Next = Star4
-			Star4.Next = RE_SIMPLE_RE5
-
-			{
-				nfaEnclosed := RE_SIMPLE_RE2.Value.(*Nfa)
-				nfaEnclosed.KleeneStar()
-				rightNfa := RE_SIMPLE_RE5.Value.(*Nfa)
-
-				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 49:
-			RE_SIMPLE_RE0 := lhs
+			_ = CharInSet1
+			_ = Dash2
+			_ = CharInSet3
+			_ = SET_ITEMS4
+		case 27:
+			SIMPLE_RE0 := lhs
 			LPar1 := rhs[0]
-			RE_UNION2 := rhs[1]
+			RE2 := rhs[1]
 			RPar3 := rhs[2]
 
-			RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_UNION2
-			RE_UNION2.Next = RPar3
+			SIMPLE_RE0.Child = LPar1
+			LPar1.Next = RE2
+			RE2.Next = RPar3
+			SIMPLE_RE0.LastChild = RPar3
 
 			{
-				RE_SIMPLE_RE0.Value = RE_UNION2.Value
+				SIMPLE_RE0.Value = RE2.Value
 			}
-		case 50:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = LPar1
+			_ = RE2
+			_ = RPar3
+		case 28:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			LPar1 := rhs[0]
-			RE_UNION2 := rhs[1]
+			RE2 := rhs[1]
 			RPar3 := rhs[2]
-			CONCATENATION_RE_SIMPLE_RE4 := rhs[3]
+			CONCATENATION_SIMPLE_RE4 := rhs[3]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_UNION2
-			RE_UNION2.Next = RPar3
-			RPar3.Next = CONCATENATION_RE_SIMPLE_RE4
+			CONCATENATION_SIMPLE_RE0.Child = LPar1
+			LPar1.Next = RE2
+			RE2.Next = RPar3
+			RPar3.Next = CONCATENATION_SIMPLE_RE4
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE4
 
 			{
-				nfaEnclosed := RE_UNION2.Value.(*Nfa)
-				rightNfa := CONCATENATION_RE_SIMPLE_RE4.Value.(*Nfa)
-
+				nfaEnclosed := RE2.Value.(*Nfa)
+				rightNfa := CONCATENATION_SIMPLE_RE4.Value.(*Nfa)
+				
 				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
+				
+				CONCATENATION_SIMPLE_RE0.Value = nfaEnclosed
 			}
-		case 51:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = LPar1
+			_ = RE2
+			_ = RPar3
+			_ = CONCATENATION_SIMPLE_RE4
+		case 29:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			LPar1 := rhs[0]
-			RE_UNION2 := rhs[1]
+			RE2 := rhs[1]
 			RPar3 := rhs[2]
-			RE_SIMPLE_RE4 := rhs[3]
+			SIMPLE_RE4 := rhs[3]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_UNION2
-			RE_UNION2.Next = RPar3
-			RPar3.Next = RE_SIMPLE_RE4
+			CONCATENATION_SIMPLE_RE0.Child = LPar1
+			LPar1.Next = RE2
+			RE2.Next = RPar3
+			RPar3.Next = SIMPLE_RE4
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE4
 
 			{
-				nfaEnclosed := RE_UNION2.Value.(*Nfa)
-				rightNfa := RE_SIMPLE_RE4.Value.(*Nfa)
-
+				nfaEnclosed := RE2.Value.(*Nfa)
+				rightNfa := SIMPLE_RE4.Value.(*Nfa)
+				
 				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
+				
+				CONCATENATION_SIMPLE_RE0.Value = nfaEnclosed
 			}
-		case 52:
-			RE_SIMPLE_RE0 := lhs
+			_ = LPar1
+			_ = RE2
+			_ = RPar3
+			_ = SIMPLE_RE4
+		case 30:
+			SIMPLE_RE0 := lhs
 			LPar1 := rhs[0]
-			RE_UNION2 := rhs[1]
+			RE2 := rhs[1]
 			RPar3 := rhs[2]
 			Plus4 := rhs[3]
 
-			RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_UNION2
-			RE_UNION2.Next = RPar3
+			SIMPLE_RE0.Child = LPar1
+			LPar1.Next = RE2
+			RE2.Next = RPar3
 			RPar3.Next = Plus4
+			SIMPLE_RE0.LastChild = Plus4
 
 			{
-				nfaEnclosed := RE_UNION2.Value.(*Nfa)
-
+				nfaEnclosed := RE2.Value.(*Nfa)
+				
 				nfaEnclosed.KleenePlus()
-
-				RE_SIMPLE_RE0.Value = nfaEnclosed
+				
+				SIMPLE_RE0.Value = nfaEnclosed
 			}
-		case 53:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = LPar1
+			_ = RE2
+			_ = RPar3
+			_ = Plus4
+		case 31:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			LPar1 := rhs[0]
-			RE_UNION2 := rhs[1]
+			RE2 := rhs[1]
 			RPar3 := rhs[2]
 			Plus4 := rhs[3]
-			CONCATENATION_RE_SIMPLE_RE5 := rhs[4]
+			CONCATENATION_SIMPLE_RE5 := rhs[4]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_UNION2
-			RE_UNION2.Next = RPar3
+			CONCATENATION_SIMPLE_RE0.Child = LPar1
+			LPar1.Next = RE2
+			RE2.Next = RPar3
 			RPar3.Next = Plus4
-			Plus4.Next = CONCATENATION_RE_SIMPLE_RE5
+			Plus4.Next = CONCATENATION_SIMPLE_RE5
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE5
 
 			{
-				nfaEnclosed := RE_UNION2.Value.(*Nfa)
+				nfaEnclosed := RE2.Value.(*Nfa)
 				nfaEnclosed.KleenePlus()
-				rightNfa := CONCATENATION_RE_SIMPLE_RE5.Value.(*Nfa)
-
+				rightNfa := CONCATENATION_SIMPLE_RE5.Value.(*Nfa)
+				
 				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 54:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+					
+				CONCATENATION_SIMPLE_RE0.Value = nfaEnclosed
+			}
+			_ = LPar1
+			_ = RE2
+			_ = RPar3
+			_ = Plus4
+			_ = CONCATENATION_SIMPLE_RE5
+		case 32:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			LPar1 := rhs[0]
-			RE_UNION2 := rhs[1]
+			RE2 := rhs[1]
 			RPar3 := rhs[2]
 			Plus4 := rhs[3]
-			RE_SIMPLE_RE5 := rhs[4]
+			SIMPLE_RE5 := rhs[4]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_UNION2
-			RE_UNION2.Next = RPar3
+			CONCATENATION_SIMPLE_RE0.Child = LPar1
+			LPar1.Next = RE2
+			RE2.Next = RPar3
 			RPar3.Next = Plus4
-			Plus4.Next = RE_SIMPLE_RE5
+			Plus4.Next = SIMPLE_RE5
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE5
 
 			{
-				nfaEnclosed := RE_UNION2.Value.(*Nfa)
+				nfaEnclosed := RE2.Value.(*Nfa)
 				nfaEnclosed.KleenePlus()
-				rightNfa := RE_SIMPLE_RE5.Value.(*Nfa)
-
+				rightNfa := SIMPLE_RE5.Value.(*Nfa)
+				
 				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 55:
-			RE_SIMPLE_RE0 := lhs
+					
+				CONCATENATION_SIMPLE_RE0.Value = nfaEnclosed
+			}
+			_ = LPar1
+			_ = RE2
+			_ = RPar3
+			_ = Plus4
+			_ = SIMPLE_RE5
+		case 33:
+			SIMPLE_RE0 := lhs
 			LPar1 := rhs[0]
-			RE_UNION2 := rhs[1]
+			RE2 := rhs[1]
 			RPar3 := rhs[2]
 			Star4 := rhs[3]
 
-			RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_UNION2
-			RE_UNION2.Next = RPar3
+			SIMPLE_RE0.Child = LPar1
+			LPar1.Next = RE2
+			RE2.Next = RPar3
 			RPar3.Next = Star4
+			SIMPLE_RE0.LastChild = Star4
 
 			{
-				nfaEnclosed := RE_UNION2.Value.(*Nfa)
-
+				nfaEnclosed := RE2.Value.(*Nfa)
+				
 				nfaEnclosed.KleeneStar()
-
-				RE_SIMPLE_RE0.Value = nfaEnclosed
+				
+				SIMPLE_RE0.Value = nfaEnclosed
 			}
-		case 56:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = LPar1
+			_ = RE2
+			_ = RPar3
+			_ = Star4
+		case 34:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			LPar1 := rhs[0]
-			RE_UNION2 := rhs[1]
+			RE2 := rhs[1]
 			RPar3 := rhs[2]
 			Star4 := rhs[3]
-			CONCATENATION_RE_SIMPLE_RE5 := rhs[4]
+			CONCATENATION_SIMPLE_RE5 := rhs[4]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_UNION2
-			RE_UNION2.Next = RPar3
+			CONCATENATION_SIMPLE_RE0.Child = LPar1
+			LPar1.Next = RE2
+			RE2.Next = RPar3
 			RPar3.Next = Star4
-			Star4.Next = CONCATENATION_RE_SIMPLE_RE5
+			Star4.Next = CONCATENATION_SIMPLE_RE5
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE5
 
 			{
-				nfaEnclosed := RE_UNION2.Value.(*Nfa)
+				nfaEnclosed := RE2.Value.(*Nfa)
 				nfaEnclosed.KleeneStar()
-				rightNfa := CONCATENATION_RE_SIMPLE_RE5.Value.(*Nfa)
-
+				rightNfa := CONCATENATION_SIMPLE_RE5.Value.(*Nfa)
+				
 				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 57:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+				
+				CONCATENATION_SIMPLE_RE0.Value = nfaEnclosed
+			}
+			_ = LPar1
+			_ = RE2
+			_ = RPar3
+			_ = Star4
+			_ = CONCATENATION_SIMPLE_RE5
+		case 35:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			LPar1 := rhs[0]
-			RE_UNION2 := rhs[1]
+			RE2 := rhs[1]
 			RPar3 := rhs[2]
 			Star4 := rhs[3]
-			RE_SIMPLE_RE5 := rhs[4]
+			SIMPLE_RE5 := rhs[4]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = LPar1
-			LPar1.Next = RE_UNION2
-			RE_UNION2.Next = RPar3
+			CONCATENATION_SIMPLE_RE0.Child = LPar1
+			LPar1.Next = RE2
+			RE2.Next = RPar3
 			RPar3.Next = Star4
-			Star4.Next = RE_SIMPLE_RE5
+			Star4.Next = SIMPLE_RE5
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE5
 
 			{
-				nfaEnclosed := RE_UNION2.Value.(*Nfa)
+				nfaEnclosed := RE2.Value.(*Nfa)
 				nfaEnclosed.KleeneStar()
-				rightNfa := RE_SIMPLE_RE5.Value.(*Nfa)
-
+				rightNfa := SIMPLE_RE5.Value.(*Nfa)
+				
 				nfaEnclosed.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = nfaEnclosed
-			}
-		case 58:
-			RE_SIMPLE_RE0 := lhs
+				
+				CONCATENATION_SIMPLE_RE0.Value = nfaEnclosed
+			}
+			_ = LPar1
+			_ = RE2
+			_ = RPar3
+			_ = Star4
+			_ = SIMPLE_RE5
+		case 36:
+			SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			SET_ITEMS2 := rhs[1]
 			SquareRPar3 := rhs[2]
 
-			RE_SIMPLE_RE0.Child = SquareLPar1
+			SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = SET_ITEMS2
 			SET_ITEMS2.Next = SquareRPar3
+			SIMPLE_RE0.LastChild = SquareRPar3
 
 			{
 				newNfa := newNfaFromCharClass(SET_ITEMS2.Value.([256]bool))
-
-				RE_SIMPLE_RE0.Value = &newNfa
+				
+				SIMPLE_RE0.Value = &newNfa
 			}
-		case 59:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = SquareLPar1
+			_ = SET_ITEMS2
+			_ = SquareRPar3
+		case 37:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			SET_ITEMS2 := rhs[1]
 			SquareRPar3 := rhs[2]
-			CONCATENATION_RE_SIMPLE_RE4 := rhs[3]
+			CONCATENATION_SIMPLE_RE4 := rhs[3]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = SquareLPar1
+			CONCATENATION_SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = SET_ITEMS2
 			SET_ITEMS2.Next = SquareRPar3
-			SquareRPar3.Next = CONCATENATION_RE_SIMPLE_RE4
+			SquareRPar3.Next = CONCATENATION_SIMPLE_RE4
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE4
 
 			{
 				newNfa := newNfaFromCharClass(SET_ITEMS2.Value.([256]bool))
-				rightNfa := CONCATENATION_RE_SIMPLE_RE4.Value.(*Nfa)
-
+				rightNfa := CONCATENATION_SIMPLE_RE4.Value.(*Nfa)
+				
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
 			}
-		case 60:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = SquareLPar1
+			_ = SET_ITEMS2
+			_ = SquareRPar3
+			_ = CONCATENATION_SIMPLE_RE4
+		case 38:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			SET_ITEMS2 := rhs[1]
 			SquareRPar3 := rhs[2]
-			RE_SIMPLE_RE4 := rhs[3]
+			SIMPLE_RE4 := rhs[3]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = SquareLPar1
+			CONCATENATION_SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = SET_ITEMS2
 			SET_ITEMS2.Next = SquareRPar3
-			SquareRPar3.Next = RE_SIMPLE_RE4
+			SquareRPar3.Next = SIMPLE_RE4
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE4
 
 			{
 				newNfa := newNfaFromCharClass(SET_ITEMS2.Value.([256]bool))
-				rightNfa := RE_SIMPLE_RE4.Value.(*Nfa)
-
+				rightNfa := SIMPLE_RE4.Value.(*Nfa)
+				
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
 			}
-		case 61:
-			RE_SIMPLE_RE0 := lhs
+			_ = SquareLPar1
+			_ = SET_ITEMS2
+			_ = SquareRPar3
+			_ = SIMPLE_RE4
+		case 39:
+			SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			SET_ITEMS2 := rhs[1]
 			SquareRPar3 := rhs[2]
 			Plus4 := rhs[3]
 
-			RE_SIMPLE_RE0.Child = SquareLPar1
+			SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = SET_ITEMS2
 			SET_ITEMS2.Next = SquareRPar3
 			SquareRPar3.Next = Plus4
+			SIMPLE_RE0.LastChild = Plus4
 
 			{
 				newNfa := newNfaFromCharClass(SET_ITEMS2.Value.([256]bool))
 				newNfa.KleenePlus()
-
-				RE_SIMPLE_RE0.Value = &newNfa
+				
+				SIMPLE_RE0.Value = &newNfa
 			}
-		case 62:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = SquareLPar1
+			_ = SET_ITEMS2
+			_ = SquareRPar3
+			_ = Plus4
+		case 40:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			SET_ITEMS2 := rhs[1]
 			SquareRPar3 := rhs[2]
 			Plus4 := rhs[3]
-			CONCATENATION_RE_SIMPLE_RE5 := rhs[4]
+			CONCATENATION_SIMPLE_RE5 := rhs[4]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = SquareLPar1
+			CONCATENATION_SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = SET_ITEMS2
 			SET_ITEMS2.Next = SquareRPar3
 			SquareRPar3.Next = Plus4
-			Plus4.Next = CONCATENATION_RE_SIMPLE_RE5
+			Plus4.Next = CONCATENATION_SIMPLE_RE5
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE5
 
 			{
 				newNfa := newNfaFromCharClass(SET_ITEMS2.Value.([256]bool))
 				newNfa.KleenePlus()
-				rightNfa := CONCATENATION_RE_SIMPLE_RE5.Value.(*Nfa)
-
+				rightNfa := CONCATENATION_SIMPLE_RE5.Value.(*Nfa)
+				
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
-			}
-		case 63:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
+			}
+			_ = SquareLPar1
+			_ = SET_ITEMS2
+			_ = SquareRPar3
+			_ = Plus4
+			_ = CONCATENATION_SIMPLE_RE5
+		case 41:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			SET_ITEMS2 := rhs[1]
 			SquareRPar3 := rhs[2]
 			Plus4 := rhs[3]
-			RE_SIMPLE_RE5 := rhs[4]
+			SIMPLE_RE5 := rhs[4]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = SquareLPar1
+			CONCATENATION_SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = SET_ITEMS2
 			SET_ITEMS2.Next = SquareRPar3
 			SquareRPar3.Next = Plus4
-			Plus4.Next = RE_SIMPLE_RE5
+			Plus4.Next = SIMPLE_RE5
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE5
 
 			{
 				newNfa := newNfaFromCharClass(SET_ITEMS2.Value.([256]bool))
 				newNfa.KleenePlus()
-				rightNfa := RE_SIMPLE_RE5.Value.(*Nfa)
-
+				rightNfa := SIMPLE_RE5.Value.(*Nfa)
+				
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
-			}
-		case 64:
-			RE_SIMPLE_RE0 := lhs
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
+			}
+			_ = SquareLPar1
+			_ = SET_ITEMS2
+			_ = SquareRPar3
+			_ = Plus4
+			_ = SIMPLE_RE5
+		case 42:
+			SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			SET_ITEMS2 := rhs[1]
 			SquareRPar3 := rhs[2]
 			Star4 := rhs[3]
 
-			RE_SIMPLE_RE0.Child = SquareLPar1
+			SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = SET_ITEMS2
 			SET_ITEMS2.Next = SquareRPar3
 			SquareRPar3.Next = Star4
+			SIMPLE_RE0.LastChild = Star4
 
 			{
 				newNfa := newNfaFromCharClass(SET_ITEMS2.Value.([256]bool))
 				newNfa.KleeneStar()
-
-				RE_SIMPLE_RE0.Value = &newNfa
+				
+				SIMPLE_RE0.Value = &newNfa
 			}
-		case 65:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = SquareLPar1
+			_ = SET_ITEMS2
+			_ = SquareRPar3
+			_ = Star4
+		case 43:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			SET_ITEMS2 := rhs[1]
 			SquareRPar3 := rhs[2]
 			Star4 := rhs[3]
-			CONCATENATION_RE_SIMPLE_RE5 := rhs[4]
+			CONCATENATION_SIMPLE_RE5 := rhs[4]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = SquareLPar1
+			CONCATENATION_SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = SET_ITEMS2
 			SET_ITEMS2.Next = SquareRPar3
 			SquareRPar3.Next = Star4
-			Star4.Next = CONCATENATION_RE_SIMPLE_RE5
+			Star4.Next = CONCATENATION_SIMPLE_RE5
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE5
 
 			{
 				newNfa := newNfaFromCharClass(SET_ITEMS2.Value.([256]bool))
 				newNfa.KleeneStar()
-				rightNfa := CONCATENATION_RE_SIMPLE_RE5.Value.(*Nfa)
-
+				rightNfa := CONCATENATION_SIMPLE_RE5.Value.(*Nfa)
+				
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
-			}
-		case 66:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
+			}
+			_ = SquareLPar1
+			_ = SET_ITEMS2
+			_ = SquareRPar3
+			_ = Star4
+			_ = CONCATENATION_SIMPLE_RE5
+		case 44:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			SET_ITEMS2 := rhs[1]
 			SquareRPar3 := rhs[2]
 			Star4 := rhs[3]
-			RE_SIMPLE_RE5 := rhs[4]
+			SIMPLE_RE5 := rhs[4]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = SquareLPar1
+			CONCATENATION_SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = SET_ITEMS2
 			SET_ITEMS2.Next = SquareRPar3
 			SquareRPar3.Next = Star4
-			Star4.Next = RE_SIMPLE_RE5
+			Star4.Next = SIMPLE_RE5
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE5
 
 			{
 				newNfa := newNfaFromCharClass(SET_ITEMS2.Value.([256]bool))
 				newNfa.KleeneStar()
-				rightNfa := RE_SIMPLE_RE5.Value.(*Nfa)
-
+				rightNfa := SIMPLE_RE5.Value.(*Nfa)
+				
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
-			}
-		case 67:
-			RE_SIMPLE_RE0 := lhs
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
+			}
+			_ = SquareLPar1
+			_ = SET_ITEMS2
+			_ = SquareRPar3
+			_ = Star4
+			_ = SIMPLE_RE5
+		case 45:
+			SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			Caret2 := rhs[1]
 			SET_ITEMS3 := rhs[2]
 			SquareRPar4 := rhs[3]
 
-			RE_SIMPLE_RE0.Child = SquareLPar1
+			SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = Caret2
 			Caret2.Next = SET_ITEMS3
 			SET_ITEMS3.Next = SquareRPar4
+			SIMPLE_RE0.LastChild = SquareRPar4
 
 			{
 				chars := SET_ITEMS3.Value.([256]bool)
-
+				
 				//Skip the first Char (empty transition)
 				for i := 1; i < len(chars); i++ {
 					chars[i] = !chars[i]
 				}
-
+				
 				newNfa := newNfaFromCharClass(chars)
-
-				RE_SIMPLE_RE0.Value = &newNfa
+				
+				SIMPLE_RE0.Value = &newNfa
 			}
-		case 68:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+			_ = SquareLPar1
+			_ = Caret2
+			_ = SET_ITEMS3
+			_ = SquareRPar4
+		case 46:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			Caret2 := rhs[1]
 			SET_ITEMS3 := rhs[2]
 			SquareRPar4 := rhs[3]
-			CONCATENATION_RE_SIMPLE_RE5 := rhs[4]
+			CONCATENATION_SIMPLE_RE5 := rhs[4]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = SquareLPar1
+			CONCATENATION_SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = Caret2
 			Caret2.Next = SET_ITEMS3
 			SET_ITEMS3.Next = SquareRPar4
-			SquareRPar4.Next = CONCATENATION_RE_SIMPLE_RE5
+			SquareRPar4.Next = CONCATENATION_SIMPLE_RE5
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE5
 
 			{
 				chars := SET_ITEMS3.Value.([256]bool)
-
+				
 				//Skip the first Char (empty transition)
 				for i := 1; i < len(chars); i++ {
 					chars[i] = !chars[i]
 				}
-
+				
 				newNfa := newNfaFromCharClass(chars)
-				rightNfa := CONCATENATION_RE_SIMPLE_RE5.Value.(*Nfa)
-
+				rightNfa := CONCATENATION_SIMPLE_RE5.Value.(*Nfa)
+				
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
-			}
-		case 69:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
+			}
+			_ = SquareLPar1
+			_ = Caret2
+			_ = SET_ITEMS3
+			_ = SquareRPar4
+			_ = CONCATENATION_SIMPLE_RE5
+		case 47:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			Caret2 := rhs[1]
 			SET_ITEMS3 := rhs[2]
 			SquareRPar4 := rhs[3]
-			RE_SIMPLE_RE5 := rhs[4]
+			SIMPLE_RE5 := rhs[4]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = SquareLPar1
+			CONCATENATION_SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = Caret2
 			Caret2.Next = SET_ITEMS3
 			SET_ITEMS3.Next = SquareRPar4
-			SquareRPar4.Next = RE_SIMPLE_RE5
+			SquareRPar4.Next = SIMPLE_RE5
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE5
 
 			{
 				chars := SET_ITEMS3.Value.([256]bool)
-
+				
 				//Skip the first Char (empty transition)
 				for i := 1; i < len(chars); i++ {
 					chars[i] = !chars[i]
 				}
-
+				
 				newNfa := newNfaFromCharClass(chars)
-				rightNfa := RE_SIMPLE_RE5.Value.(*Nfa)
-
+				rightNfa := SIMPLE_RE5.Value.(*Nfa)
+				
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
-			}
-		case 70:
-			RE_SIMPLE_RE0 := lhs
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
+			}
+			_ = SquareLPar1
+			_ = Caret2
+			_ = SET_ITEMS3
+			_ = SquareRPar4
+			_ = SIMPLE_RE5
+		case 48:
+			SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			Caret2 := rhs[1]
 			SET_ITEMS3 := rhs[2]
 			SquareRPar4 := rhs[3]
 			Plus5 := rhs[4]
 
-			RE_SIMPLE_RE0.Child = SquareLPar1
+			SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = Caret2
 			Caret2.Next = SET_ITEMS3
 			SET_ITEMS3.Next = SquareRPar4
 			SquareRPar4.Next = Plus5
+			SIMPLE_RE0.LastChild = Plus5
 
 			{
 				chars := SET_ITEMS3.Value.([256]bool)
-
+				
 				//Skip the first Char (empty transition)
 				for i := 1; i < len(chars); i++ {
 					chars[i] = !chars[i]
 				}
-
+				
 				newNfa := newNfaFromCharClass(chars)
-
+				
 				newNfa.KleenePlus()
-
-				RE_SIMPLE_RE0.Value = &newNfa
-			}
-		case 71:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+				
+				SIMPLE_RE0.Value = &newNfa
+			}
+			_ = SquareLPar1
+			_ = Caret2
+			_ = SET_ITEMS3
+			_ = SquareRPar4
+			_ = Plus5
+		case 49:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			Caret2 := rhs[1]
 			SET_ITEMS3 := rhs[2]
 			SquareRPar4 := rhs[3]
 			Plus5 := rhs[4]
-			CONCATENATION_RE_SIMPLE_RE6 := rhs[5]
+			CONCATENATION_SIMPLE_RE6 := rhs[5]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = SquareLPar1
+			CONCATENATION_SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = Caret2
 			Caret2.Next = SET_ITEMS3
 			SET_ITEMS3.Next = SquareRPar4
 			SquareRPar4.Next = Plus5
-			Plus5.Next = CONCATENATION_RE_SIMPLE_RE6
+			Plus5.Next = CONCATENATION_SIMPLE_RE6
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE6
 
 			{
 				chars := SET_ITEMS3.Value.([256]bool)
-
+				
 				//Skip the first Char (empty transition)
 				for i := 1; i < len(chars); i++ {
 					chars[i] = !chars[i]
 				}
-
+				
 				newNfa := newNfaFromCharClass(chars)
 				newNfa.KleenePlus()
-				rightNfa := CONCATENATION_RE_SIMPLE_RE6.Value.(*Nfa)
-
+				rightNfa := CONCATENATION_SIMPLE_RE6.Value.(*Nfa)
+				
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
-			}
-		case 72:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
+			}
+			_ = SquareLPar1
+			_ = Caret2
+			_ = SET_ITEMS3
+			_ = SquareRPar4
+			_ = Plus5
+			_ = CONCATENATION_SIMPLE_RE6
+		case 50:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			Caret2 := rhs[1]
 			SET_ITEMS3 := rhs[2]
 			SquareRPar4 := rhs[3]
 			Plus5 := rhs[4]
-			RE_SIMPLE_RE6 := rhs[5]
+			SIMPLE_RE6 := rhs[5]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = SquareLPar1
+			CONCATENATION_SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = Caret2
 			Caret2.Next = SET_ITEMS3
 			SET_ITEMS3.Next = SquareRPar4
 			SquareRPar4.Next = Plus5
-			Plus5.Next = RE_SIMPLE_RE6
+			Plus5.Next = SIMPLE_RE6
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE6
 
 			{
 				chars := SET_ITEMS3.Value.([256]bool)
-
+				
 				//Skip the first Char (empty transition)
 				for i := 1; i < len(chars); i++ {
 					chars[i] = !chars[i]
 				}
-
+				
 				newNfa := newNfaFromCharClass(chars)
 				newNfa.KleenePlus()
-				rightNfa := RE_SIMPLE_RE6.Value.(*Nfa)
-
+				rightNfa := SIMPLE_RE6.Value.(*Nfa)
+				
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
-			}
-		case 73:
-			RE_SIMPLE_RE0 := lhs
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
+			}
+			_ = SquareLPar1
+			_ = Caret2
+			_ = SET_ITEMS3
+			_ = SquareRPar4
+			_ = Plus5
+			_ = SIMPLE_RE6
+		case 51:
+			SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			Caret2 := rhs[1]
 			SET_ITEMS3 := rhs[2]
 			SquareRPar4 := rhs[3]
 			Star5 := rhs[4]
 
-			RE_SIMPLE_RE0.Child = SquareLPar1
+			SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = Caret2
 			Caret2.Next = SET_ITEMS3
 			SET_ITEMS3.Next = SquareRPar4
 			SquareRPar4.Next = Star5
+			SIMPLE_RE0.LastChild = Star5
 
 			{
 				chars := SET_ITEMS3.Value.([256]bool)
-
+				
 				//Skip the first Char (empty transition)
 				for i := 1; i < len(chars); i++ {
 					chars[i] = !chars[i]
 				}
-
+				
 				newNfa := newNfaFromCharClass(chars)
-
+				
 				newNfa.KleeneStar()
-
-				RE_SIMPLE_RE0.Value = &newNfa
-			}
-		case 74:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+				
+				SIMPLE_RE0.Value = &newNfa
+			}
+			_ = SquareLPar1
+			_ = Caret2
+			_ = SET_ITEMS3
+			_ = SquareRPar4
+			_ = Star5
+		case 52:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			Caret2 := rhs[1]
 			SET_ITEMS3 := rhs[2]
 			SquareRPar4 := rhs[3]
 			Star5 := rhs[4]
-			CONCATENATION_RE_SIMPLE_RE6 := rhs[5]
+			CONCATENATION_SIMPLE_RE6 := rhs[5]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = SquareLPar1
+			CONCATENATION_SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = Caret2
 			Caret2.Next = SET_ITEMS3
 			SET_ITEMS3.Next = SquareRPar4
 			SquareRPar4.Next = Star5
-			Star5.Next = CONCATENATION_RE_SIMPLE_RE6
+			Star5.Next = CONCATENATION_SIMPLE_RE6
+			CONCATENATION_SIMPLE_RE0.LastChild = CONCATENATION_SIMPLE_RE6
 
 			{
 				chars := SET_ITEMS3.Value.([256]bool)
-
+				
 				//Skip the first Char (empty transition)
 				for i := 1; i < len(chars); i++ {
 					chars[i] = !chars[i]
 				}
-
+				
 				newNfa := newNfaFromCharClass(chars)
 				newNfa.KleeneStar()
-				rightNfa := CONCATENATION_RE_SIMPLE_RE6.Value.(*Nfa)
-
+				rightNfa := CONCATENATION_SIMPLE_RE6.Value.(*Nfa)
+				
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
-			}
-		case 75:
-			CONCATENATION_RE_SIMPLE_RE0 := lhs
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
+			}
+			_ = SquareLPar1
+			_ = Caret2
+			_ = SET_ITEMS3
+			_ = SquareRPar4
+			_ = Star5
+			_ = CONCATENATION_SIMPLE_RE6
+		case 53:
+			CONCATENATION_SIMPLE_RE0 := lhs
 			SquareLPar1 := rhs[0]
 			Caret2 := rhs[1]
 			SET_ITEMS3 := rhs[2]
 			SquareRPar4 := rhs[3]
 			Star5 := rhs[4]
-			RE_SIMPLE_RE6 := rhs[5]
+			SIMPLE_RE6 := rhs[5]
 
-			CONCATENATION_RE_SIMPLE_RE0.Child = SquareLPar1
+			CONCATENATION_SIMPLE_RE0.Child = SquareLPar1
 			SquareLPar1.Next = Caret2
 			Caret2.Next = SET_ITEMS3
 			SET_ITEMS3.Next = SquareRPar4
 			SquareRPar4.Next = Star5
-			Star5.Next = RE_SIMPLE_RE6
+			Star5.Next = SIMPLE_RE6
+			CONCATENATION_SIMPLE_RE0.LastChild = SIMPLE_RE6
 
 			{
 				chars := SET_ITEMS3.Value.([256]bool)
-
+				
 				//Skip the first Char (empty transition)
 				for i := 1; i < len(chars); i++ {
 					chars[i] = !chars[i]
 				}
-
+				
 				newNfa := newNfaFromCharClass(chars)
 				newNfa.KleeneStar()
-				rightNfa := RE_SIMPLE_RE6.Value.(*Nfa)
-
+				rightNfa := SIMPLE_RE6.Value.(*Nfa)
+				
 				newNfa.Concatenate(*rightNfa)
-
-				CONCATENATION_RE_SIMPLE_RE0.Value = &newNfa
-			}
+				
+				CONCATENATION_SIMPLE_RE0.Value = &newNfa
+			}
+			_ = SquareLPar1
+			_ = Caret2
+			_ = SET_ITEMS3
+			_ = SquareRPar4
+			_ = Star5
+			_ = SIMPLE_RE6
 		}
+		_ = ruleFlags
 	}
 
-	parser := &gopapageno.Grammar{
-		Lexer:                     NewLexer(),
-		NumTerminals:              numTerminals,
-		NumNonterminals:           numNonTerminals,
-		MaxRHSLength:              maxRHSLen,
-		Rules:                     rules,
-		CompressedRules:           compressedRules,
-		PrecedenceMatrix:          precMatrix,
+	return &gopapageno.Grammar{
+		NumTerminals:  numTerminals,
+		NumNonterminals: numNonTerminals,
+		MaxRHSLength: maxRHSLen,
+		Rules: rules,
+		CompressedRules: compressedRules,
+		PrecedenceMatrix: precMatrix,
 		BitPackedPrecedenceMatrix: bitPackedMatrix,
-		Func:                      fn,
+		TokenNames: tokenNames,
+		SyncTerminals: syncTerminals,
+		Func: fn,
+		ParsingStrategy: gopapageno.OPP,
 	}
-
-	for _, opt := range opts {
-		opt(parser)
-	}
-
-	return parser
 }
+
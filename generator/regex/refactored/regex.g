@@ -1,5 +1,3 @@
-%%
-
 %axiom RE
 
 %%
@@ -0,0 +1,480 @@
+package regex
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+runeRange is an inclusive range of code points.
+*/
+type runeRange struct {
+	lo, hi rune
+}
+
+/*
+runeSet is a set of code points, kept as a sorted list of disjoint ranges once normalized.
+*/
+type runeSet []runeRange
+
+func (s *runeSet) addRange(lo, hi rune) {
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+
+	*s = append(*s, runeRange{lo, hi})
+}
+
+func (s *runeSet) addRune(r rune) {
+	s.addRange(r, r)
+}
+
+func (s *runeSet) addTable(table *unicode.RangeTable) {
+	for _, r16 := range table.R16 {
+		for r := rune(r16.Lo); r <= rune(r16.Hi); r += rune(r16.Stride) {
+			if r16.Stride == 1 {
+				s.addRange(r, rune(r16.Hi))
+				break
+			}
+			s.addRune(r)
+		}
+	}
+
+	for _, r32 := range table.R32 {
+		for r := rune(r32.Lo); r <= rune(r32.Hi); r += rune(r32.Stride) {
+			if r32.Stride == 1 {
+				s.addRange(r, rune(r32.Hi))
+				break
+			}
+			s.addRune(r)
+		}
+	}
+}
+
+/*
+normalize sorts the ranges of the set and merges the overlapping or adjacent ones.
+*/
+func (s *runeSet) normalize() {
+	slices.SortFunc(*s, func(a, b runeRange) int {
+		return int(a.lo - b.lo)
+	})
+
+	merged := (*s)[:0]
+	for _, r := range *s {
+		if n := len(merged); n > 0 && r.lo <= merged[n-1].hi+1 {
+			merged[n-1].hi = max(merged[n-1].hi, r.hi)
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	*s = merged
+}
+
+/*
+negate replaces the set with its complement over the valid code points.
+*/
+func (s *runeSet) negate() {
+	s.normalize()
+
+	negated := runeSet{}
+	next := rune(0)
+	for _, r := range *s {
+		if r.lo > next {
+			negated.addRange(next, r.lo-1)
+		}
+		next = r.hi + 1
+	}
+
+	if next <= unicode.MaxRune {
+		negated.addRange(next, unicode.MaxRune)
+	}
+
+	*s = negated
+}
+
+/*
+byteRange is an inclusive range of bytes.
+*/
+type byteRange struct {
+	lo, hi byte
+}
+
+/*
+utf8Sequences calls f with every sequence of byte ranges matching the UTF-8 encoding of the code points in [lo, hi].
+Each sequence covers code points that are encoded with the same number of bytes and share all but a suffix of their bits,
+so that matching a byte from each range in order accepts exactly one of them.
+Surrogates, which can't be encoded, are skipped.
+*/
+func utf8Sequences(lo, hi rune, f func(seq []byteRange)) {
+	if lo > hi {
+		return
+	}
+
+	// Surrogates halves.
+	if lo < 0xD800 && hi > 0xDFFF {
+		utf8Sequences(lo, 0xD7FF, f)
+		utf8Sequences(0xE000, hi, f)
+		return
+	}
+	if lo >= 0xD800 && lo <= 0xDFFF {
+		utf8Sequences(0xE000, hi, f)
+		return
+	}
+	if hi >= 0xD800 && hi <= 0xDFFF {
+		utf8Sequences(lo, 0xD7FF, f)
+		return
+	}
+
+	// Ranges spanning different encoding lengths.
+	for _, max := range []rune{0x7F, 0x7FF, 0xFFFF} {
+		if lo <= max && hi > max {
+			utf8Sequences(lo, max, f)
+			utf8Sequences(max+1, hi, f)
+			return
+		}
+	}
+
+	// Ranges whose continuation bytes can't be matched independently.
+	n := utf8.RuneLen(lo)
+	for i := 1; i < n; i++ {
+		m := rune(1)<<(6*i) - 1
+
+		if lo&^m != hi&^m {
+			if lo&m != 0 {
+				utf8Sequences(lo, lo|m, f)
+				utf8Sequences((lo|m)+1, hi, f)
+				return
+			}
+			if hi&m != m {
+				utf8Sequences(lo, (hi&^m)-1, f)
+				utf8Sequences(hi&^m, hi, f)
+				return
+			}
+		}
+	}
+
+	var loBytes, hiBytes [utf8.UTFMax]byte
+	utf8.EncodeRune(loBytes[:], lo)
+	utf8.EncodeRune(hiBytes[:], hi)
+
+	seq := make([]byteRange, n)
+	for i := range seq {
+		seq[i] = byteRange{loBytes[i], hiBytes[i]}
+	}
+
+	f(seq)
+}
+
+/*
+newNfaFromRuneSet returns an Nfa matching the UTF-8 encoding of any code point of the set.
+Sequences sharing their leading byte ranges share the states reached through them.
+The NUL character can't be matched, since its byte denotes empty transitions.
+*/
+func newNfaFromRuneSet(set runeSet) Nfa {
+	set.normalize()
+
+	nfa := Nfa{}
+	nfaInitial := NfaState{}
+	nfaFinal := NfaState{}
+
+	nfa.Initial = &nfaInitial
+	nfa.Final = &nfaFinal
+	nfa.NumStates = 2
+
+	type edge struct {
+		from *NfaState
+		r    byteRange
+	}
+	states := make(map[edge]*NfaState)
+
+	for _, r := range set {
+		utf8Sequences(max(r.lo, 1), r.hi, func(seq []byteRange) {
+			curState := nfa.Initial
+
+			for i, br := range seq {
+				nextState := nfa.Final
+
+				if i < len(seq)-1 {
+					e := edge{curState, br}
+					if state, ok := states[e]; ok {
+						curState = state
+						continue
+					}
+
+					nextState = &NfaState{}
+					states[e] = nextState
+					nfa.NumStates++
+				}
+
+				for c := int(br.lo); c <= int(br.hi); c++ {
+					curState.AddTransition(byte(c), nextState)
+				}
+				curState = nextState
+			}
+		})
+	}
+
+	return nfa
+}
+
+/*
+anyCharSet returns the set of code points matched by the . wildcard, which doesn't match line breaks.
+*/
+func anyCharSet() runeSet {
+	set := runeSet{{'\n', '\n'}, {'\r', '\r'}}
+	set.negate()
+
+	return set
+}
+
+var errUnterminatedClass = errors.New("unterminated character class")
+
+/*
+lexClass reads a character class, whose opening bracket has already been read, and returns the set of code points it matches.
+Classes may contain UTF-8 encoded characters, ranges of them, escapes and Unicode property classes.
+A leading caret negates the class, and a dash is taken literally at its beginning or end.
+*/
+func (l *lexer) lexClass() (runeSet, error) {
+	set := runeSet{}
+
+	negated := false
+	if l.pos < len(l.data) && l.data[l.pos] == '^' {
+		negated = true
+		l.pos++
+	}
+
+	first := true
+	for {
+		if l.pos >= len(l.data) {
+			return nil, errUnterminatedClass
+		}
+
+		switch l.data[l.pos] {
+		case ']':
+			l.pos++
+
+			if negated {
+				set.negate()
+			}
+			return set, nil
+		case '\t', '\r', '\n':
+			l.pos++
+			continue
+		}
+
+		// A property or shorthand class can't be the bound of a range.
+		if props, ok, err := l.lexClassEscape(); err != nil {
+			return nil, err
+		} else if ok {
+			set = append(set, props...)
+			first = false
+			continue
+		}
+
+		lo, err := l.lexClassRune(first)
+		if err != nil {
+			return nil, err
+		}
+		first = false
+
+		if l.pos+1 < len(l.data) && l.data[l.pos] == '-' && l.data[l.pos+1] != ']' {
+			l.pos++
+
+			hi, err := l.lexClassRune(false)
+			if err != nil {
+				return nil, err
+			}
+
+			set.addRange(lo, hi)
+			continue
+		}
+
+		set.addRune(lo)
+	}
+}
+
+/*
+lexClassRune reads a single code point of a character class.
+*/
+func (l *lexer) lexClassRune(first bool) (rune, error) {
+	c := l.data[l.pos]
+
+	switch {
+	case c == '\\':
+		l.pos++
+		return l.lexEscapedRune()
+	case c == '-' && !first && (l.pos+1 >= len(l.data) || l.data[l.pos+1] != ']'):
+		return 0, fmt.Errorf("unexpected range in character class")
+	}
+
+	return l.lexRune()
+}
+
+/*
+lexRune reads a UTF-8 encoded code point.
+*/
+func (l *lexer) lexRune() (rune, error) {
+	r, size := utf8.DecodeRune(l.data[l.pos:])
+	if r == utf8.RuneError && size <= 1 {
+		return 0, fmt.Errorf("invalid UTF-8 encoding at position %d", l.pos)
+	}
+
+	l.pos += size
+	return r, nil
+}
+
+/*
+lexEscapedRune reads the code point denoted by an escape sequence, whose backslash has already been read.
+*/
+func (l *lexer) lexEscapedRune() (rune, error) {
+	if l.pos >= len(l.data) {
+		return 0, fmt.Errorf("unterminated escape sequence")
+	}
+
+	c := l.data[l.pos]
+	l.pos++
+
+	switch c {
+	case '(', ')', '[', ']', '{', '}', '*', '+', '?', '-', '|', '^', '$', '.', '\\':
+		return rune(c), nil
+	case 't':
+		return '\t', nil
+	case 'r':
+		return '\r', nil
+	case 'n':
+		return '\n', nil
+	case 'u':
+		if l.pos+4 > len(l.data) {
+			return 0, fmt.Errorf("invalid \\u escape")
+		}
+
+		code, err := strconv.ParseUint(string(l.data[l.pos:l.pos+4]), 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid \\u escape: %w", err)
+		}
+
+		l.pos += 4
+
+		// As with \x00, NUL would match the empty string instead of itself.
+		if code == 0 {
+			return 0, fmt.Errorf("invalid \\u escape: the NUL character can't be matched")
+		}
+
+		return rune(code), nil
+	case 'x':
+		if l.pos+2 > len(l.data) {
+			return 0, fmt.Errorf("invalid \\x escape")
+		}
+
+		code, err := strconv.ParseUint(string(l.data[l.pos:l.pos+2]), 16, 8)
+		if err != nil {
+			return 0, fmt.Errorf("invalid \\x escape: %w", err)
+		}
+
+		l.pos += 2
+
+		// Byte 0 denotes empty transitions, so NUL would match the empty string instead of itself.
+		if code == 0 {
+			return 0, fmt.Errorf("invalid \\x escape: the NUL character can't be matched")
+		}
+
+		return rune(code), nil
+	}
+
+	return 0, fmt.Errorf("unknown escape sequence \\%c", c)
+}
+
+/*
+lexClassEscape reads an escape sequence denoting a set of code points, if one begins at the current position.
+These are Unicode property classes and the \d, \w and \s shorthands, along with their negations.
+*/
+func (l *lexer) lexClassEscape() (runeSet, bool, error) {
+	if set, ok := l.lexShorthand(); ok {
+		return set, true, nil
+	}
+
+	return l.lexProperty()
+}
+
+/*
+lexShorthand reads one of the \d, \w and \s shorthand classes, or their uppercase negations, if one begins at the current position.
+They only match ASCII characters: Unicode property classes should be used otherwise.
+*/
+func (l *lexer) lexShorthand() (runeSet, bool) {
+	if l.pos+1 >= len(l.data) || l.data[l.pos] != '\\' {
+		return nil, false
+	}
+
+	var set runeSet
+
+	c := l.data[l.pos+1]
+	switch c {
+	case 'd', 'D':
+		set = runeSet{{'0', '9'}}
+	case 'w', 'W':
+		set = runeSet{{'0', '9'}, {'A', 'Z'}, {'_', '_'}, {'a', 'z'}}
+	case 's', 'S':
+		set = runeSet{{'\t', '\r'}, {' ', ' '}}
+	default:
+		return nil, false
+	}
+
+	l.pos += 2
+
+	if c >= 'A' && c <= 'Z' {
+		set.negate()
+	}
+
+	return set, true
+}
+
+/*
+lexProperty reads a Unicode property class such as \pL, \p{Greek} or its negation \P{Lu}, if one begins at the current position.
+Properties are either general categories or scripts.
+*/
+func (l *lexer) lexProperty() (runeSet, bool, error) {
+	if l.pos+2 >= len(l.data) || l.data[l.pos] != '\\' || (l.data[l.pos+1] != 'p' && l.data[l.pos+1] != 'P') {
+		return nil, false, nil
+	}
+
+	negated := l.data[l.pos+1] == 'P'
+	pos := l.pos + 2
+
+	var name string
+	if l.data[pos] == '{' {
+		end := slices.Index(l.data[pos:], '}')
+		if end < 0 {
+			return nil, false, fmt.Errorf("unterminated Unicode property class")
+		}
+
+		name = string(l.data[pos+1 : pos+end])
+		pos += end + 1
+	} else {
+		name = string(l.data[pos])
+		pos++
+	}
+
+	table, ok := unicode.Categories[name]
+	if !ok {
+		table, ok = unicode.Scripts[name]
+	}
+	if !ok {
+		return nil, false, fmt.Errorf("unknown Unicode property class %s", name)
+	}
+
+	l.pos = pos
+
+	set := runeSet{}
+	set.addTable(table)
+
+	if negated {
+		set.negate()
+	}
+
+	return set, true, nil
+}
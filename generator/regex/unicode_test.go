@@ -0,0 +1,272 @@
+package regex
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+checkUtf8Sequences checks that the sequences produced by utf8Sequences for [lo, hi] match the encoding of every
+code point of the range but surrogates exactly once, and nothing else.
+*/
+func checkUtf8Sequences(t *testing.T, lo, hi rune) {
+	t.Helper()
+
+	seen := make(map[rune]bool)
+
+	utf8Sequences(lo, hi, func(seq []byteRange) {
+		buf := make([]byte, len(seq))
+
+		var visit func(i int)
+		visit = func(i int) {
+			if i == len(seq) {
+				r, size := utf8.DecodeRune(buf)
+				if size != len(buf) || (r == utf8.RuneError && size <= 1) {
+					t.Fatalf("[%X, %X]: sequence %v matches the invalid encoding % X", lo, hi, seq, buf)
+				}
+
+				if r < lo || r > hi {
+					t.Fatalf("[%X, %X]: sequence %v matches %X, which is out of range", lo, hi, seq, r)
+				}
+
+				if seen[r] {
+					t.Fatalf("[%X, %X]: %X is matched by more than one sequence", lo, hi, r)
+				}
+				seen[r] = true
+
+				return
+			}
+
+			for c := int(seq[i].lo); c <= int(seq[i].hi); c++ {
+				buf[i] = byte(c)
+				visit(i + 1)
+			}
+		}
+
+		visit(0)
+	})
+
+	for r := lo; r <= hi; r++ {
+		if !seen[r] && (r < 0xD800 || r > 0xDFFF) {
+			t.Fatalf("[%X, %X]: %X is not matched by any sequence", lo, hi, r)
+		}
+	}
+}
+
+func TestUtf8Sequences(t *testing.T) {
+	checkUtf8Sequences(t, 0, unicode.MaxRune)
+
+	ranges := []runeRange{
+		{'a', 'a'},
+		{0x7F, 0x80},
+		{0x7FF, 0x800},
+		{0xD7FF, 0xE000},
+		{0xD800, 0xDFFF},
+		{0xDBFF, 0xE001},
+		{0xFFFF, 0x10000},
+		{0x3B1, 0x10FF3},
+		{0x10401, 0x10FFFE},
+		{0xE9, 0xEB},
+	}
+
+	for _, r := range ranges {
+		checkUtf8Sequences(t, r.lo, r.hi)
+	}
+}
+
+/*
+contains reports whether r belongs to set.
+*/
+func contains(set runeSet, r rune) bool {
+	for _, rr := range set {
+		if r >= rr.lo && r <= rr.hi {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestLexClass(t *testing.T) {
+	tests := []struct {
+		class string
+		in    string
+		out   string
+	}{
+		{`a-c]`, "abc", "d`"},
+		{`^a-c]`, "dé\n", "abc"},
+		{`-a]`, "-a", "b"},
+		{`a-]`, "-a", "b"},
+		{`é-ë]`, "éêë", "eè"},
+		{`é\x41]`, "éA", "eB"},
+		{`\pL]`, "aßλ", "1 "},
+		{`\p{Greek}]`, "λΩ", "a"},
+		{`\P{Lu}]`, "a1", "AΩ"},
+		{`^\p{Greek}]`, "a", "λ"},
+		{`\d\s]`, "5 \t", "a"},
+		{`\D]`, "a", "5"},
+		{`^\W]`, "a_9", "-"},
+		{"a\n\tb]", "ab", "\n\t"},
+	}
+
+	for _, tt := range tests {
+		l := &lexer{data: []byte(tt.class)}
+
+		set, err := l.lexClass()
+		if err != nil {
+			t.Errorf("[%s: unexpected error: %v", tt.class, err)
+			continue
+		}
+
+		if l.pos != len(tt.class) {
+			t.Errorf("[%s: expected the whole class to be read, stopped at %d", tt.class, l.pos)
+		}
+
+		set.normalize()
+
+		for _, r := range tt.in {
+			if !contains(set, r) {
+				t.Errorf("[%s: expected %q to be matched", tt.class, r)
+			}
+		}
+
+		for _, r := range tt.out {
+			if contains(set, r) {
+				t.Errorf("[%s: expected %q not to be matched", tt.class, r)
+			}
+		}
+	}
+}
+
+func TestLexClassErrors(t *testing.T) {
+	classes := []string{
+		`a-c`,
+		`\p{Nope}]`,
+		`\p{L`,
+		`\q]`,
+		`\u12]`,
+		"\xff]",
+		`\x00-\x1F]`,
+		`\u0000]`,
+	}
+
+	for _, class := range classes {
+		l := &lexer{data: []byte(class)}
+
+		if _, err := l.lexClass(); err == nil {
+			t.Errorf("[%s: expected an error", class)
+		}
+	}
+}
+
+func TestLexEscapedRuneErrors(t *testing.T) {
+	tests := []struct {
+		escape string
+		err    string
+	}{
+		{`x00`, "the NUL character can't be matched"},
+		{`x4`, "invalid \\x escape"},
+		{`xZZ`, "invalid \\x escape"},
+		{`u0000`, "the NUL character can't be matched"},
+		{`u12`, "invalid \\u escape"},
+		{`q`, "unknown escape sequence"},
+		{``, "unterminated escape sequence"},
+	}
+
+	for _, tt := range tests {
+		l := &lexer{data: []byte(tt.escape)}
+
+		if _, err := l.lexEscapedRune(); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("\\%s: expected error %q, got %v", tt.escape, tt.err, err)
+		}
+	}
+}
+
+func TestLexProperty(t *testing.T) {
+	tests := []struct {
+		src  string
+		ok   bool
+		pos  int
+		in   rune
+		out  rune
+		fail bool
+	}{
+		{src: `\pLx`, ok: true, pos: 3, in: 'a', out: '1'},
+		{src: `\p{Lu}x`, ok: true, pos: 6, in: 'A', out: 'a'},
+		{src: `\P{Lu}x`, ok: true, pos: 6, in: 'a', out: 'A'},
+		{src: `\p{Cyrillic}`, ok: true, pos: 12, in: 'ж', out: 'z'},
+		{src: `\PN`, ok: true, pos: 3, in: 'a', out: '٣'},
+		{src: `\d`},
+		{src: `p{L}`},
+		{src: `\p{Nope}`, fail: true},
+	}
+
+	for _, tt := range tests {
+		l := &lexer{data: []byte(tt.src)}
+
+		set, ok, err := l.lexProperty()
+		if (err != nil) != tt.fail {
+			t.Errorf("%s: unexpected error %v", tt.src, err)
+			continue
+		}
+
+		if ok != tt.ok {
+			t.Errorf("%s: expected ok to be %v", tt.src, tt.ok)
+			continue
+		}
+
+		if !ok {
+			if l.pos != 0 {
+				t.Errorf("%s: expected nothing to be read, stopped at %d", tt.src, l.pos)
+			}
+			continue
+		}
+
+		if l.pos != tt.pos {
+			t.Errorf("%s: expected to stop at %d, stopped at %d", tt.src, tt.pos, l.pos)
+		}
+
+		set.normalize()
+
+		if !contains(set, tt.in) || contains(set, tt.out) {
+			t.Errorf("%s: expected %q to be matched and %q not to be", tt.src, tt.in, tt.out)
+		}
+	}
+}
+
+func TestNegate(t *testing.T) {
+	set := runeSet{{'b', 'd'}, {0, 'a'}, {0x10FFF0, unicode.MaxRune}}
+	set.negate()
+
+	expected := runeSet{{'e', 0x10FFEF}}
+	if len(set) != len(expected) || set[0] != expected[0] {
+		t.Errorf("expected %v, got %v", expected, set)
+	}
+
+	set.negate()
+
+	expected = runeSet{{0, 'd'}, {0x10FFF0, unicode.MaxRune}}
+	if len(set) != len(expected) || set[0] != expected[0] || set[1] != expected[1] {
+		t.Errorf("expected %v, got %v", expected, set)
+	}
+}
+
+func TestNfaFromRuneSetExcludesNul(t *testing.T) {
+	nfa := newNfaFromRuneSet(runeSet{{0, 'b'}, {'é', 'é'}})
+
+	if nfa.Initial.Transitions[0] != nil {
+		t.Errorf("expected NUL not to be matched, since its byte denotes empty transitions")
+	}
+
+	for _, c := range []byte{1, 'a', 'b', 0xC3} {
+		if nfa.Initial.Transitions[c] == nil {
+			t.Errorf("expected a transition on byte %#x", c)
+		}
+	}
+
+	if nfa.Initial.Transitions['c'] != nil {
+		t.Errorf("expected no transition on byte 'c'")
+	}
+}
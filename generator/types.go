@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// resolveTypes computes the type of the semantic value of every token of the transformed grammar.
+// Nonterminals that were merged together must declare the same type, or none at all.
+func (p *grammarDescription) resolveTypes() error {
+	p.valueTypes = make(map[string]string)
+
+	for _, token := range p.terminals.Iter {
+		if typ, ok := p.types[token]; ok {
+			p.valueTypes[token] = typ
+		}
+	}
+
+	for _, token := range p.nonterminals.Iter {
+		origins, ok := p.merged[token]
+		if !ok {
+			origins = []string{token}
+		}
+
+		typ, typed := p.types[origins[0]]
+		for _, origin := range origins[1:] {
+			if otherTyp, otherTyped := p.types[origin]; otherTyped != typed || otherTyp != typ {
+				return fmt.Errorf("nonterminals %s are merged into %s but have different types", strings.Join(origins, ", "), token)
+			}
+		}
+
+		if typed {
+			p.valueTypes[token] = typ
+		}
+	}
+
+	return nil
+}
+
+// isPointerType reports whether typ is a pointer type, whose values are stored in tokens as they are.
+// Values of any other type are stored as pointers to memory taken from per-thread pools.
+func isPointerType(typ string) bool {
+	return strings.HasPrefix(typ, "*")
+}
+
+// pooledTypes returns the distinct types of semantic values that need pools, sorted.
+func (p *grammarDescription) pooledTypes() []string {
+	types := make([]string, 0)
+	for _, typ := range p.valueTypes {
+		if !isPointerType(typ) && !slices.Contains(types, typ) {
+			types = append(types, typ)
+		}
+	}
+
+	slices.Sort(types)
+
+	return types
+}
+
+// emitRunState writes the parserRunState type, holding the pools of typed semantic values used during a single run,
+// and its constructor. stateType is the type of the state returned by the preamble function, if any.
+func (p *grammarDescription) emitRunState(f io.Writer, stateType string, pooled []string) {
+	fmt.Fprintf(f, "// parserRunState holds the memory used by semantic actions during a single run.\n")
+	fmt.Fprintf(f, "type parserRunState struct {\n")
+	if stateType != "" {
+		fmt.Fprintf(f, "\tstate %s\n\n", stateType)
+	}
+	for i, typ := range pooled {
+		fmt.Fprintf(f, "\tvalues%d []*gopapageno.Pool[%s]\n", i, typ)
+	}
+	fmt.Fprintf(f, "}\n\n")
+
+	fmt.Fprintf(f, "func newParserRunState(sourceLen int, concurrency int, avgTokenLength int) *parserRunState {\n")
+	fmt.Fprintf(f, "\ts := &parserRunState{}\n")
+	if stateType != "" {
+		fmt.Fprintf(f, "\ts.state = %s(sourceLen, concurrency)\n", p.preambleFunc)
+	}
+	fmt.Fprintf(f, "\n\tpoolSizePerThread := sourceLen / avgTokenLength / concurrency\n\n")
+	for i, typ := range pooled {
+		fmt.Fprintf(f, "\ts.values%d = make([]*gopapageno.Pool[%s], concurrency)\n", i, typ)
+	}
+	fmt.Fprintf(f, "\tfor thread := 0; thread < concurrency; thread++ {\n")
+	for i, typ := range pooled {
+		fmt.Fprintf(f, "\t\ts.values%d[thread] = gopapageno.NewPool[%s](poolSizePerThread)\n", i, typ)
+	}
+	fmt.Fprintf(f, "\t}\n\n")
+	fmt.Fprintf(f, "\treturn s\n")
+	fmt.Fprintf(f, "}\n\n")
+}
+
+// emitTypedValues writes the variables holding the typed semantic values of the tokens of rule,
+// and returns action with every reference to those values replaced by them.
+func (p *grammarDescription) emitTypedValues(f io.Writer, rule ruleDescription, action string) string {
+	if typ, ok := p.valueTypes[rule.LHS]; ok {
+		fmt.Fprintf(f, "\t\t\t%s0Value := gopapageno.ValueOf[%s](%s0)\n", rule.LHS, typ, rule.LHS)
+		action = strings.ReplaceAll(action, "$$.Value", rule.LHS+"0Value")
+	}
+
+	for j, token := range rule.RHS {
+		if typ, ok := p.valueTypes[token]; ok {
+			fmt.Fprintf(f, "\t\t\t%s%dValue := gopapageno.ValueOf[%s](%s%d)\n", token, j+1, typ, token, j+1)
+			action = strings.ReplaceAll(action, fmt.Sprintf("$%d.Value", j+1), fmt.Sprintf("%s%dValue", token, j+1))
+		}
+	}
+
+	return action
+}
+
+// emitTypedStore writes the statements storing the typed semantic value of the left-hand side of rule into its token.
+func (p *grammarDescription) emitTypedStore(f io.Writer, rule ruleDescription, pooled []string) {
+	for j, token := range rule.RHS {
+		if _, ok := p.valueTypes[token]; ok {
+			fmt.Fprintf(f, "\t\t\t_ = %s%dValue\n", token, j+1)
+		}
+	}
+
+	typ, ok := p.valueTypes[rule.LHS]
+	if !ok {
+		return
+	}
+
+	if isPointerType(typ) {
+		fmt.Fprintf(f, "\t\t\t%s0.Value = %s0Value\n", rule.LHS, rule.LHS)
+		return
+	}
+
+	fmt.Fprintf(f, "\t\t\tgopapageno.SetValue(%s0, %s0Value, rs.values%d[thread])\n", rule.LHS, rule.LHS, slices.Index(pooled, typ))
+}
@@ -2,8 +2,84 @@ package generator
 
 import (
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io"
+	"strings"
 )
 
+// preambleStateType returns the type of the value returned by the preamble function called name, declared in code.
+// Preamble functions returning a value build the state of a single run, which is passed to semantic actions.
+// It returns an empty string if the function returns nothing, or if it isn't declared in code.
+func preambleStateType(code string, name string) (string, error) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "", "package preamble\n"+code, parser.SkipObjectResolution)
+	if err != nil {
+		return "", fmt.Errorf("could not parse code section: %w", err)
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Name.Name != name {
+			continue
+		}
+
+		results := fn.Type.Results
+		if results.NumFields() == 0 {
+			return "", nil
+		}
+
+		if results.NumFields() > 1 {
+			return "", fmt.Errorf("preamble function %s must return a single value", name)
+		}
+
+		var sb strings.Builder
+		if err := printer.Fprint(&sb, fset, results.List[0].Type); err != nil {
+			return "", fmt.Errorf("could not print result type of preamble function %s: %w", name, err)
+		}
+
+		return sb.String(), nil
+	}
+
+	return "", nil
+}
+
+// emitPreamble writes the field of a Lexer or Grammar holding the preamble function called name.
+// Functions returning a value are wrapped into a StateFunc.
+func emitPreamble(f io.Writer, name string, stateType string) {
+	if stateType == "" {
+		fmt.Fprintf(f, "\t\tPreambleFunc: %s,\n", name)
+		return
+	}
+
+	fmt.Fprintf(f, "\t\tStateFunc: func(sourceLen, concurrency, _ int) any {\n")
+	fmt.Fprintf(f, "\t\t\treturn %s(sourceLen, concurrency)\n", name)
+	fmt.Fprintf(f, "\t\t},\n")
+}
+
+// emitStateAssertion writes the statements giving semantic actions access to the state of the run as `state`.
+func emitStateAssertion(f io.Writer, stateType string) {
+	if stateType == "" {
+		return
+	}
+
+	fmt.Fprintf(f, "\t\tstate := runState.(%s)\n", stateType)
+	fmt.Fprintf(f, "\t\t_ = state\n\n")
+}
+
+// emitThreadStateAssertion writes the statements giving lexer actions access to the state of their thread as `threadState`.
+func emitThreadStateAssertion(f io.Writer, stateType string) {
+	if stateType == "" {
+		return
+	}
+
+	fmt.Fprintf(f, "\t\tthreadState := runThreadState.(%s)\n", stateType)
+	fmt.Fprintf(f, "\t\t_ = threadState\n\n")
+}
+
 func skipSpaces(input string, index *int) {
 	for *index < len(input) &&
 		(input[*index] == ' ' ||
@@ -15,7 +15,7 @@ type parseResult[S any] struct {
 	stack     *S
 }
 
-type ParserFunc func(rule uint16, ruleType RuleFlags, lhs *Token, rhs []*Token, thread int)
+type ParserFunc func(rule uint16, ruleType RuleFlags, lhs *Token, rhs []*Token, thread int, state any)
 
 // A ReductionStrategy defines which kind of algorithm should be executed
 // when collecting and running multiple parsing passes.
@@ -82,8 +82,15 @@ type Grammar struct {
 	PrecedenceMatrix          [][]Precedence
 	BitPackedPrecedenceMatrix []uint64
 
+	// TokenNames maps token types to the names they were declared with. It is only used to describe errors.
+	TokenNames map[TokenType]string
+
+	// SyncTerminals are the terminals on which parsers resynchronize after a syntax error when recovering from errors.
+	SyncTerminals []TokenType
+
 	Func         ParserFunc
 	PreambleFunc PreambleFunc
+	StateFunc    StateFunc
 
 	ParsingStrategy ParsingStrategy
 }
@@ -92,14 +99,41 @@ type Parser interface {
 	Parse(ctx context.Context, tokensLists []*LOS[Token]) (*Token, error)
 }
 
+// A reusableParser can be prepared for a new source, reusing the memory allocated for the previous ones.
+type reusableParser interface {
+	Parser
+	reset(srcLen int, opts *RunOptions)
+}
+
+// A streamParser parses the lists of tokens of a source as they are produced, before the whole source has been lexed.
+// The first pass runs on the lists of a batch as soon as the token following them is known, and its results are joined
+// into the input of a single final sweep, so that the stacks of the first pass can be reused by the next batch.
+type streamParser interface {
+	Parser
+	parseStream(ctx context.Context, batchCh <-chan tokensBatch) (*Token, error)
+}
+
+// A tokensBatch holds the lists of tokens lexed from a portion of the source, in order.
+type tokensBatch struct {
+	lists []*LOS[Token]
+
+	// srcLen is the length of the source lexed so far, including the portion.
+	srcLen int
+}
+
 func (g *Grammar) Parser(src []byte, opts *RunOptions) Parser {
+	return g.parser(len(src), opts)
+}
+
+// parser builds the Parser matching the grammar's strategy for a source of srcLen bytes.
+func (g *Grammar) parser(srcLen int, opts *RunOptions) Parser {
 	switch g.ParsingStrategy {
 	case OPP:
-		return NewOPParser(g, src, opts)
+		return newOPParser(g, srcLen, opts)
 	case AOPP:
-		return NewAOPParser(g, src, opts)
+		return newAOPParser(g, srcLen, opts)
 	case COPP:
-		return NewCOPParser(g, src, opts)
+		return newCOPParser(g, srcLen, opts)
 	default:
 		panic("unknown parser strategy")
 	}
@@ -128,14 +162,15 @@ func (g *Grammar) findRuleMatch(rhs []TokenType) (TokenType, uint16) {
 		}
 
 		pos++
-		low := uint16(0)
-		high := numIndices - 1
+		// Bounds are signed, since high drops below 0 when key precedes every index.
+		low := 0
+		high := int(numIndices) - 1
 		startPos := pos
 		foundNext := false
 
 		for low <= high {
 			indexPos := low + (high-low)/2
-			pos = startPos + indexPos*2
+			pos = startPos + uint16(indexPos)*2
 			curKey := g.CompressedRules[pos]
 
 			if uint16(key) < curKey {
@@ -168,14 +203,15 @@ func (g *Grammar) findPrefixMatch(rhs []TokenType) (TokenType, uint16) {
 		}
 
 		pos++
-		low := uint16(0)
-		high := numIndices - 1
+		// Bounds are signed, since high drops below 0 when key precedes every index.
+		low := 0
+		high := int(numIndices) - 1
 		startPos := pos
 		foundNext := false
 
 		for low <= high {
 			indexPos := low + (high-low)/2
-			pos = startPos + indexPos*2
+			pos = startPos + uint16(indexPos)*2
 			curKey := g.CompressedPrefixes[pos]
 
 			if uint16(key) < curKey {
@@ -210,3 +246,65 @@ func collectResults[S any](results []*S, resultCh <-chan parseResult[S], errCh <
 
 	return nil
 }
+
+// newTermToken returns a # delimiting the source at pos.
+func newTermToken(pos int) *Token {
+	return &Token{
+		Type:       TokenTerm,
+		Precedence: PrecEmpty,
+		Start:      pos,
+		End:        pos,
+	}
+}
+
+// lookahead returns a copy of the first token of list, or nil if it is empty.
+// The token is copied, since the worker parsing list modifies it.
+func lookahead(list *LOS[Token]) *Token {
+	t := list.HeadIterator().Next()
+	if t == nil {
+		return nil
+	}
+
+	next := *t
+	return &next
+}
+
+// A listStream holds back the last list of tokens received, until the token following it is known.
+type listStream struct {
+	pending *LOS[Token]
+}
+
+// add receives the lists of a batch, returning the ones that can be parsed in order alongside the token following them.
+// Empty lists are dropped.
+func (s *listStream) add(lists []*LOS[Token]) ([]*LOS[Token], *Token) {
+	var ready []*LOS[Token]
+
+	for _, l := range lists {
+		if l.Length() == 0 {
+			continue
+		}
+
+		if s.pending != nil {
+			ready = append(ready, s.pending)
+		}
+		s.pending = l
+	}
+
+	if len(ready) == 0 {
+		return nil, nil
+	}
+
+	return ready, lookahead(s.pending)
+}
+
+// flush returns the list held back once the source is over, or an empty one taken from pool if no list was received.
+func (s *listStream) flush(pool *Pool[stack[Token]]) *LOS[Token] {
+	if s.pending == nil {
+		return NewLOS[Token](pool)
+	}
+
+	l := s.pending
+	s.pending = nil
+
+	return l
+}
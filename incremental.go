@@ -0,0 +1,615 @@
+package gopapageno
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// An Edit describes a change to a source: Removed bytes starting at Offset are replaced by Inserted.
+type Edit struct {
+	Offset   int
+	Removed  int
+	Inserted []byte
+}
+
+// delta returns how much the offsets following the edit are shifted by.
+func (e Edit) delta() int {
+	return len(e.Inserted) - e.Removed
+}
+
+// An IncrementalParser updates parse trees after edits to their source, without parsing it again from scratch.
+//
+// Only the region surrounding an edit, delimited by cut points, is lexed again.
+// Its tokens replace the old ones within the smallest subtree containing them that can be reduced on its own:
+// the subtree is parsed again in isolation, moving up to its parent whenever its new tokens don't reduce to the same nonterminal,
+// and the semantic actions of copies of its ancestors are run again to compute their new values.
+// Only the new subtree, its ancestors and their children are new tokens: the updated tree shares every other subtree
+// with the previous one, which is never modified, so that the cost of an update grows with the size of the edit and
+// the depth of the tree rather than with the size of the source.
+// Subtrees following an edit that changes the length of the source are moved through the Shift of their copied roots.
+//
+// Since operator precedence parsing is only local for OPP grammars, other strategies are not supported.
+type IncrementalParser struct {
+	runner *Runner
+}
+
+// ErrIncrementalStrategy is returned when creating an IncrementalParser for a grammar that doesn't use the OPP strategy.
+var ErrIncrementalStrategy = errors.New("incremental parsing requires the OPP strategy")
+
+// NewIncrementalParser returns an IncrementalParser using the lexer, grammar and options of r.
+// Trees it updates must not be produced by runs reusing pools, since they would be overwritten by later runs.
+func NewIncrementalParser(r *Runner) (*IncrementalParser, error) {
+	if r.Parser.ParsingStrategy != OPP {
+		return nil, ErrIncrementalStrategy
+	}
+
+	return &IncrementalParser{
+		runner: r,
+	}, nil
+}
+
+// Parse parses src from scratch, returning a tree that can be updated by Reparse.
+func (p *IncrementalParser) Parse(ctx context.Context, src []byte) (*Token, error) {
+	return p.runner.Run(ctx, src)
+}
+
+// Reparse updates root, the parse tree of a source, after edit was applied to it.
+// src is the source after the edit.
+//
+// It returns a new tree, in which tokens following the edit have their offsets shifted, directly or through the Shift
+// of an ancestor, and the ancestors of the replaced subtree have new values.
+// root is left untouched, and remains valid whether Reparse succeeds or not.
+// When no subtree can be replaced, src is parsed again from scratch.
+func (p *IncrementalParser) Reparse(ctx context.Context, root *Token, src []byte, edit Edit) (*Token, error) {
+	if root == nil {
+		return p.Parse(ctx, src)
+	}
+
+	if edit.Offset < 0 || edit.Removed < 0 || edit.Offset+len(edit.Inserted) > len(src) {
+		return nil, fmt.Errorf("edit at offset %d is out of the bounds of the source", edit.Offset)
+	}
+
+	token, ok, err := p.reparse(ctx, root, src, edit)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return p.Parse(ctx, src)
+	}
+
+	return token, nil
+}
+
+// reparse updates root locally, reporting false if no subtree can be replaced.
+func (p *IncrementalParser) reparse(ctx context.Context, root *Token, src []byte, edit Edit) (*Token, bool, error) {
+	opts, cleanupFunc := p.runner.prepare()
+	defer cleanupFunc()
+
+	opts.Concurrency = 1
+
+	// The start condition in which the lexer is at the cut point preceding the edit is unknown,
+	// and lexemes read by hand may span cut points.
+	if p.runner.Lexer.ModeAutomata != nil || p.runner.Lexer.Source != nil {
+		return nil, false, nil
+	}
+
+	tree := treePath{{token: root}}
+
+	// Lex the region between the cut points surrounding the edit.
+	start := p.runner.Lexer.prevCutPoint(src, edit.Offset)
+
+	end, ok := p.runner.Lexer.nextCutPoint(src, edit.Offset+len(edit.Inserted))
+	if !ok {
+		end = len(src)
+	}
+
+	// Old leaves lexed again are the ones beginning within the region, in the coordinates of the previous source.
+	// The ones surrounding them are kept too: before precedes the region, and after follows it.
+	var region []treePath
+
+	after, ok := tree.leafFrom(start)
+	for ok && after.start() < end-edit.delta() {
+		region = append(region, after)
+		after, ok = after.next()
+	}
+	if !ok {
+		after = nil
+	}
+
+	var before treePath
+	switch {
+	case len(region) > 0:
+		before, _ = region[0].prev()
+	case after != nil:
+		before, _ = after.prev()
+	default:
+		if last := tree.lastLeaf(); last.token().IsTerminal() {
+			before = last
+		}
+	}
+
+	// The region is lexed after the leaf preceding it, which lexer actions may depend on.
+	var prev *Token
+	if before != nil {
+		leaf := leafCopy(before.token(), before.shift())
+		prev = &leaf
+	}
+
+	tokens, err := p.lex(ctx, src, start, end, prev, opts)
+	if err != nil {
+		locateError(err, NewSourceMap(src))
+		p.runner.report(err)
+		return nil, false, fmt.Errorf("could not lex: %w", err)
+	}
+
+	// Tokens lexed just like before at the edges of the region don't need to be replaced.
+	// Before the edit, the one following them must match too, since it may have been looked ahead at.
+	for len(region) > 1 && len(tokens) > 1 && region[1].end() < edit.Offset &&
+		sameToken(region[0], &tokens[0], 0) && sameToken(region[1], &tokens[1], 0) {
+		before = region[0]
+		region = region[1:]
+		tokens = tokens[1:]
+	}
+
+	for len(region) > 0 && len(tokens) > 0 && tokens[len(tokens)-1].Start > edit.Offset+len(edit.Inserted) &&
+		sameToken(region[len(region)-1], &tokens[len(tokens)-1], edit.delta()) {
+		after = region[len(region)-1]
+		region = region[:len(region)-1]
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	g := p.runner.Parser
+	if g.PreambleFunc != nil {
+		g.PreambleFunc(len(src), opts.Concurrency)
+	}
+
+	// When no old leaf is replaced, the ones surrounding the insertion are included instead.
+	lo, hi := before, after
+	if len(region) > 0 {
+		lo, hi = region[0], region[len(region)-1]
+	}
+
+	if lo == nil {
+		lo = hi
+	} else if hi == nil {
+		hi = lo
+	}
+
+	if lo == nil {
+		return nil, false, nil
+	}
+
+	// Tokens replace the leaves from the first one of the region to the one following it.
+	var replaced replacedLeaves
+	if len(region) > 0 {
+		replaced.first = region[0].token()
+	} else if after != nil {
+		replaced.first = after.token()
+	}
+	if after != nil {
+		replaced.last = after.token()
+	}
+
+	// The same parser is used for every subtree climbed through.
+	parser := segmentParser{g: g, opts: opts, srcLen: len(src)}
+
+	// Climb from the smallest subtree containing the replaced leaves until one can be parsed again on its own.
+	for path := commonAncestor(lo, hi); len(path) > 0; path = path[:len(path)-1] {
+		isRoot := len(path) == 1
+
+		segment := newSegment(path, replaced, tokens, edit.delta())
+		if len(segment) == 0 || !fits(g, path, segment) {
+			continue
+		}
+
+		token, err := parser.parse(ctx, segment)
+		if err != nil && isRoot {
+			locateError(err, NewSourceMap(src))
+			p.runner.report(err)
+			return nil, false, fmt.Errorf("could not parse: %w", err)
+		}
+
+		if err != nil || token == nil || !isRoot && token.Type != path.token().Type {
+			continue
+		}
+
+		// The new subtree must span the whole segment.
+		if token.Start != segment[0].Start || token.End != segment[len(segment)-1].End {
+			continue
+		}
+
+		token, err = replace(g, path, token, edit.delta(), parser.state())
+		if err != nil {
+			return nil, false, fmt.Errorf("could not parse: %w", err)
+		}
+
+		return token, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// lex tokenizes src[start:end] following the token prev, returning tokens with offsets relative to the whole source.
+func (p *IncrementalParser) lex(ctx context.Context, src []byte, start int, end int, prev *Token, opts *RunOptions) ([]Token, error) {
+	if p.runner.Lexer.PreambleFunc != nil {
+		p.runner.Lexer.PreambleFunc(end-start, opts.Concurrency)
+	}
+
+	scanner := p.runner.Lexer.Scanner(src[start:end], opts)
+	scanner.offset = start
+	scanner.lineStart = start == 0 || src[start-1] == '\n'
+	scanner.lineEnd = end == len(src) || isLineBreak(src[end])
+	scanner.prev = prev
+
+	lists, err := scanner.Lex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]Token, 0)
+	for _, l := range lists {
+		it := l.HeadIterator()
+		for token := it.Next(); token != nil; token = it.Next() {
+			tokens = append(tokens, *token)
+		}
+	}
+
+	return tokens, nil
+}
+
+// A segmentParser parses sequences of terminals on their own, reusing the same parser and pools for all of them.
+// They are sized after the first segment parsed, and grow along with the following ones.
+type segmentParser struct {
+	g    *Grammar
+	opts *RunOptions
+
+	// srcLen is the length of the whole source, at which the end of every segment is placed.
+	srcLen int
+
+	parser *OPParser
+	input  *Pool[stack[Token]]
+}
+
+// parse parses segment, invalidating the tree returned by the previous call.
+func (s *segmentParser) parse(ctx context.Context, segment []Token) (*Token, error) {
+	segmentLen := segment[len(segment)-1].End - segment[0].Start
+
+	if s.parser == nil {
+		s.parser = newOPParser(s.g, segmentLen, s.opts)
+		s.input = NewPool(stacksCount[Token](segmentLen, 1, s.opts.AvgTokenLength), WithConstructor(newStack[Token]))
+	} else {
+		s.parser.reset(segmentLen, s.opts)
+		s.input.Reset()
+	}
+
+	// Errors found at the end of a segment spanning the whole source are reported at its end.
+	s.parser.srcLen = s.srcLen
+
+	los := NewLOS[Token](s.input)
+	for _, token := range segment {
+		los.Push(token)
+	}
+
+	return s.parser.Parse(ctx, []*LOS[Token]{los})
+}
+
+// state returns the state passed to the semantic actions of the last segment parsed.
+func (s *segmentParser) state() any {
+	return s.parser.state
+}
+
+// prevCutPoint returns the position at which the last match of the cut points automaton beginning before pos begins,
+// or 0 if there is none. Since the source before pos is unchanged by an edit at pos, so are the tokens preceding it.
+func (l *Lexer) prevCutPoint(src []byte, pos int) int {
+	for window := 1024; ; window *= 2 {
+		from := max(pos-window, 0)
+
+		cutPoint := -1
+		for p, ok := l.nextCutPoint(src, from); ok && p < pos; p, ok = l.nextCutPoint(src, p+1) {
+			cutPoint = p
+		}
+
+		if cutPoint >= 0 {
+			return cutPoint
+		}
+
+		if from == 0 {
+			return 0
+		}
+	}
+}
+
+// A treePath leads from the root of a tree to one of its tokens, the last one.
+// Along with each token, it holds the sum of the shifts of its ancestors, which applies to its offsets.
+// Paths let the part of a tree surrounding an edit be found without visiting the rest of it.
+type treePath []pathStep
+
+type pathStep struct {
+	token *Token
+	shift int
+}
+
+// token returns the token the path leads to.
+func (p treePath) token() *Token {
+	return p[len(p)-1].token
+}
+
+// shift returns the shift applying to the offsets of the token the path leads to.
+func (p treePath) shift() int {
+	return p[len(p)-1].shift
+}
+
+// start returns the offset of the first byte of the token the path leads to.
+func (p treePath) start() int {
+	return p.token().Start + p.shift()
+}
+
+// end returns the offset of the last byte of the token the path leads to.
+func (p treePath) end() int {
+	return p.token().End + p.shift()
+}
+
+// child returns the path extended to child, a child of the token p leads to.
+// The path is copied, so that paths sharing a prefix never share their steps.
+func (p treePath) child(child *Token) treePath {
+	parent := p[len(p)-1]
+
+	c := make(treePath, len(p), len(p)+1)
+	copy(c, p)
+
+	return append(c, pathStep{token: child, shift: parent.shift + parent.token.Shift})
+}
+
+// firstLeaf returns the path extended to the first leaf of the token p leads to.
+func (p treePath) firstLeaf() treePath {
+	for !p.token().IsTerminal() && p.token().Child != nil {
+		p = p.child(p.token().Child)
+	}
+
+	return p
+}
+
+// lastLeaf returns the path extended to the last leaf of the token p leads to.
+func (p treePath) lastLeaf() treePath {
+	for !p.token().IsTerminal() && p.token().LastChild != nil {
+		p = p.child(p.token().LastChild)
+	}
+
+	return p
+}
+
+// next returns the path to the leaf following the last token of p, reporting false if there is none.
+func (p treePath) next() (treePath, bool) {
+	for i := len(p) - 1; i > 0; i-- {
+		parent, token := p[i-1].token, p[i].token
+		if token != parent.LastChild && token.Next != nil {
+			return p[:i].child(token.Next).firstLeaf(), true
+		}
+	}
+
+	return nil, false
+}
+
+// prev returns the path to the leaf preceding the last token of p, reporting false if there is none.
+func (p treePath) prev() (treePath, bool) {
+	for i := len(p) - 1; i > 0; i-- {
+		parent, token := p[i-1].token, p[i].token
+		if token == parent.Child {
+			continue
+		}
+
+		sibling := parent.Child
+		for sibling.Next != token {
+			sibling = sibling.Next
+		}
+
+		return p[:i].child(sibling).lastLeaf(), true
+	}
+
+	return nil, false
+}
+
+// leafFrom returns the path to the first leaf of the token p leads to beginning at pos or after it,
+// reporting false if there is none.
+func (p treePath) leafFrom(pos int) (treePath, bool) {
+	// Every leaf of the children preceding the one descended into ends before pos.
+	for token := p.token(); !token.IsTerminal() && token.Child != nil; token = p.token() {
+		shift := p.shift() + token.Shift
+
+		child := token.Child
+		for child != token.LastChild && child.End+shift < pos {
+			child = child.Next
+		}
+
+		p = p.child(child)
+	}
+
+	if !p.token().IsTerminal() {
+		return nil, false
+	}
+
+	if p.start() >= pos {
+		return p, true
+	}
+
+	return p.next()
+}
+
+// commonAncestor returns the path to the lowest nonterminal whose leaves include those lo and hi lead to.
+func commonAncestor(lo treePath, hi treePath) treePath {
+	n := 0
+	for n < len(lo) && n < len(hi) && lo[n].token == hi[n].token {
+		n++
+	}
+
+	for n > 1 && lo[n-1].token.IsTerminal() {
+		n--
+	}
+
+	return lo[:n:n]
+}
+
+// replacedLeaves delimits the leaves replaced by new tokens: they go from first, included, to last, excluded.
+// When first is last, no leaf is replaced and the tokens are inserted before it; when first is nil, they follow every leaf.
+type replacedLeaves struct {
+	first *Token
+	last  *Token
+}
+
+// newSegment returns the terminals that replace the leaves of the token path leads to, where those delimited by replaced
+// are replaced by tokens. Leaves following them are shifted by delta.
+func newSegment(path treePath, replaced replacedLeaves, tokens []Token, delta int) []Token {
+	segment := make([]Token, 0, len(tokens))
+
+	inserted, replacing, shifted := false, false, false
+
+	var visit func(token *Token, shift int)
+	visit = func(token *Token, shift int) {
+		if token.IsTerminal() {
+			if token == replaced.first {
+				segment = append(segment, tokens...)
+				inserted, replacing = true, true
+			}
+
+			if token == replaced.last {
+				replacing, shifted = false, true
+			}
+
+			switch {
+			case shifted:
+				segment = append(segment, leafCopy(token, shift+delta))
+			case !replacing:
+				segment = append(segment, leafCopy(token, shift))
+			}
+
+			return
+		}
+
+		for child := token.Child; child != nil; child = child.Next {
+			visit(child, shift+token.Shift)
+
+			if child == token.LastChild {
+				break
+			}
+		}
+	}
+
+	visit(path.token(), path.shift())
+
+	if !inserted {
+		segment = append(segment, tokens...)
+	}
+
+	return segment
+}
+
+// fits reports whether a subtree spanning segment can replace the one path leads to,
+// that is, whether the terminals surrounding it yield to its first terminal and take precedence over its last one.
+func fits(g *Grammar, path treePath, segment []Token) bool {
+	if prev, ok := path.firstLeaf().prev(); ok && !g.hasRelation(prev.token().Type, segment[0].Type, PrecYields) {
+		return false
+	}
+
+	if next, ok := path.lastLeaf().next(); ok && !g.hasRelation(segment[len(segment)-1].Type, next.token().Type, PrecTakes) {
+		return false
+	}
+
+	return true
+}
+
+// replace returns the root of a new tree in which token takes the place of the one path leads to, running again the
+// semantic actions of copies of its ancestors, which link them to their new children.
+// Only the siblings of the replaced token and of its ancestors are copied: their subtrees are shared with the previous
+// tree, which is left untouched, and those following the edit are shifted by delta through their Shift.
+func replace(g *Grammar, path treePath, token *Token, delta int, state any) (*Token, error) {
+	for i := len(path) - 2; i >= 0; i-- {
+		ancestor, old := path[i].token, path[i+1].token
+
+		// Children of the ancestor are copied with the offsets they have in the source.
+		shift := path[i].shift + ancestor.Shift
+
+		children := make([]*Token, 0)
+
+		replaced := false
+		for child := ancestor.Child; child != nil; child = child.Next {
+			switch {
+			case child == old:
+				children = append(children, token)
+				replaced = true
+			case replaced:
+				children = append(children, shiftedCopy(child, shift+delta))
+			default:
+				children = append(children, shiftedCopy(child, shift))
+			}
+
+			if child == ancestor.LastChild {
+				break
+			}
+		}
+
+		rhs := make([]TokenType, len(children))
+		for i, child := range children {
+			rhs[i] = child.Type
+		}
+
+		lhs, ruleNum := g.findRuleMatch(rhs)
+		if lhs != ancestor.Type {
+			return nil, fmt.Errorf("no rule reduces %s to %s", g.sprintTypes(rhs), g.TokenName(ancestor.Type))
+		}
+
+		// The value of the ancestor is computed from scratch, since actions may store it in the memory it already holds.
+		parent := &Token{
+			Type:       ancestor.Type,
+			Precedence: ancestor.Precedence,
+		}
+
+		parent.setSpan(children)
+		g.Func(ruleNum, RuleSimple, parent, children, 0, state)
+
+		token = parent
+	}
+
+	return token, nil
+}
+
+// hasRelation reports whether t1 and t2 are in relation prec, or in an associative one.
+func (g *Grammar) hasRelation(t1 TokenType, t2 TokenType, prec Precedence) bool {
+	if !g.hasPrecedence(t1, t2) {
+		return false
+	}
+
+	actual := g.precedence(t1, t2)
+	return actual == prec || actual == PrecAssociative
+}
+
+// sameToken reports whether token is the leaf old leads to, shifted by delta.
+func sameToken(old treePath, token *Token, delta int) bool {
+	return old.token().Type == token.Type && old.start()+delta == token.Start && old.end()+delta == token.End
+}
+
+// shiftedCopy returns a detached copy of token, with its offsets shifted by delta.
+// Its subtree is shared, its Shift accounting for the offsets of its descendants.
+func shiftedCopy(token *Token, delta int) *Token {
+	c := *token
+	c.Next = nil
+	c.Start += delta
+	c.End += delta
+
+	if c.Child != nil {
+		c.Shift += delta
+	}
+
+	return &c
+}
+
+// leafCopy returns a detached copy of a leaf, with its offsets shifted by delta.
+func leafCopy(leaf *Token, delta int) Token {
+	return Token{
+		Type:  leaf.Type,
+		Value: leaf.Value,
+		Start: leaf.Start + delta,
+		End:   leaf.End + delta,
+	}
+}
@@ -0,0 +1,30 @@
+package gopapageno
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLexer_prevCutPoint(t *testing.T) {
+	src := []byte("ab\ncd\n" + strings.Repeat("x", 3000) + "\nend")
+	lexer := &Lexer{CutPointsAutomaton: newlineCutPoints()}
+
+	tests := []struct {
+		pos  int
+		want int
+	}{
+		{0, 0},
+		{2, 0},
+		{3, 2},
+		{5, 2},
+		{6, 5},
+		{3006, 5},
+		{len(src), 3006},
+	}
+
+	for _, tt := range tests {
+		if got := lexer.prevCutPoint(src, tt.pos); got != tt.want {
+			t.Errorf("prevCutPoint(%d) = %d, want %d", tt.pos, got, tt.want)
+		}
+	}
+}
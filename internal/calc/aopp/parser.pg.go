@@ -0,0 +1,899 @@
+// Code generated by Gopapageno; DO NOT EDIT.
+package aopp
+
+import (
+	"github.com/giornetta/gopapageno"
+	"strings"
+	"fmt"
+	"os"
+)
+
+
+// Non-terminals
+const (
+	E_F_Statements_T = gopapageno.TokenEmpty + 1 + iota
+	E_Statements
+	E_Statements_T
+	Program
+	Statements
+)
+
+// Terminals
+const (
+	LPAR = gopapageno.TokenTerm + 1 + iota
+	NUMBER
+	PLUS
+	RPAR
+	SEMICOLON
+	TIMES
+)
+
+func DumpGraph[ValueType any](root *gopapageno.Token, f *os.File) {
+	sb := strings.Builder{}
+	sb.WriteString("digraph parse_tree {\n")
+	sb.WriteString("ratio = fill;\n")
+	sb.WriteString("node [style=filled];\n")
+
+	var graphPrintRec func(t *gopapageno.Token, p *gopapageno.Token, sb *strings.Builder, i int)
+	graphPrintRec = func(t *gopapageno.Token, p *gopapageno.Token, sb *strings.Builder, i int) {
+		if t == nil {
+			return
+		}
+
+		if p == nil {
+			graphPrintRec(t.Child, t, sb, i+1)
+			return
+		}
+
+		var t_name, t_color, p_name, p_color string
+
+		switch p.Type {
+		case E_F_Statements_T:
+			p_name, p_color = "E_F_Statements_T", "0.408 0.498 1.000"
+		case E_Statements:
+			p_name, p_color = "E_Statements", "0.408 0.498 1.000"
+		case E_Statements_T:
+			p_name, p_color = "E_Statements_T", "0.408 0.498 1.000"
+		case Program:
+			p_name, p_color = "Program", "0.408 0.498 1.000"
+		case Statements:
+			p_name, p_color = "Statements", "0.408 0.498 1.000"
+		case gopapageno.TokenEmpty:
+			p_name, p_color = "__EMPTY__", "0.408 0.498 1.000"
+		case LPAR:
+			p_name, p_color = "LPAR", "0.641 0.212 1.000"
+		case NUMBER:
+			p_name, p_color = "NUMBER", "0.641 0.212 1.000"
+		case PLUS:
+			p_name, p_color = "PLUS", "0.641 0.212 1.000"
+		case RPAR:
+			p_name, p_color = "RPAR", "0.641 0.212 1.000"
+		case SEMICOLON:
+			p_name, p_color = "SEMICOLON", "0.641 0.212 1.000"
+		case TIMES:
+			p_name, p_color = "TIMES", "0.641 0.212 1.000"
+		case gopapageno.TokenTerm:
+			p_name, p_color = "__TERM__", "0.641 0.212 1.000"
+		}
+
+		switch t.Type {
+		case E_F_Statements_T:
+			t_name, t_color = "E_F_Statements_T", "0.408 0.498 1.000"
+		case E_Statements:
+			t_name, t_color = "E_Statements", "0.408 0.498 1.000"
+		case E_Statements_T:
+			t_name, t_color = "E_Statements_T", "0.408 0.498 1.000"
+		case Program:
+			t_name, t_color = "Program", "0.408 0.498 1.000"
+		case Statements:
+			t_name, t_color = "Statements", "0.408 0.498 1.000"
+		case gopapageno.TokenEmpty:
+			t_name, t_color = "__EMPTY__", "0.408 0.498 1.000"
+		case LPAR:
+			t_name, t_color = "LPAR", "0.641 0.212 1.000"
+		case NUMBER:
+			t_name, t_color = "NUMBER", "0.641 0.212 1.000"
+		case PLUS:
+			t_name, t_color = "PLUS", "0.641 0.212 1.000"
+		case RPAR:
+			t_name, t_color = "RPAR", "0.641 0.212 1.000"
+		case SEMICOLON:
+			t_name, t_color = "SEMICOLON", "0.641 0.212 1.000"
+		case TIMES:
+			t_name, t_color = "TIMES", "0.641 0.212 1.000"
+		case gopapageno.TokenTerm:
+			t_name, t_color = "__TERM__", "0.641 0.212 1.000"
+		}
+
+		sb.WriteString(fmt.Sprintf("\"%p\" -> \"%p\";\n", p, t))
+		sb.WriteString(fmt.Sprintf("\"%p\" [label=\"%s\" color=\"%s\"];\n", p, p_name, p_color))
+		sb.WriteString(fmt.Sprintf("\"%p\" [label=\"%s\" color=\"%s\"];\n", t, t_name, t_color))
+
+		graphPrintRec(t.Child, t, sb, i+1)
+		graphPrintRec(t.Next, p, sb, i)
+	}
+	graphPrintRec(root, nil, &sb, 0)
+	sb.WriteString("}\n")
+
+	fmt.Fprint(f, sb.String())
+}
+
+
+func SprintToken[ValueType any](root *gopapageno.Token) string {
+	var sprintRec func(t *gopapageno.Token, sb *strings.Builder, indent string)
+
+	sprintRec = func(t *gopapageno.Token, sb *strings.Builder, indent string) {
+		if t == nil {
+			return
+		}
+
+		sb.WriteString(indent)
+		if t.Next == nil {
+			sb.WriteString("└── ")
+			indent += "    "
+		} else {
+			sb.WriteString("├── ")
+			indent += "|   "
+		}
+
+		switch t.Type {
+		case E_F_Statements_T:
+			sb.WriteString("E_F_Statements_T")
+		case E_Statements:
+			sb.WriteString("E_Statements")
+		case E_Statements_T:
+			sb.WriteString("E_Statements_T")
+		case Program:
+			sb.WriteString("Program")
+		case Statements:
+			sb.WriteString("Statements")
+		case gopapageno.TokenEmpty:
+			sb.WriteString("Empty")
+		case LPAR:
+			sb.WriteString("LPAR")
+		case NUMBER:
+			sb.WriteString("NUMBER")
+		case PLUS:
+			sb.WriteString("PLUS")
+		case RPAR:
+			sb.WriteString("RPAR")
+		case SEMICOLON:
+			sb.WriteString("SEMICOLON")
+		case TIMES:
+			sb.WriteString("TIMES")
+		case gopapageno.TokenTerm:
+			sb.WriteString("Term")
+		default:
+			sb.WriteString("Unknown")
+		}
+
+		if t.Value != nil {
+			if v, ok := any(t.Value).(*ValueType); ok {
+				sb.WriteString(fmt.Sprintf(": %v", *v))
+			} else {
+				sb.WriteString(fmt.Sprintf("%v", v))
+			}
+		}
+		
+		sb.WriteString("\n")
+		
+		sprintRec(t.Child, sb, indent)
+		sprintRec(t.Next, sb, indent[:len(indent)-4])
+	}
+
+	var sb strings.Builder
+	
+	sprintRec(root, &sb, "")
+	
+	return sb.String()
+}
+// parserRunState holds the memory used by semantic actions during a single run.
+type parserRunState struct {
+	values0 []*gopapageno.Pool[int64]
+}
+
+func newParserRunState(sourceLen int, concurrency int, avgTokenLength int) *parserRunState {
+	s := &parserRunState{}
+
+	poolSizePerThread := sourceLen / avgTokenLength / concurrency
+
+	s.values0 = make([]*gopapageno.Pool[int64], concurrency)
+	for thread := 0; thread < concurrency; thread++ {
+		s.values0[thread] = gopapageno.NewPool[int64](poolSizePerThread)
+	}
+
+	return s
+}
+
+
+func NewGrammar() *gopapageno.Grammar {
+	numTerminals := uint16(7)
+	numNonTerminals := uint16(6)
+
+	maxRHSLen := 3
+	rules := []gopapageno.Rule{
+		{Program, []gopapageno.TokenType{E_F_Statements_T}, gopapageno.RuleSimple},
+		{E_Statements, []gopapageno.TokenType{E_F_Statements_T, PLUS, E_F_Statements_T}, gopapageno.RuleSimple},
+		{E_Statements, []gopapageno.TokenType{E_F_Statements_T, PLUS, E_Statements_T}, gopapageno.RuleSimple},
+		{Statements, []gopapageno.TokenType{E_F_Statements_T, SEMICOLON, E_F_Statements_T}, gopapageno.RuleSimple},
+		{Statements, []gopapageno.TokenType{E_F_Statements_T, SEMICOLON, E_Statements}, gopapageno.RuleSimple},
+		{Statements, []gopapageno.TokenType{E_F_Statements_T, SEMICOLON, E_Statements_T}, gopapageno.RuleSimple},
+		{E_Statements_T, []gopapageno.TokenType{E_F_Statements_T, TIMES, E_F_Statements_T}, gopapageno.RuleSimple},
+		{Program, []gopapageno.TokenType{E_Statements}, gopapageno.RuleSimple},
+		{E_Statements, []gopapageno.TokenType{E_Statements, PLUS, E_F_Statements_T}, gopapageno.RuleSimple},
+		{E_Statements, []gopapageno.TokenType{E_Statements, PLUS, E_Statements_T}, gopapageno.RuleSimple},
+		{Statements, []gopapageno.TokenType{E_Statements, SEMICOLON, E_F_Statements_T}, gopapageno.RuleSimple},
+		{Statements, []gopapageno.TokenType{E_Statements, SEMICOLON, E_Statements}, gopapageno.RuleSimple},
+		{Statements, []gopapageno.TokenType{E_Statements, SEMICOLON, E_Statements_T}, gopapageno.RuleSimple},
+		{Program, []gopapageno.TokenType{E_Statements_T}, gopapageno.RuleSimple},
+		{E_Statements, []gopapageno.TokenType{E_Statements_T, PLUS, E_F_Statements_T}, gopapageno.RuleSimple},
+		{E_Statements, []gopapageno.TokenType{E_Statements_T, PLUS, E_Statements_T}, gopapageno.RuleSimple},
+		{Statements, []gopapageno.TokenType{E_Statements_T, SEMICOLON, E_F_Statements_T}, gopapageno.RuleSimple},
+		{Statements, []gopapageno.TokenType{E_Statements_T, SEMICOLON, E_Statements}, gopapageno.RuleSimple},
+		{Statements, []gopapageno.TokenType{E_Statements_T, SEMICOLON, E_Statements_T}, gopapageno.RuleSimple},
+		{E_Statements_T, []gopapageno.TokenType{E_Statements_T, TIMES, E_F_Statements_T}, gopapageno.RuleSimple},
+		{Program, []gopapageno.TokenType{Statements}, gopapageno.RuleSimple},
+		{Statements, []gopapageno.TokenType{Statements, SEMICOLON, E_F_Statements_T}, gopapageno.RuleSimple},
+		{Statements, []gopapageno.TokenType{Statements, SEMICOLON, E_Statements}, gopapageno.RuleSimple},
+		{Statements, []gopapageno.TokenType{Statements, SEMICOLON, E_Statements_T}, gopapageno.RuleSimple},
+		{E_F_Statements_T, []gopapageno.TokenType{LPAR, E_F_Statements_T, RPAR}, gopapageno.RuleSimple},
+		{E_F_Statements_T, []gopapageno.TokenType{LPAR, E_Statements, RPAR}, gopapageno.RuleSimple},
+		{E_F_Statements_T, []gopapageno.TokenType{LPAR, E_Statements_T, RPAR}, gopapageno.RuleSimple},
+		{E_F_Statements_T, []gopapageno.TokenType{NUMBER}, gopapageno.RuleSimple},
+	}
+	compressedRules := []uint16{0, 0, 6, 1, 15, 2, 63, 3, 101, 5, 149, 32769, 172, 32770, 205, 4, 0, 3, 32771, 24, 32773, 37, 32774, 55, 0, 0, 2, 1, 31, 3, 34, 2, 1, 0, 2, 2, 0, 0, 0, 3, 1, 46, 2, 49, 3, 52, 5, 3, 0, 5, 4, 0, 5, 5, 0, 0, 0, 1, 1, 60, 3, 6, 0, 4, 7, 2, 32771, 70, 32773, 83, 0, 0, 2, 1, 77, 3, 80, 2, 8, 0, 2, 9, 0, 0, 0, 3, 1, 92, 2, 95, 3, 98, 5, 10, 0, 5, 11, 0, 5, 12, 0, 4, 13, 3, 32771, 110, 32773, 123, 32774, 141, 0, 0, 2, 1, 117, 3, 120, 2, 14, 0, 2, 15, 0, 0, 0, 3, 1, 132, 2, 135, 3, 138, 5, 16, 0, 5, 17, 0, 5, 18, 0, 0, 0, 1, 1, 146, 3, 19, 0, 4, 20, 1, 32773, 154, 0, 0, 3, 1, 163, 2, 166, 3, 169, 5, 21, 0, 5, 22, 0, 5, 23, 0, 0, 0, 3, 1, 181, 2, 189, 3, 197, 0, 0, 1, 32772, 186, 1, 24, 0, 0, 0, 1, 32772, 194, 1, 25, 0, 0, 0, 1, 32772, 202, 1, 26, 0, 1, 27, 0	}
+
+	precMatrix := [][]gopapageno.Precedence{
+		{gopapageno.PrecEquals, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields},
+		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecEquals, gopapageno.PrecEquals, gopapageno.PrecYields},
+		{gopapageno.PrecTakes, gopapageno.PrecEquals, gopapageno.PrecEquals, gopapageno.PrecTakes, gopapageno.PrecTakes, gopapageno.PrecTakes, gopapageno.PrecTakes},
+		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecTakes, gopapageno.PrecTakes, gopapageno.PrecTakes, gopapageno.PrecYields},
+		{gopapageno.PrecTakes, gopapageno.PrecEquals, gopapageno.PrecEquals, gopapageno.PrecTakes, gopapageno.PrecTakes, gopapageno.PrecTakes, gopapageno.PrecTakes},
+		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecEquals, gopapageno.PrecTakes, gopapageno.PrecYields},
+		{gopapageno.PrecTakes, gopapageno.PrecYields, gopapageno.PrecYields, gopapageno.PrecTakes, gopapageno.PrecTakes, gopapageno.PrecTakes, gopapageno.PrecTakes},
+	}
+	bitPackedMatrix := []uint64{
+		9397423250668361044, 11431974314, 
+	}
+
+	tokenNames := map[gopapageno.TokenType]string{
+		E_F_Statements_T: "E_F_Statements_T",
+		E_Statements: "E_Statements",
+		E_Statements_T: "E_Statements_T",
+		Program: "Program",
+		Statements: "Statements",
+		LPAR: "LPAR",
+		NUMBER: "NUMBER",
+		PLUS: "PLUS",
+		RPAR: "RPAR",
+		SEMICOLON: "SEMICOLON",
+		TIMES: "TIMES",
+	}
+
+	syncTerminals := []gopapageno.TokenType{SEMICOLON}
+
+	fn := func(ruleDescription uint16, ruleFlags gopapageno.RuleFlags, lhs *gopapageno.Token, rhs []*gopapageno.Token, thread int, runState any){
+		rs := runState.(*parserRunState)
+		_ = rs
+
+		switch ruleDescription {
+		case 0:
+			Program0 := lhs
+			E_F_Statements_T1 := rhs[0]
+
+			Program0.Child = E_F_Statements_T1
+			Program0.LastChild = E_F_Statements_T1
+
+			Program0Value := gopapageno.ValueOf[int64](Program0)
+			E_F_Statements_T1Value := gopapageno.ValueOf[int64](E_F_Statements_T1)
+			{
+			    Program0Value = E_F_Statements_T1Value
+			}
+			_ = E_F_Statements_T1Value
+			gopapageno.SetValue(Program0, Program0Value, rs.values0[thread])
+			_ = E_F_Statements_T1
+		case 1:
+			E_Statements0 := lhs
+			E_F_Statements_T1 := rhs[0]
+			PLUS2 := rhs[1]
+			E_F_Statements_T3 := rhs[2]
+
+			E_Statements0.Child = E_F_Statements_T1
+			E_F_Statements_T1.Next = PLUS2
+			PLUS2.Next = E_F_Statements_T3
+			E_Statements0.LastChild = E_F_Statements_T3
+
+			E_Statements0Value := gopapageno.ValueOf[int64](E_Statements0)
+			E_F_Statements_T1Value := gopapageno.ValueOf[int64](E_F_Statements_T1)
+			E_F_Statements_T3Value := gopapageno.ValueOf[int64](E_F_Statements_T3)
+			{
+			    E_Statements0Value = E_F_Statements_T1Value + E_F_Statements_T3Value
+			}
+			_ = E_F_Statements_T1Value
+			_ = E_F_Statements_T3Value
+			gopapageno.SetValue(E_Statements0, E_Statements0Value, rs.values0[thread])
+			_ = E_F_Statements_T1
+			_ = PLUS2
+			_ = E_F_Statements_T3
+		case 2:
+			E_Statements0 := lhs
+			E_F_Statements_T1 := rhs[0]
+			PLUS2 := rhs[1]
+			E_Statements_T3 := rhs[2]
+
+			E_Statements0.Child = E_F_Statements_T1
+			E_F_Statements_T1.Next = PLUS2
+			PLUS2.Next = E_Statements_T3
+			E_Statements0.LastChild = E_Statements_T3
+
+			E_Statements0Value := gopapageno.ValueOf[int64](E_Statements0)
+			E_F_Statements_T1Value := gopapageno.ValueOf[int64](E_F_Statements_T1)
+			E_Statements_T3Value := gopapageno.ValueOf[int64](E_Statements_T3)
+			{
+			    E_Statements0Value = E_F_Statements_T1Value + E_Statements_T3Value
+			}
+			_ = E_F_Statements_T1Value
+			_ = E_Statements_T3Value
+			gopapageno.SetValue(E_Statements0, E_Statements0Value, rs.values0[thread])
+			_ = E_F_Statements_T1
+			_ = PLUS2
+			_ = E_Statements_T3
+		case 3:
+			Statements0 := lhs
+			E_F_Statements_T1 := rhs[0]
+			SEMICOLON2 := rhs[1]
+			E_F_Statements_T3 := rhs[2]
+
+			Statements0.Child = E_F_Statements_T1
+			E_F_Statements_T1.Next = SEMICOLON2
+			SEMICOLON2.Next = E_F_Statements_T3
+			Statements0.LastChild = E_F_Statements_T3
+
+			Statements0Value := gopapageno.ValueOf[int64](Statements0)
+			E_F_Statements_T1Value := gopapageno.ValueOf[int64](E_F_Statements_T1)
+			E_F_Statements_T3Value := gopapageno.ValueOf[int64](E_F_Statements_T3)
+			{
+			    Statements0Value = E_F_Statements_T1Value + E_F_Statements_T3Value
+			}
+			_ = E_F_Statements_T1Value
+			_ = E_F_Statements_T3Value
+			gopapageno.SetValue(Statements0, Statements0Value, rs.values0[thread])
+			_ = E_F_Statements_T1
+			_ = SEMICOLON2
+			_ = E_F_Statements_T3
+		case 4:
+			Statements0 := lhs
+			E_F_Statements_T1 := rhs[0]
+			SEMICOLON2 := rhs[1]
+			E_Statements3 := rhs[2]
+
+			Statements0.Child = E_F_Statements_T1
+			E_F_Statements_T1.Next = SEMICOLON2
+			SEMICOLON2.Next = E_Statements3
+			Statements0.LastChild = E_Statements3
+
+			Statements0Value := gopapageno.ValueOf[int64](Statements0)
+			E_F_Statements_T1Value := gopapageno.ValueOf[int64](E_F_Statements_T1)
+			E_Statements3Value := gopapageno.ValueOf[int64](E_Statements3)
+			{
+			    Statements0Value = E_F_Statements_T1Value + E_Statements3Value
+			}
+			_ = E_F_Statements_T1Value
+			_ = E_Statements3Value
+			gopapageno.SetValue(Statements0, Statements0Value, rs.values0[thread])
+			_ = E_F_Statements_T1
+			_ = SEMICOLON2
+			_ = E_Statements3
+		case 5:
+			Statements0 := lhs
+			E_F_Statements_T1 := rhs[0]
+			SEMICOLON2 := rhs[1]
+			E_Statements_T3 := rhs[2]
+
+			Statements0.Child = E_F_Statements_T1
+			E_F_Statements_T1.Next = SEMICOLON2
+			SEMICOLON2.Next = E_Statements_T3
+			Statements0.LastChild = E_Statements_T3
+
+			Statements0Value := gopapageno.ValueOf[int64](Statements0)
+			E_F_Statements_T1Value := gopapageno.ValueOf[int64](E_F_Statements_T1)
+			E_Statements_T3Value := gopapageno.ValueOf[int64](E_Statements_T3)
+			{
+			    Statements0Value = E_F_Statements_T1Value + E_Statements_T3Value
+			}
+			_ = E_F_Statements_T1Value
+			_ = E_Statements_T3Value
+			gopapageno.SetValue(Statements0, Statements0Value, rs.values0[thread])
+			_ = E_F_Statements_T1
+			_ = SEMICOLON2
+			_ = E_Statements_T3
+		case 6:
+			E_Statements_T0 := lhs
+			E_F_Statements_T1 := rhs[0]
+			TIMES2 := rhs[1]
+			E_F_Statements_T3 := rhs[2]
+
+			E_Statements_T0.Child = E_F_Statements_T1
+			E_F_Statements_T1.Next = TIMES2
+			TIMES2.Next = E_F_Statements_T3
+			E_Statements_T0.LastChild = E_F_Statements_T3
+
+			E_Statements_T0Value := gopapageno.ValueOf[int64](E_Statements_T0)
+			E_F_Statements_T1Value := gopapageno.ValueOf[int64](E_F_Statements_T1)
+			E_F_Statements_T3Value := gopapageno.ValueOf[int64](E_F_Statements_T3)
+			{
+			    E_Statements_T0Value = E_F_Statements_T1Value * E_F_Statements_T3Value
+			}
+			_ = E_F_Statements_T1Value
+			_ = E_F_Statements_T3Value
+			gopapageno.SetValue(E_Statements_T0, E_Statements_T0Value, rs.values0[thread])
+			_ = E_F_Statements_T1
+			_ = TIMES2
+			_ = E_F_Statements_T3
+		case 7:
+			Program0 := lhs
+			E_Statements1 := rhs[0]
+
+			Program0.Child = E_Statements1
+			Program0.LastChild = E_Statements1
+
+			Program0Value := gopapageno.ValueOf[int64](Program0)
+			E_Statements1Value := gopapageno.ValueOf[int64](E_Statements1)
+			{
+			    Program0Value = E_Statements1Value
+			}
+			_ = E_Statements1Value
+			gopapageno.SetValue(Program0, Program0Value, rs.values0[thread])
+			_ = E_Statements1
+		case 8:
+			E_Statements0 := lhs
+			E_Statements1 := rhs[0]
+			PLUS2 := rhs[1]
+			E_F_Statements_T3 := rhs[2]
+
+			E_Statements0.Child = E_Statements1
+			E_Statements1.Next = PLUS2
+			PLUS2.Next = E_F_Statements_T3
+			E_Statements0.LastChild = E_F_Statements_T3
+
+			E_Statements0Value := gopapageno.ValueOf[int64](E_Statements0)
+			E_Statements1Value := gopapageno.ValueOf[int64](E_Statements1)
+			E_F_Statements_T3Value := gopapageno.ValueOf[int64](E_F_Statements_T3)
+			{
+			    E_Statements0Value = E_Statements1Value + E_F_Statements_T3Value
+			}
+			_ = E_Statements1Value
+			_ = E_F_Statements_T3Value
+			gopapageno.SetValue(E_Statements0, E_Statements0Value, rs.values0[thread])
+			_ = E_Statements1
+			_ = PLUS2
+			_ = E_F_Statements_T3
+		case 9:
+			E_Statements0 := lhs
+			E_Statements1 := rhs[0]
+			PLUS2 := rhs[1]
+			E_Statements_T3 := rhs[2]
+
+			E_Statements0.Child = E_Statements1
+			E_Statements1.Next = PLUS2
+			PLUS2.Next = E_Statements_T3
+			E_Statements0.LastChild = E_Statements_T3
+
+			E_Statements0Value := gopapageno.ValueOf[int64](E_Statements0)
+			E_Statements1Value := gopapageno.ValueOf[int64](E_Statements1)
+			E_Statements_T3Value := gopapageno.ValueOf[int64](E_Statements_T3)
+			{
+			    E_Statements0Value = E_Statements1Value + E_Statements_T3Value
+			}
+			_ = E_Statements1Value
+			_ = E_Statements_T3Value
+			gopapageno.SetValue(E_Statements0, E_Statements0Value, rs.values0[thread])
+			_ = E_Statements1
+			_ = PLUS2
+			_ = E_Statements_T3
+		case 10:
+			Statements0 := lhs
+			E_Statements1 := rhs[0]
+			SEMICOLON2 := rhs[1]
+			E_F_Statements_T3 := rhs[2]
+
+			Statements0.Child = E_Statements1
+			E_Statements1.Next = SEMICOLON2
+			SEMICOLON2.Next = E_F_Statements_T3
+			Statements0.LastChild = E_F_Statements_T3
+
+			Statements0Value := gopapageno.ValueOf[int64](Statements0)
+			E_Statements1Value := gopapageno.ValueOf[int64](E_Statements1)
+			E_F_Statements_T3Value := gopapageno.ValueOf[int64](E_F_Statements_T3)
+			{
+			    Statements0Value = E_Statements1Value + E_F_Statements_T3Value
+			}
+			_ = E_Statements1Value
+			_ = E_F_Statements_T3Value
+			gopapageno.SetValue(Statements0, Statements0Value, rs.values0[thread])
+			_ = E_Statements1
+			_ = SEMICOLON2
+			_ = E_F_Statements_T3
+		case 11:
+			Statements0 := lhs
+			E_Statements1 := rhs[0]
+			SEMICOLON2 := rhs[1]
+			E_Statements3 := rhs[2]
+
+			Statements0.Child = E_Statements1
+			E_Statements1.Next = SEMICOLON2
+			SEMICOLON2.Next = E_Statements3
+			Statements0.LastChild = E_Statements3
+
+			Statements0Value := gopapageno.ValueOf[int64](Statements0)
+			E_Statements1Value := gopapageno.ValueOf[int64](E_Statements1)
+			E_Statements3Value := gopapageno.ValueOf[int64](E_Statements3)
+			{
+			    Statements0Value = E_Statements1Value + E_Statements3Value
+			}
+			_ = E_Statements1Value
+			_ = E_Statements3Value
+			gopapageno.SetValue(Statements0, Statements0Value, rs.values0[thread])
+			_ = E_Statements1
+			_ = SEMICOLON2
+			_ = E_Statements3
+		case 12:
+			Statements0 := lhs
+			E_Statements1 := rhs[0]
+			SEMICOLON2 := rhs[1]
+			E_Statements_T3 := rhs[2]
+
+			Statements0.Child = E_Statements1
+			E_Statements1.Next = SEMICOLON2
+			SEMICOLON2.Next = E_Statements_T3
+			Statements0.LastChild = E_Statements_T3
+
+			Statements0Value := gopapageno.ValueOf[int64](Statements0)
+			E_Statements1Value := gopapageno.ValueOf[int64](E_Statements1)
+			E_Statements_T3Value := gopapageno.ValueOf[int64](E_Statements_T3)
+			{
+			    Statements0Value = E_Statements1Value + E_Statements_T3Value
+			}
+			_ = E_Statements1Value
+			_ = E_Statements_T3Value
+			gopapageno.SetValue(Statements0, Statements0Value, rs.values0[thread])
+			_ = E_Statements1
+			_ = SEMICOLON2
+			_ = E_Statements_T3
+		case 13:
+			Program0 := lhs
+			E_Statements_T1 := rhs[0]
+
+			Program0.Child = E_Statements_T1
+			Program0.LastChild = E_Statements_T1
+
+			Program0Value := gopapageno.ValueOf[int64](Program0)
+			E_Statements_T1Value := gopapageno.ValueOf[int64](E_Statements_T1)
+			{
+			    Program0Value = E_Statements_T1Value
+			}
+			_ = E_Statements_T1Value
+			gopapageno.SetValue(Program0, Program0Value, rs.values0[thread])
+			_ = E_Statements_T1
+		case 14:
+			E_Statements0 := lhs
+			E_Statements_T1 := rhs[0]
+			PLUS2 := rhs[1]
+			E_F_Statements_T3 := rhs[2]
+
+			E_Statements0.Child = E_Statements_T1
+			E_Statements_T1.Next = PLUS2
+			PLUS2.Next = E_F_Statements_T3
+			E_Statements0.LastChild = E_F_Statements_T3
+
+			E_Statements0Value := gopapageno.ValueOf[int64](E_Statements0)
+			E_Statements_T1Value := gopapageno.ValueOf[int64](E_Statements_T1)
+			E_F_Statements_T3Value := gopapageno.ValueOf[int64](E_F_Statements_T3)
+			{
+			    E_Statements0Value = E_Statements_T1Value + E_F_Statements_T3Value
+			}
+			_ = E_Statements_T1Value
+			_ = E_F_Statements_T3Value
+			gopapageno.SetValue(E_Statements0, E_Statements0Value, rs.values0[thread])
+			_ = E_Statements_T1
+			_ = PLUS2
+			_ = E_F_Statements_T3
+		case 15:
+			E_Statements0 := lhs
+			E_Statements_T1 := rhs[0]
+			PLUS2 := rhs[1]
+			E_Statements_T3 := rhs[2]
+
+			E_Statements0.Child = E_Statements_T1
+			E_Statements_T1.Next = PLUS2
+			PLUS2.Next = E_Statements_T3
+			E_Statements0.LastChild = E_Statements_T3
+
+			E_Statements0Value := gopapageno.ValueOf[int64](E_Statements0)
+			E_Statements_T1Value := gopapageno.ValueOf[int64](E_Statements_T1)
+			E_Statements_T3Value := gopapageno.ValueOf[int64](E_Statements_T3)
+			{
+			    E_Statements0Value = E_Statements_T1Value + E_Statements_T3Value
+			}
+			_ = E_Statements_T1Value
+			_ = E_Statements_T3Value
+			gopapageno.SetValue(E_Statements0, E_Statements0Value, rs.values0[thread])
+			_ = E_Statements_T1
+			_ = PLUS2
+			_ = E_Statements_T3
+		case 16:
+			Statements0 := lhs
+			E_Statements_T1 := rhs[0]
+			SEMICOLON2 := rhs[1]
+			E_F_Statements_T3 := rhs[2]
+
+			Statements0.Child = E_Statements_T1
+			E_Statements_T1.Next = SEMICOLON2
+			SEMICOLON2.Next = E_F_Statements_T3
+			Statements0.LastChild = E_F_Statements_T3
+
+			Statements0Value := gopapageno.ValueOf[int64](Statements0)
+			E_Statements_T1Value := gopapageno.ValueOf[int64](E_Statements_T1)
+			E_F_Statements_T3Value := gopapageno.ValueOf[int64](E_F_Statements_T3)
+			{
+			    Statements0Value = E_Statements_T1Value + E_F_Statements_T3Value
+			}
+			_ = E_Statements_T1Value
+			_ = E_F_Statements_T3Value
+			gopapageno.SetValue(Statements0, Statements0Value, rs.values0[thread])
+			_ = E_Statements_T1
+			_ = SEMICOLON2
+			_ = E_F_Statements_T3
+		case 17:
+			Statements0 := lhs
+			E_Statements_T1 := rhs[0]
+			SEMICOLON2 := rhs[1]
+			E_Statements3 := rhs[2]
+
+			Statements0.Child = E_Statements_T1
+			E_Statements_T1.Next = SEMICOLON2
+			SEMICOLON2.Next = E_Statements3
+			Statements0.LastChild = E_Statements3
+
+			Statements0Value := gopapageno.ValueOf[int64](Statements0)
+			E_Statements_T1Value := gopapageno.ValueOf[int64](E_Statements_T1)
+			E_Statements3Value := gopapageno.ValueOf[int64](E_Statements3)
+			{
+			    Statements0Value = E_Statements_T1Value + E_Statements3Value
+			}
+			_ = E_Statements_T1Value
+			_ = E_Statements3Value
+			gopapageno.SetValue(Statements0, Statements0Value, rs.values0[thread])
+			_ = E_Statements_T1
+			_ = SEMICOLON2
+			_ = E_Statements3
+		case 18:
+			Statements0 := lhs
+			E_Statements_T1 := rhs[0]
+			SEMICOLON2 := rhs[1]
+			E_Statements_T3 := rhs[2]
+
+			Statements0.Child = E_Statements_T1
+			E_Statements_T1.Next = SEMICOLON2
+			SEMICOLON2.Next = E_Statements_T3
+			Statements0.LastChild = E_Statements_T3
+
+			Statements0Value := gopapageno.ValueOf[int64](Statements0)
+			E_Statements_T1Value := gopapageno.ValueOf[int64](E_Statements_T1)
+			E_Statements_T3Value := gopapageno.ValueOf[int64](E_Statements_T3)
+			{
+			    Statements0Value = E_Statements_T1Value + E_Statements_T3Value
+			}
+			_ = E_Statements_T1Value
+			_ = E_Statements_T3Value
+			gopapageno.SetValue(Statements0, Statements0Value, rs.values0[thread])
+			_ = E_Statements_T1
+			_ = SEMICOLON2
+			_ = E_Statements_T3
+		case 19:
+			E_Statements_T0 := lhs
+			E_Statements_T1 := rhs[0]
+			TIMES2 := rhs[1]
+			E_F_Statements_T3 := rhs[2]
+
+			E_Statements_T0.Child = E_Statements_T1
+			E_Statements_T1.Next = TIMES2
+			TIMES2.Next = E_F_Statements_T3
+			E_Statements_T0.LastChild = E_F_Statements_T3
+
+			E_Statements_T0Value := gopapageno.ValueOf[int64](E_Statements_T0)
+			E_Statements_T1Value := gopapageno.ValueOf[int64](E_Statements_T1)
+			E_F_Statements_T3Value := gopapageno.ValueOf[int64](E_F_Statements_T3)
+			{
+			    E_Statements_T0Value = E_Statements_T1Value * E_F_Statements_T3Value
+			}
+			_ = E_Statements_T1Value
+			_ = E_F_Statements_T3Value
+			gopapageno.SetValue(E_Statements_T0, E_Statements_T0Value, rs.values0[thread])
+			_ = E_Statements_T1
+			_ = TIMES2
+			_ = E_F_Statements_T3
+		case 20:
+			Program0 := lhs
+			Statements1 := rhs[0]
+
+			Program0.Child = Statements1
+			Program0.LastChild = Statements1
+
+			Program0Value := gopapageno.ValueOf[int64](Program0)
+			Statements1Value := gopapageno.ValueOf[int64](Statements1)
+			{
+			    Program0Value = Statements1Value
+			}
+			_ = Statements1Value
+			gopapageno.SetValue(Program0, Program0Value, rs.values0[thread])
+			_ = Statements1
+		case 21:
+			Statements0 := lhs
+			Statements1 := rhs[0]
+			SEMICOLON2 := rhs[1]
+			E_F_Statements_T3 := rhs[2]
+
+			Statements0.Child = Statements1
+			Statements1.Next = SEMICOLON2
+			SEMICOLON2.Next = E_F_Statements_T3
+			Statements0.LastChild = E_F_Statements_T3
+
+			Statements0Value := gopapageno.ValueOf[int64](Statements0)
+			Statements1Value := gopapageno.ValueOf[int64](Statements1)
+			E_F_Statements_T3Value := gopapageno.ValueOf[int64](E_F_Statements_T3)
+			{
+			    Statements0Value = Statements1Value + E_F_Statements_T3Value
+			}
+			_ = Statements1Value
+			_ = E_F_Statements_T3Value
+			gopapageno.SetValue(Statements0, Statements0Value, rs.values0[thread])
+			_ = Statements1
+			_ = SEMICOLON2
+			_ = E_F_Statements_T3
+		case 22:
+			Statements0 := lhs
+			Statements1 := rhs[0]
+			SEMICOLON2 := rhs[1]
+			E_Statements3 := rhs[2]
+
+			Statements0.Child = Statements1
+			Statements1.Next = SEMICOLON2
+			SEMICOLON2.Next = E_Statements3
+			Statements0.LastChild = E_Statements3
+
+			Statements0Value := gopapageno.ValueOf[int64](Statements0)
+			Statements1Value := gopapageno.ValueOf[int64](Statements1)
+			E_Statements3Value := gopapageno.ValueOf[int64](E_Statements3)
+			{
+			    Statements0Value = Statements1Value + E_Statements3Value
+			}
+			_ = Statements1Value
+			_ = E_Statements3Value
+			gopapageno.SetValue(Statements0, Statements0Value, rs.values0[thread])
+			_ = Statements1
+			_ = SEMICOLON2
+			_ = E_Statements3
+		case 23:
+			Statements0 := lhs
+			Statements1 := rhs[0]
+			SEMICOLON2 := rhs[1]
+			E_Statements_T3 := rhs[2]
+
+			Statements0.Child = Statements1
+			Statements1.Next = SEMICOLON2
+			SEMICOLON2.Next = E_Statements_T3
+			Statements0.LastChild = E_Statements_T3
+
+			Statements0Value := gopapageno.ValueOf[int64](Statements0)
+			Statements1Value := gopapageno.ValueOf[int64](Statements1)
+			E_Statements_T3Value := gopapageno.ValueOf[int64](E_Statements_T3)
+			{
+			    Statements0Value = Statements1Value + E_Statements_T3Value
+			}
+			_ = Statements1Value
+			_ = E_Statements_T3Value
+			gopapageno.SetValue(Statements0, Statements0Value, rs.values0[thread])
+			_ = Statements1
+			_ = SEMICOLON2
+			_ = E_Statements_T3
+		case 24:
+			E_F_Statements_T0 := lhs
+			LPAR1 := rhs[0]
+			E_F_Statements_T2 := rhs[1]
+			RPAR3 := rhs[2]
+
+			E_F_Statements_T0.Child = LPAR1
+			LPAR1.Next = E_F_Statements_T2
+			E_F_Statements_T2.Next = RPAR3
+			E_F_Statements_T0.LastChild = RPAR3
+
+			E_F_Statements_T0Value := gopapageno.ValueOf[int64](E_F_Statements_T0)
+			E_F_Statements_T2Value := gopapageno.ValueOf[int64](E_F_Statements_T2)
+			{
+			    E_F_Statements_T0Value = E_F_Statements_T2Value
+			}
+			_ = E_F_Statements_T2Value
+			gopapageno.SetValue(E_F_Statements_T0, E_F_Statements_T0Value, rs.values0[thread])
+			_ = LPAR1
+			_ = E_F_Statements_T2
+			_ = RPAR3
+		case 25:
+			E_F_Statements_T0 := lhs
+			LPAR1 := rhs[0]
+			E_Statements2 := rhs[1]
+			RPAR3 := rhs[2]
+
+			E_F_Statements_T0.Child = LPAR1
+			LPAR1.Next = E_Statements2
+			E_Statements2.Next = RPAR3
+			E_F_Statements_T0.LastChild = RPAR3
+
+			E_F_Statements_T0Value := gopapageno.ValueOf[int64](E_F_Statements_T0)
+			E_Statements2Value := gopapageno.ValueOf[int64](E_Statements2)
+			{
+			    E_F_Statements_T0Value = E_Statements2Value
+			}
+			_ = E_Statements2Value
+			gopapageno.SetValue(E_F_Statements_T0, E_F_Statements_T0Value, rs.values0[thread])
+			_ = LPAR1
+			_ = E_Statements2
+			_ = RPAR3
+		case 26:
+			E_F_Statements_T0 := lhs
+			LPAR1 := rhs[0]
+			E_Statements_T2 := rhs[1]
+			RPAR3 := rhs[2]
+
+			E_F_Statements_T0.Child = LPAR1
+			LPAR1.Next = E_Statements_T2
+			E_Statements_T2.Next = RPAR3
+			E_F_Statements_T0.LastChild = RPAR3
+
+			E_F_Statements_T0Value := gopapageno.ValueOf[int64](E_F_Statements_T0)
+			E_Statements_T2Value := gopapageno.ValueOf[int64](E_Statements_T2)
+			{
+			    E_F_Statements_T0Value = E_Statements_T2Value
+			}
+			_ = E_Statements_T2Value
+			gopapageno.SetValue(E_F_Statements_T0, E_F_Statements_T0Value, rs.values0[thread])
+			_ = LPAR1
+			_ = E_Statements_T2
+			_ = RPAR3
+		case 27:
+			E_F_Statements_T0 := lhs
+			NUMBER1 := rhs[0]
+
+			E_F_Statements_T0.Child = NUMBER1
+			E_F_Statements_T0.LastChild = NUMBER1
+
+			E_F_Statements_T0Value := gopapageno.ValueOf[int64](E_F_Statements_T0)
+			NUMBER1Value := gopapageno.ValueOf[int64](NUMBER1)
+			{
+			    E_F_Statements_T0Value = NUMBER1Value
+			}
+			_ = NUMBER1Value
+			gopapageno.SetValue(E_F_Statements_T0, E_F_Statements_T0Value, rs.values0[thread])
+			_ = NUMBER1
+		}
+		_ = ruleFlags
+	}
+
+	return &gopapageno.Grammar{
+		NumTerminals:  numTerminals,
+		NumNonterminals: numNonTerminals,
+		MaxRHSLength: maxRHSLen,
+		Rules: rules,
+		CompressedRules: compressedRules,
+		PrecedenceMatrix: precMatrix,
+		BitPackedPrecedenceMatrix: bitPackedMatrix,
+		TokenNames: tokenNames,
+		SyncTerminals: syncTerminals,
+		Func: fn,
+		ParsingStrategy: gopapageno.AOPP,
+		StateFunc: func(sourceLen, concurrency, avgTokenLength int) any {
+			return newParserRunState(sourceLen, concurrency, avgTokenLength)
+		},
+	}
+}
+
@@ -0,0 +1,50 @@
+%axiom Program
+%sync SEMICOLON
+
+%type Program int64
+%type Statements int64
+%type E int64
+%type T int64
+%type F int64
+%type NUMBER int64
+
+%%
+
+Program : Statements
+{
+    $$.Value = $1.Value
+};
+
+Statements : Statements SEMICOLON E
+{
+    $$.Value = $1.Value + $3.Value
+} | E
+{
+    $$.Value = $1.Value
+};
+
+E : E PLUS T
+{
+    $$.Value = $1.Value + $3.Value
+} | T
+{
+    $$.Value = $1.Value
+};
+
+T : T TIMES F
+{
+    $$.Value = $1.Value * $3.Value
+} | F
+{
+    $$.Value = $1.Value
+};
+
+F : NUMBER
+{
+    $$.Value = $1.Value
+} | LPAR E RPAR
+{
+    $$.Value = $2.Value
+};
+
+%%
@@ -0,0 +1,7 @@
+// Package calc holds a grammar of arithmetic expressions separated by semicolons, generated for every parsing strategy,
+// so that the runtime can be tested end to end.
+package calc
+
+//go:generate go run ../../cmd/gopapageno -types-only -l calc.l -g calc.g -o opp -s opp
+//go:generate go run ../../cmd/gopapageno -types-only -l calc.l -g calc.g -o aopp -s aopp
+//go:generate go run ../../cmd/gopapageno -types-only -l calc.l -g calc.g -o copp -s copp
@@ -0,0 +1,452 @@
+package calc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/giornetta/gopapageno"
+	"github.com/giornetta/gopapageno/internal/calc/aopp"
+	"github.com/giornetta/gopapageno/internal/calc/copp"
+	"github.com/giornetta/gopapageno/internal/calc/opp"
+)
+
+var strategies = []struct {
+	name    string
+	lexer   func() *gopapageno.Lexer
+	grammar func() *gopapageno.Grammar
+}{
+	{"opp", opp.NewLexer, opp.NewGrammar},
+	{"aopp", aopp.NewLexer, aopp.NewGrammar},
+	{"copp", copp.NewLexer, copp.NewGrammar},
+}
+
+// program returns a source of n random statements, along with the sum of their values.
+func program(n int, seed int64) (string, int64) {
+	rnd := rand.New(rand.NewSource(seed))
+
+	var sb strings.Builder
+	var sum int64
+
+	for i := range n {
+		if i > 0 {
+			sb.WriteString(";\n")
+		}
+
+		sum += expr(&sb, rnd, 3)
+	}
+
+	return sb.String(), sum
+}
+
+// expr writes a random expression nested at most depth times to sb, and returns its value.
+func expr(sb *strings.Builder, rnd *rand.Rand, depth int) int64 {
+	terms := 1 + rnd.Intn(3)
+
+	var value int64
+	for i := range terms {
+		if i > 0 {
+			sb.WriteString(" + ")
+		}
+
+		factors := 1 + rnd.Intn(2)
+
+		term := int64(1)
+		for j := range factors {
+			if j > 0 {
+				sb.WriteString("*")
+			}
+
+			if depth > 0 && rnd.Intn(4) == 0 {
+				sb.WriteString("(")
+				term *= expr(sb, rnd, depth-1)
+				sb.WriteString(")")
+			} else {
+				n := rnd.Int63n(100)
+				fmt.Fprintf(sb, "%d", n)
+				term *= n
+			}
+		}
+
+		value += term
+	}
+
+	return value
+}
+
+// spans lists the type and span of every token of the tree rooted in root, in depth-first order.
+func spans(root *gopapageno.Token) []string {
+	var out []string
+
+	var visit func(t *gopapageno.Token, shift int)
+	visit = func(t *gopapageno.Token, shift int) {
+		for ; t != nil; t = t.Next {
+			out = append(out, fmt.Sprintf("%d[%d:%d]", t.Type, t.Start+shift, t.End+shift))
+			visit(t.Child, shift+t.Shift)
+		}
+	}
+	visit(root, 0)
+
+	return out
+}
+
+func TestRunConcurrently(t *testing.T) {
+	const runs = 8
+
+	for _, strat := range strategies {
+		for _, reuse := range []bool{false, true} {
+			r := gopapageno.NewRunner(strat.lexer(), strat.grammar(), gopapageno.WithConcurrency(2), gopapageno.WithPoolReuse(reuse))
+
+			var wg sync.WaitGroup
+			for i := range runs {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					src, expected := program(100+i, int64(i))
+
+					root, err := r.Run(context.Background(), []byte(src))
+					if err != nil {
+						t.Errorf("%s, pool reuse %v, run %d: unexpected error: %v", strat.name, reuse, i, err)
+						return
+					}
+
+					// With pool reuse the tree is only valid until the next run, so only runs with their own pools are checked.
+					if reuse {
+						return
+					}
+
+					if v := gopapageno.ValueOf[int64](root); v != expected {
+						t.Errorf("%s, run %d: expected %d, got %d", strat.name, i, expected, v)
+					}
+				}()
+			}
+
+			wg.Wait()
+		}
+	}
+}
+
+func TestRunReader(t *testing.T) {
+	src, expected := program(300, 1)
+
+	readers := map[string]func(string) io.Reader{
+		"sized": func(s string) io.Reader {
+			return bytes.NewReader([]byte(s))
+		},
+		"unsized": func(s string) io.Reader {
+			return iotest.HalfReader(strings.NewReader(s))
+		},
+	}
+
+	for _, strat := range strategies {
+		for _, concurrency := range []int{1, 3} {
+			r := gopapageno.NewRunner(strat.lexer(), strat.grammar(), gopapageno.WithConcurrency(concurrency))
+
+			want, err := r.Run(context.Background(), []byte(src))
+			if err != nil {
+				t.Fatalf("%s: could not run with concurrency %d: %v", strat.name, concurrency, err)
+			}
+
+			if v := gopapageno.ValueOf[int64](want); v != expected {
+				t.Fatalf("%s: expected Run with concurrency %d to yield %d, got %d", strat.name, concurrency, expected, v)
+			}
+
+			for _, windowSize := range []int{16, 100, 4096, 1 << 20} {
+				for name, reader := range readers {
+					r.Options.WindowSize = windowSize
+
+					got, err := r.RunReader(context.Background(), reader(src))
+					if err != nil {
+						t.Fatalf("%s, concurrency %d, window %d, %s reader: unexpected error: %v", strat.name, concurrency, windowSize, name, err)
+					}
+
+					if v := gopapageno.ValueOf[int64](got); v != expected {
+						t.Errorf("%s, concurrency %d, window %d, %s reader: expected %d, got %d", strat.name, concurrency, windowSize, name, expected, v)
+					}
+
+					if w, g := spans(want), spans(got); !slices.Equal(w, g) {
+						t.Errorf("%s, concurrency %d, window %d, %s reader: trees differ:\nRun:        %v\nRunReader:  %v", strat.name, concurrency, windowSize, name, w, g)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRunReaderEmpty(t *testing.T) {
+	for _, strat := range strategies {
+		r := gopapageno.NewRunner(strat.lexer(), strat.grammar(), gopapageno.WithConcurrency(2))
+
+		_, wantErr := r.Run(context.Background(), nil)
+		_, err := r.RunReader(context.Background(), strings.NewReader(" \n "))
+
+		if (err == nil) != (wantErr == nil) {
+			t.Errorf("%s: expected RunReader to fail like Run on an empty source, got %v and %v", strat.name, err, wantErr)
+		}
+	}
+}
+
+// errorOffsets returns the offsets of the syntax errors contained in err.
+func errorOffsets(t *testing.T, err error) []int {
+	t.Helper()
+
+	var list gopapageno.ErrorList
+	if !errors.As(err, &list) {
+		t.Fatalf("expected an ErrorList, got %v", err)
+	}
+
+	offsets := make([]int, len(list))
+	for i, e := range list {
+		offsets[i] = e.Offset
+	}
+
+	return offsets
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		src     string
+		offsets []int
+	}{
+		{"1 + 2; 3 * + 4; 5", []int{11}},
+		{"1 * (2 + 3); 4 5 * 6; 7 + + + 8; 9", []int{17, 26}},
+		{"1 + 2; 3 * * 4; 5; 6 + ; 7 8 9; 10", []int{11, 23, 30}},
+	}
+
+	for _, strat := range strategies {
+		for _, concurrency := range []int{1, 3} {
+			r := gopapageno.NewRunner(strat.lexer(), strat.grammar(),
+				gopapageno.WithConcurrency(concurrency),
+				gopapageno.WithErrorRecovery(0),
+				gopapageno.WithDiagnostics(func(error) {}))
+
+			for _, tt := range tests {
+				root, err := r.Run(context.Background(), []byte(tt.src))
+				if root == nil {
+					t.Errorf("%s, concurrency %d, %q: expected a partial parse tree", strat.name, concurrency, tt.src)
+				}
+
+				if offsets := errorOffsets(t, err); !slices.Equal(offsets, tt.offsets) {
+					t.Errorf("%s, concurrency %d, %q: expected errors at %v, got %v", strat.name, concurrency, tt.src, tt.offsets, offsets)
+				}
+			}
+		}
+	}
+}
+
+func TestErrorRecoveryLimit(t *testing.T) {
+	src, _ := program(50, 2)
+	src = strings.Repeat(src+"; 1 2;\n", 8)
+
+	for _, strat := range strategies {
+		for _, concurrency := range []int{1, 3} {
+			var reported int
+
+			r := gopapageno.NewRunner(strat.lexer(), strat.grammar(),
+				gopapageno.WithConcurrency(concurrency),
+				gopapageno.WithErrorRecovery(5),
+				gopapageno.WithDiagnostics(func(error) { reported++ }))
+
+			root, err := r.Run(context.Background(), []byte(src))
+			if root == nil {
+				t.Errorf("%s, concurrency %d: expected a partial parse tree", strat.name, concurrency)
+			}
+
+			if offsets := errorOffsets(t, err); len(offsets) != 5 || reported != 5 {
+				t.Errorf("%s, concurrency %d: expected 5 errors to be returned and reported, got %d and %d", strat.name, concurrency, len(offsets), reported)
+			}
+		}
+	}
+}
+
+// randomEdit returns a random edit of src, which replaces a number, inserts a statement or removes one.
+func randomEdit(src string, rnd *rand.Rand) gopapageno.Edit {
+	from := rnd.Intn(len(src))
+
+	switch rnd.Intn(4) {
+	case 0:
+		if i := strings.IndexByte(src[from:], ';'); i >= 0 {
+			var sb strings.Builder
+			sb.WriteString(" ")
+			expr(&sb, rnd, 1)
+			sb.WriteString(";")
+
+			return gopapageno.Edit{Offset: from + i + 1, Inserted: []byte(sb.String())}
+		}
+	case 1:
+		if i := strings.IndexByte(src[from:], ';'); i >= 0 {
+			start := from + i
+			if j := strings.IndexByte(src[start+1:], ';'); j >= 0 {
+				return gopapageno.Edit{Offset: start, Removed: j + 1}
+			}
+		}
+	}
+
+	start := strings.IndexAny(src[from:], "0123456789")
+	if start < 0 {
+		return randomEdit(src, rnd)
+	}
+	start += from
+
+	for start > 0 && src[start-1] >= '0' && src[start-1] <= '9' {
+		start--
+	}
+
+	end := start
+	for end < len(src) && src[end] >= '0' && src[end] <= '9' {
+		end++
+	}
+
+	var sb strings.Builder
+	if rnd.Intn(2) == 0 {
+		fmt.Fprintf(&sb, "%d", rnd.Intn(1000))
+	} else {
+		sb.WriteString("(")
+		expr(&sb, rnd, 1)
+		sb.WriteString(")")
+	}
+
+	return gopapageno.Edit{Offset: start, Removed: end - start, Inserted: []byte(sb.String())}
+}
+
+// applyEdit returns src after edit.
+func applyEdit(src string, edit gopapageno.Edit) string {
+	return src[:edit.Offset] + string(edit.Inserted) + src[edit.Offset+edit.Removed:]
+}
+
+func TestReparse(t *testing.T) {
+	src, _ := program(40, 3)
+	rnd := rand.New(rand.NewSource(4))
+
+	r := gopapageno.NewRunner(opp.NewLexer(), opp.NewGrammar())
+
+	p, err := gopapageno.NewIncrementalParser(r)
+	if err != nil {
+		t.Fatalf("could not create incremental parser: %v", err)
+	}
+
+	root, err := p.Parse(context.Background(), []byte(src))
+	if err != nil {
+		t.Fatalf("could not parse: %v", err)
+	}
+
+	for range 200 {
+		edit := randomEdit(src, rnd)
+		edited := applyEdit(src, edit)
+
+		before, value := spans(root), gopapageno.ValueOf[int64](root)
+
+		got, err := p.Reparse(context.Background(), root, []byte(edited), edit)
+		if err != nil {
+			t.Fatalf("could not reparse %q after %+v: %v", edited, edit, err)
+		}
+
+		want, err := r.Run(context.Background(), []byte(edited))
+		if err != nil {
+			t.Fatalf("could not parse %q: %v", edited, err)
+		}
+
+		if w, g := gopapageno.ValueOf[int64](want), gopapageno.ValueOf[int64](got); w != g {
+			t.Fatalf("%q after %+v: expected %d, got %d", edited, edit, w, g)
+		}
+
+		if w, g := spans(want), spans(got); !slices.Equal(w, g) {
+			t.Fatalf("%q after %+v: trees differ:\nParse:    %v\nReparse:  %v", edited, edit, w, g)
+		}
+
+		if !slices.Equal(spans(root), before) || gopapageno.ValueOf[int64](root) != value {
+			t.Fatalf("%q after %+v: expected the previous tree to be left untouched", edited, edit)
+		}
+
+		src, root = edited, got
+	}
+}
+
+func TestReparseError(t *testing.T) {
+	src := "1 + 2;\n3 * 4;\n5"
+
+	r := gopapageno.NewRunner(opp.NewLexer(), opp.NewGrammar())
+
+	p, err := gopapageno.NewIncrementalParser(r)
+	if err != nil {
+		t.Fatalf("could not create incremental parser: %v", err)
+	}
+
+	root, err := p.Parse(context.Background(), []byte(src))
+	if err != nil {
+		t.Fatalf("could not parse: %v", err)
+	}
+
+	before := spans(root)
+
+	edit := gopapageno.Edit{Offset: 9, Removed: 1, Inserted: []byte("* +")}
+	if _, err := p.Reparse(context.Background(), root, []byte(applyEdit(src, edit)), edit); err == nil {
+		t.Fatalf("expected reparsing an invalid source to fail")
+	}
+
+	if !slices.Equal(spans(root), before) || gopapageno.ValueOf[int64](root) != 20 {
+		t.Errorf("expected the previous tree to be left untouched")
+	}
+}
+
+func TestReparseSharesSubtrees(t *testing.T) {
+	src, _ := program(100, 5)
+
+	p, err := gopapageno.NewIncrementalParser(gopapageno.NewRunner(opp.NewLexer(), opp.NewGrammar()))
+	if err != nil {
+		t.Fatalf("could not create incremental parser: %v", err)
+	}
+
+	root, err := p.Parse(context.Background(), []byte(src))
+	if err != nil {
+		t.Fatalf("could not parse: %v", err)
+	}
+
+	// The first number is replaced by a longer expression, shifting every token following it.
+	start := strings.IndexAny(src, "0123456789")
+	end := start + strings.IndexFunc(src[start:], func(r rune) bool { return r < '0' || r > '9' })
+	edit := gopapageno.Edit{Offset: start, Removed: end - start, Inserted: []byte("(1 + 2)")}
+
+	got, err := p.Reparse(context.Background(), root, []byte(applyEdit(src, edit)), edit)
+	if err != nil {
+		t.Fatalf("could not reparse: %v", err)
+	}
+
+	tokens := func(root *gopapageno.Token) map[*gopapageno.Token]bool {
+		set := make(map[*gopapageno.Token]bool)
+
+		var visit func(t *gopapageno.Token)
+		visit = func(t *gopapageno.Token) {
+			for ; t != nil; t = t.Next {
+				set[t] = true
+				visit(t.Child)
+			}
+		}
+		visit(root)
+
+		return set
+	}
+
+	before, after := tokens(root), tokens(got)
+
+	shared := 0
+	for token := range after {
+		if before[token] {
+			shared++
+		}
+	}
+
+	if shared < len(after)*3/4 {
+		t.Errorf("expected most tokens to be shared with the previous tree, got %d of %d", shared, len(after))
+	}
+}
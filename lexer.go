@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"sync"
 	"unsafe"
 )
 
@@ -11,132 +14,466 @@ var (
 	ErrInvalid = errors.New("invalid character")
 )
 
+// A PreambleFunc prepares package-level state, such as memory pools, before a run.
+// Since that state is shared by every run, runs of lexers and grammars providing one are serialized.
+// Use a StateFunc to allow concurrent runs.
 type PreambleFunc func(sourceLen, concurrency int)
 
-type LexerFunc func(rule int, text string, start int, end int, thread int, token *Token) LexResult
+// A StateFunc allocates the state used by semantic actions during a single run, such as preallocated memory for semantic values.
+// It is called with the length of the source, the number of threads that will run actions
+// and the average length of the tokens the run expects, to size that memory.
+// The value it returns is passed to every action of that run.
+type StateFunc func(sourceLen, concurrency, avgTokenLength int) any
+
+// call returns the state built by f, or nil if f is nil.
+func (f StateFunc) call(sourceLen, concurrency, avgTokenLength int) any {
+	if f == nil {
+		return nil
+	}
+
+	return f(sourceLen, concurrency, avgTokenLength)
+}
+
+// A LexContext describes a lexeme to the action reading it.
+// Each thread reuses the same LexContext for every lexeme, so actions must not retain it.
+type LexContext struct {
+	// Rule is the number of the rule matching Text, whose first and last bytes are at positions Start and End of the source.
+	Rule  int
+	Text  string
+	Start int
+	End   int
+
+	// Thread is the thread running the action.
+	Thread int
+
+	// Token is the token the action produces.
+	// Prev is the token produced before it, or nil if there is none: actions may depend on its type and position
+	// to tell apart context-sensitive tokens, but must not modify it.
+	Token *Token
+	Prev  *Token
+
+	// State is the state of the run, and ThreadState the one of the thread running the action.
+	State       any
+	ThreadState any
+
+	// Mode is the start condition used to match the following tokens, which the action can switch by setting it.
+	Mode *LexerMode
+}
+
+// A LexerFunc runs the action of the rule matching a lexeme.
+type LexerFunc func(ctx *LexContext) LexResult
+
+// A TokenSource reads by hand the tokens that regular expressions can't describe, such as nested comments, heredocs
+// or length-prefixed fields, alongside the automata of a Lexer.
+// It is asked for a token wherever one may begin, before the automata: lexemes it leaves to them are matched as usual.
+// Since it runs in every thread lexing a portion of the source, it must be safe for concurrent use.
+type TokenSource interface {
+	// Token reads the token at the beginning of src, which holds the rest of the data available to the thread.
+	// ctx is the context of a LexerFunc, where Start is the position of src in the source, and Rule, Text and End are unset.
+	// It returns the length of the lexeme, along with LexOK once it has set the type and value of the token,
+	// LexSkip if it must be discarded, or LexErr. A length of 0 with LexOK leaves the token to the automata.
+	// LexEOF reports that the lexeme may continue past src: it is read again once more data is available,
+	// and is an error at the end of the source.
+	// Just like actions, it can switch the start condition used to match the following tokens by setting the mode.
+	Token(src []byte, ctx *LexContext) (int, LexResult)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource.
+type TokenSourceFunc func(src []byte, ctx *LexContext) (int, LexResult)
+
+// Token calls f.
+func (f TokenSourceFunc) Token(src []byte, ctx *LexContext) (int, LexResult) {
+	return f(src, ctx)
+}
+
+// A LexerMode identifies a start condition of a Lexer, which determines the rules that can match.
+type LexerMode int
+
+// ModeInitial is the start condition in which lexing begins.
+const ModeInitial LexerMode = 0
 
 type Lexer struct {
-	Automaton          LexerDFA
+	// Automaton matches the rules active in the initial start condition.
+	Automaton LexerDFA
+
+	// CutPointsAutomaton matches the positions at which sources may be split to be lexed in parallel.
+	// Since a cut point may fall within a token or a start condition other than the initial one,
+	// every portion but the first one is lexed speculatively, and lexed again from where the previous one actually ends
+	// until both agree on the beginning of a token.
 	CutPointsAutomaton LexerDFA
-	Func               LexerFunc
+
+	Func LexerFunc
+
+	// Source, if set, reads tokens by hand before the automata try to match them.
+	Source TokenSource
+
+	// ModeAutomata holds the automata matching the rules active in the other start conditions:
+	// the automaton of mode m is ModeAutomata[m-1]. It is nil if the lexer declares no other start condition.
+	ModeAutomata []LexerDFA
+
+	// Anchors holds the anchors of each rule, indexed by rule number.
+	// It is nil if no rule is anchored.
+	Anchors []LexerAnchors
 
 	PreambleFunc PreambleFunc
+	StateFunc    StateFunc
+
+	// ThreadStateFunc, if set, allocates the state of each thread lexing a source, passed to actions as ThreadState.
+	// It is called once for every thread of a run, so that the state needs no indexing by thread nor synchronization;
+	// when the source is read in windows, each thread keeps its state from one window to the next.
+	ThreadStateFunc StateFunc
 }
 
 type LexerDFAState struct {
-	Transitions     [256]int
 	IsFinal         bool
 	AssociatedRules []int
 }
 
-type LexerDFA []LexerDFAState
+// A LexerDFA is a deterministic automaton whose transition table is compressed by grouping bytes into equivalence classes:
+// bytes belonging to the same class lead every state to the same state. Its initial state is the first one.
+type LexerDFA struct {
+	// Classes maps each byte to its equivalence class.
+	Classes    [256]uint8
+	NumClasses int
+
+	// Transitions holds the state reached by each state through each class, at index state*NumClasses+class.
+	// Missing transitions are -1.
+	Transitions []int32
+
+	States []LexerDFAState
+}
+
+// NewLexerDFA builds a LexerDFA from the states and the uncompressed transitions of an automaton,
+// where transitions[i][c] is the state reached by state i through byte c, or -1.
+func NewLexerDFA(states []LexerDFAState, transitions [][256]int) LexerDFA {
+	dfa := LexerDFA{
+		States: states,
+	}
+
+	signatures := make(map[string]uint8)
+	var columns [][]int32
+
+	for c := 0; c < 256; c++ {
+		column := make([]int32, len(states))
+		for i := range states {
+			column[i] = int32(transitions[i][c])
+		}
+
+		signature := fmt.Sprint(column)
+		class, ok := signatures[signature]
+		if !ok {
+			class = uint8(len(columns))
+			signatures[signature] = class
+			columns = append(columns, column)
+		}
+
+		dfa.Classes[c] = class
+	}
+
+	dfa.NumClasses = len(columns)
+	dfa.Transitions = make([]int32, len(states)*dfa.NumClasses)
+	for class, column := range columns {
+		for i, next := range column {
+			dfa.Transitions[i*dfa.NumClasses+class] = next
+		}
+	}
+
+	return dfa
+}
+
+// next returns the state reached by state through byte c, or -1 if there is none.
+func (dfa *LexerDFA) next(state int, c byte) int {
+	return int(dfa.Transitions[state*dfa.NumClasses+int(dfa.Classes[c])])
+}
+
+// LexerAnchors constrain the positions at which the matches of a lexer rule may begin and end.
+type LexerAnchors uint8
+
+const (
+	// AnchorLineStart requires matches to begin at the start of a line.
+	AnchorLineStart LexerAnchors = 1 << iota
+	// AnchorLineEnd requires matches to end at the end of a line, before a line break or the end of the source.
+	AnchorLineEnd
+)
+
+// isLineBreak reports whether c begins a line break.
+func isLineBreak(c byte) bool {
+	return c == '\n' || c == '\r'
+}
+
+// chunksPerThread is the number of portions the source is split into for each thread lexing it in parallel.
+const chunksPerThread = 4
 
 // Scanner implements reading and tokenization.
 type Scanner struct {
 	Lexer *Lexer
 
-	source      []byte
+	source []byte
+	offset int
+
+	// cutPoints holds the positions at which the portions of the source begin, followed by its length.
+	// The portions are lexed by concurrency threads.
 	cutPoints   []int
 	concurrency int
 
+	// atCutPoint tells whether each portion begins at a cut point of the lexer description, rather than wherever
+	// the source had to be cut. The lists of tokens returned to parsers are only split at cut points,
+	// since parsers rely on them to begin their lists where the grammar allows.
+	atCutPoint []bool
+
+	// state is passed to lexer actions, along with the threadStates of the thread running them.
+	state        any
+	threadStates []any
+
+	// lineStart and lineEnd report whether the source begins at the start of a line and ends at the end of one.
+	// They only differ from true when the source is a portion of a larger one.
+	lineStart bool
+	lineEnd   bool
+
+	// mode is the start condition in which the source begins. Once it has been lexed, it is the one in which it ends.
+	mode LexerMode
+
+	// prev is the token preceding the source, if any. Once it has been lexed, it is the last token of the source.
+	prev *Token
+
+	// When more is set, the source is followed by more data that isn't available yet, so tokens reaching its end
+	// are left unlexed: consumed is the length of the part of the source that was lexed.
+	more     bool
+	consumed int
+
+	// When indexLines is set, lines holds the offsets at which the lines of the source begin, once it has been lexed.
+	indexLines bool
+	lines      []int
+
 	pools []*Pool[stack[Token]]
 }
 
 func (l *Lexer) Scanner(src []byte, opts *RunOptions) *Scanner {
+	s := &Scanner{
+		Lexer: l,
+	}
+
+	s.reset(src, opts)
+
+	return s
+}
+
+// reset prepares the Scanner to tokenize src.
+// Pools allocated for previous sources are rewound and reused, so tokens produced by them must not be used anymore.
+func (s *Scanner) reset(src []byte, opts *RunOptions) {
 	if opts.Concurrency < 1 {
 		opts.Concurrency = 1
 	}
 
-	s := &Scanner{
-		Lexer: l,
+	s.source = src
+	s.offset = 0
 
-		source:      src,
-		cutPoints:   []int{0},
-		concurrency: 1,
-	}
+	s.lineStart = true
+	s.lineEnd = true
 
-	s.cutPoints, s.concurrency = s.findCutPoints(opts.Concurrency)
+	s.mode = ModeInitial
+	s.prev = nil
+	s.more = false
 
-	s.pools = make([]*Pool[stack[Token]], s.concurrency)
+	// Sources lexed in parallel are split into more portions than threads, so that threads done with their own
+	// portions can take over the ones of the others, which may be slower to lex.
+	chunks := opts.Concurrency
+	if chunks > 1 {
+		chunks *= chunksPerThread
+	}
+
+	s.cutPoints, s.atCutPoint = s.findCutPoints(chunks)
+	s.concurrency = min(opts.Concurrency, len(s.cutPoints)-1)
 
 	if opts.AvgTokenLength < 1 {
 		opts.AvgTokenLength = 1
 	}
 
-	stacksNum := stacksCount[Token](s.source, s.concurrency, opts.AvgTokenLength)
+	s.state = s.Lexer.StateFunc.call(len(s.source), s.concurrency, opts.AvgTokenLength)
+
+	s.threadStates = make([]any, s.concurrency)
+	for thread := range s.threadStates {
+		s.threadStates[thread] = s.Lexer.ThreadStateFunc.call(len(s.source), s.concurrency, opts.AvgTokenLength)
+	}
+
+	s.indexLines = opts.sourceMap != nil
+	s.lines = nil
+
+	stacksNum := stacksCount[Token](len(s.source), s.concurrency, opts.AvgTokenLength)
 
 	for thread := 0; thread < s.concurrency; thread++ {
-		s.pools[thread] = NewPool(stacksNum, WithConstructor(newStack[Token]))
+		if thread < len(s.pools) {
+			s.pools[thread].Reset()
+		} else {
+			s.pools = append(s.pools, NewPool(stacksNum, WithConstructor(newStack[Token])))
+		}
+	}
+}
+
+// minWindowChunkLength is the length under which the windows of a source read in windows are not split further,
+// since lexing portions that short in parallel is not worth it.
+const minWindowChunkLength = 4096
+
+// minWindowStackLength bounds from below the length of the stacks holding the tokens of a window.
+const minWindowStackLength = 16
+
+// windowScanner returns a Scanner for a source of about srcLen bytes read in windows of opts.WindowSize bytes,
+// which are loaded one at a time.
+// Unlike reset, it builds the states of the lexer only once for the whole source, and keeps the tokens of every window
+// in the same pools, which are never rewound: their stacks are sized after a portion of a window.
+func (l *Lexer) windowScanner(srcLen int, opts *RunOptions) *Scanner {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+
+	if opts.AvgTokenLength < 1 {
+		opts.AvgTokenLength = 1
+	}
+
+	s := &Scanner{
+		Lexer:        l,
+		concurrency:  opts.Concurrency,
+		state:        l.StateFunc.call(srcLen, opts.Concurrency, opts.AvgTokenLength),
+		threadStates: make([]any, opts.Concurrency),
+		indexLines:   opts.sourceMap != nil,
+		pools:        make([]*Pool[stack[Token]], opts.Concurrency),
+	}
+
+	stackLen := opts.WindowSize / opts.AvgTokenLength / windowChunks(opts.WindowSize, opts.Concurrency)
+	stackLen = min(max(stackLen, minWindowStackLength), stackSize[Token]())
+
+	stacksNum := srcLen / opts.AvgTokenLength / opts.Concurrency / stackLen
+
+	for thread := range s.threadStates {
+		s.threadStates[thread] = l.ThreadStateFunc.call(srcLen, opts.Concurrency, opts.AvgTokenLength)
+		s.pools[thread] = NewPool(stacksNum, WithConstructor(newStackFactory[Token](stackLen)))
 	}
 
 	return s
 }
 
-// findCutPoints cuts the source string at specific points determined by the lexer description file.
-// It returns a slice containing the cut points indices in the source string, and the number of goroutines to spawn to handle them.
-func (s *Scanner) findCutPoints(maxConcurrency int) ([]int, int) {
-	sourceLen := len(s.source)
-	avgBytesPerThread := sourceLen / maxConcurrency
+// load prepares a Scanner built by windowScanner to tokenize w, which follows the window lexed last.
+// It begins in the start condition and after the token the previous window ends with.
+func (s *Scanner) load(w window) {
+	s.source = w.data
+	s.offset = w.offset
 
-	cutPoints := make([]int, maxConcurrency+1)
-	cutPoints[0] = 0
-	cutPoints[maxConcurrency] = len(s.source)
+	s.lineStart = w.lineStart
+	s.lineEnd = w.lineEnd
+	s.more = !w.last
 
-	for i := 1; i < maxConcurrency; i++ {
-		startPos := cutPoints[i-1] + avgBytesPerThread
+	s.lines = nil
 
-		pos := startPos
-		state := s.Lexer.CutPointsAutomaton[0]
+	s.cutPoints, s.atCutPoint = s.findCutPoints(windowChunks(len(w.data), len(s.threadStates)))
+	s.concurrency = min(len(s.threadStates), len(s.cutPoints)-1)
+}
 
-		for !state.IsFinal {
-			if pos >= sourceLen {
-				return append(cutPoints[0:i], cutPoints[maxConcurrency]), i
-			}
+// windowChunks returns the number of portions a window of n bytes is split into when lexed by concurrency threads.
+func windowChunks(n int, concurrency int) int {
+	chunks := concurrency
+	if chunks > 1 {
+		chunks *= chunksPerThread
+	}
 
-			stateIdx := state.Transitions[s.source[pos]]
+	return max(1, min(chunks, n/minWindowChunkLength))
+}
 
-			//No more transitions are possible, reset the Automaton state
-			if stateIdx == -1 {
-				startPos = pos + 1
-				state = s.Lexer.CutPointsAutomaton[0]
-			} else {
-				state = s.Lexer.Automaton[stateIdx]
-			}
-			pos++
+// findCutPoints cuts the source into at most n portions of similar length, at the points determined by the lexer
+// description file. It returns the positions at which the portions begin, followed by the length of the source,
+// and whether each portion begins at one of those points.
+// When no cut point follows the ideal end of a portion closely enough, the source is cut there anyway:
+// portions cut within a token are lexed again once the ones preceding them have been lexed.
+func (s *Scanner) findCutPoints(n int) ([]int, []bool) {
+	chunkLen := max(len(s.source)/n, 1)
+
+	cutPoints := make([]int, 1, n+1)
+	atCutPoint := make([]bool, 1, n)
+
+	for len(cutPoints) < n {
+		from := cutPoints[len(cutPoints)-1] + chunkLen
+		if from >= len(s.source) {
+			break
+		}
+
+		// Searching at most one portion ahead keeps cutting linear in the length of the source.
+		cutPoint, ok := s.Lexer.nextCutPoint(s.source[:min(from+chunkLen, len(s.source))], from)
+		if !ok {
+			cutPoint = from
+		}
+
+		cutPoints = append(cutPoints, cutPoint)
+		atCutPoint = append(atCutPoint, ok)
+	}
+
+	return append(cutPoints, len(s.source)), atCutPoint
+}
+
+// automaton returns the automaton matching the rules active in mode.
+func (l *Lexer) automaton(mode LexerMode) *LexerDFA {
+	if mode == ModeInitial {
+		return &l.Automaton
+	}
+
+	return &l.ModeAutomata[mode-1]
+}
+
+// nextCutPoint runs the cut points automaton over src starting from position from.
+// It returns the position at which the first match begins, or false if src contains no further cut points.
+func (l *Lexer) nextCutPoint(src []byte, from int) (int, bool) {
+	startPos := from
+
+	pos := startPos
+	dfa := &l.CutPointsAutomaton
+	state := 0
+
+	for !dfa.States[state].IsFinal {
+		if pos >= len(src) {
+			return 0, false
+		}
+
+		stateIdx := dfa.next(state, src[pos])
+
+		//No more transitions are possible, reset the Automaton state
+		if stateIdx == -1 {
+			startPos = pos + 1
+			state = 0
+		} else {
+			state = stateIdx
 		}
-		cutPoints[i] = startPos
+		pos++
 	}
 
-	return cutPoints, maxConcurrency
+	return startPos, true
 }
 
+// Lex tokenizes the source, returning its tokens split into at most as many ordered lists as the threads lexing it.
 func (s *Scanner) Lex(ctx context.Context) ([]*LOS[Token], error) {
-	resultCh := make(chan lexResult, s.concurrency)
+	chunks := len(s.cutPoints) - 1
+
+	resultCh := make(chan lexResult, chunks)
 	errCh := make(chan error, 1)
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	for thread := 0; thread < s.concurrency; thread++ {
-		w := &scannerWorker{
-			lexer:       s.Lexer,
-			id:          thread,
-			stackPool:   s.pools[thread],
-			data:        s.source[s.cutPoints[thread]:s.cutPoints[thread+1]],
-			pos:         0,
-			startingPos: s.cutPoints[thread],
-		}
+	// Each thread begins with a contiguous share of the portions, and steals from the others once done with it.
+	queues := make([]chunkQueue, s.concurrency)
+	for thread := range queues {
+		queues[thread].next = thread * chunks / s.concurrency
+		queues[thread].end = (thread + 1) * chunks / s.concurrency
+	}
 
-		go w.lex(ctx, resultCh, errCh)
+	for thread := 0; thread < s.concurrency; thread++ {
+		go s.lexChunks(ctx, thread, queues, resultCh, errCh)
 	}
 
-	lexResults := make([]*LOS[Token], s.concurrency)
+	results := make([]lexResult, chunks)
 	completed := 0
 
-	for completed < s.concurrency {
+	for completed < chunks {
 		select {
 		case result := <-resultCh:
-			lexResults[result.threadID] = result.tokens
+			results[result.chunk] = result
 			completed++
 		case err := <-errCh:
 			cancel()
@@ -144,50 +481,353 @@ func (s *Scanner) Lex(ctx context.Context) ([]*LOS[Token], error) {
 		}
 	}
 
-	return lexResults, nil
+	lexResults := make([]*LOS[Token], 0, chunks)
+	lines := make([][]int, chunks)
+
+	// pos is the position at which the tokens lexed so far end, mode the start condition the lexer is in there,
+	// and prev the last of them.
+	pos, mode, prev := s.offset, s.mode, s.prev
+
+	for chunk, result := range results {
+		// Tokens begin a new list only at cut points the tokens preceding them actually end at.
+		split := s.atCutPoint[chunk] && result.start == pos
+
+		// A portion that doesn't begin where the tokens preceding it end, in the initial start condition,
+		// was cut within a token or a start condition: it is lexed again from there.
+		// Its first token was lexed without knowing the previous one, so it is lexed again too.
+		if chunk > 0 && (result.start != pos || mode != ModeInitial || prev != nil) {
+			result = s.resync(chunk, pos, mode, prev, result)
+
+			// The portion may have been swallowed by a token beginning before it.
+			if result.err == nil && result.tokens.Length() == 0 {
+				lines[chunk] = result.lines
+				pos, mode = result.end, result.mode
+				continue
+			}
+		}
+
+		if result.err != nil {
+			return nil, result.err
+		}
+
+		switch last := len(lexResults) - 1; {
+		case split || last < 0:
+			lexResults = append(lexResults, result.tokens)
+		case lexResults[last].Length() == 0:
+			lexResults[last] = result.tokens
+		case result.tokens.Length() > 0:
+			lexResults[last].Merge(result.tokens)
+		}
+		lines[chunk] = result.lines
+
+		pos, mode = result.end, result.mode
+		if result.last != nil {
+			prev = result.last
+		}
+	}
+
+	s.mode = mode
+	s.prev = prev
+	s.consumed = pos - s.offset
+
+	if s.indexLines {
+		s.lines = slices.Concat(lines...)
+
+		// Lines beginning in the unconsumed part of the source are indexed along with it.
+		for len(s.lines) > 0 && s.lines[len(s.lines)-1] > pos {
+			s.lines = s.lines[:len(s.lines)-1]
+		}
+	}
+
+	return joinTokensLists(lexResults, s.concurrency), nil
+}
+
+// A chunkQueue holds the portions of the source left to a thread, which other threads may steal once done with theirs.
+type chunkQueue struct {
+	mu sync.Mutex
+
+	// next and end delimit the indices of the portions left.
+	next int
+	end  int
+}
+
+// pop takes the first portion left in the queue, returning false if there is none.
+func (q *chunkQueue) pop() (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.next >= q.end {
+		return 0, false
+	}
+
+	q.next++
+	return q.next - 1, true
+}
+
+// steal takes the last portion left in the queue, returning false if there is none.
+// Stealing from the end leaves the owner of the queue lexing contiguous portions.
+func (q *chunkQueue) steal() (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.next >= q.end {
+		return 0, false
+	}
+
+	q.end--
+	return q.end, true
+}
+
+// lexChunks is executed in parallel by each thread: it lexes the portions of the source in its own queue,
+// then the ones it can steal from the queues of the other threads, until none are left.
+func (s *Scanner) lexChunks(ctx context.Context, thread int, queues []chunkQueue, resultCh chan<- lexResult, errCh chan<- error) {
+	for ctx.Err() == nil {
+		chunk, ok := queues[thread].pop()
+		for i := 1; !ok && i < len(queues); i++ {
+			chunk, ok = queues[(thread+i)%len(queues)].steal()
+		}
+
+		if !ok {
+			return
+		}
+
+		w := s.worker(thread, chunk, s.cutPoints[chunk], s.mode, s.prev)
+
+		// Every portion but the first one is lexed speculatively, assuming that it begins at a token boundary
+		// in the initial start condition, with no token before it.
+		// The assumption is checked once the previous portions have been lexed.
+		if chunk > 0 {
+			w.mode = ModeInitial
+			w.prev = nil
+			w.speculative = true
+		}
+
+		w.lex(ctx, resultCh, errCh)
+	}
+}
+
+// resync lexes the given portion of the source again, beginning at position pos in mode after the token prev,
+// where the tokens preceding it actually end. Lexing stops as soon as it reaches the beginning of a token of the
+// speculative result in the initial start condition, following the same token: from there on,
+// the speculative result is correct and is kept.
+// It must only be called once every thread is done lexing, as it uses the resources of the first one.
+func (s *Scanner) resync(chunk int, pos int, mode LexerMode, prev *Token, speculative lexResult) lexResult {
+	w := s.worker(0, chunk, pos-s.offset, mode, prev)
+
+	it := speculative.tokens.HeadIterator()
+	next := it.Next()
+
+	// before is the speculative token preceding next, which it was lexed after.
+	var before *Token
+
+	w.sync = func(pos int) bool {
+		if w.mode != ModeInitial || pos >= speculative.modeSwitch {
+			return false
+		}
+
+		for next != nil && next.Start < pos {
+			before, next = next, it.Next()
+		}
+
+		return next != nil && next.Start == pos && sameLexeme(before, w.prev)
+	}
+
+	result, err := w.run()
+	result.lines = speculative.lines
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	if !w.synced {
+		return result
+	}
+
+	for ; next != nil; next = it.Next() {
+		result.last = result.tokens.Push(*next)
+	}
+
+	result.end, result.mode, result.err = speculative.end, speculative.mode, speculative.err
+
+	return result
+}
+
+// sameLexeme reports whether two tokens, which may be nil, have the same type and span.
+func sameLexeme(a, b *Token) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Type == b.Type && a.Start == b.Start && a.End == b.End
+}
+
+// worker returns a scannerWorker run by thread, tokenizing the given portion of the source from position start,
+// beginning in mode after the token prev. Its tokens begin before the end of the portion, but may extend past it.
+func (s *Scanner) worker(thread int, chunk int, start int, mode LexerMode, prev *Token) *scannerWorker {
+	w := &scannerWorker{
+		lexer:       s.Lexer,
+		state:       s.state,
+		threadState: s.threadStates[thread],
+		id:          thread,
+		chunk:       chunk,
+		stackPool:   s.pools[thread],
+		data:        s.source[start:],
+		end:         s.cutPoints[chunk+1] - start,
+		pos:         0,
+		startingPos: s.offset + start,
+		lineStart:   s.lineStart,
+		lineEnd:     s.lineEnd,
+		more:        s.more,
+		mode:        mode,
+		modeSwitch:  math.MaxInt,
+		prev:        prev,
+		indexLines:  s.indexLines,
+	}
+
+	if start > 0 {
+		w.lineStart = s.source[start-1] == '\n'
+	}
+
+	return w
+}
+
+// sourceMap returns the SourceMap of the source lexed last. Its lines must have been indexed.
+func (s *Scanner) sourceMap() *SourceMap {
+	m := &SourceMap{}
+	m.add(s.offset, s.source, s.lines)
+
+	return m
 }
 
 // worker implements the tokenizing logic on a subset of the source string.
 type scannerWorker struct {
-	lexer *Lexer
+	lexer       *Lexer
+	state       any
+	threadState any
 
 	id        int
 	stackPool *Pool[stack[Token]]
 
+	// chunk is the index of the portion of the source the worker tokenizes.
+	chunk int
+
+	// data holds the source from the position the worker begins at, and end is the position past which no token may begin.
 	data []byte
+	end  int
 	pos  int
 
 	startingPos int
+
+	// lineStart and lineEnd report whether data begins at the start of a line and ends at the end of one.
+	lineStart bool
+	lineEnd   bool
+
+	// more reports whether the source continues past data, so that tokens reaching its end may be incomplete.
+	more bool
+
+	// mode is the current start condition, and modeSwitch the position at which it first changed.
+	mode       LexerMode
+	modeSwitch int
+
+	// prev is the last token produced, or nil if there is none.
+	prev *Token
+
+	// ctx is passed to actions and to the TokenSource of the lexer.
+	ctx LexContext
+
+	// A speculative worker may begin within a token or in the wrong start condition,
+	// so its errors are only reported along with its result.
+	speculative bool
+
+	// sync is called at the beginning of each token, if set: lexing stops when it reports true, and synced is set.
+	sync   func(pos int) bool
+	synced bool
+
+	indexLines bool
 }
 
 type lexResult struct {
-	threadID int
-	tokens   *LOS[Token]
+	chunk  int
+	tokens *LOS[Token]
+	lines  []int
+
+	// start and end are the positions at which the worker began and stopped lexing.
+	start int
+	end   int
+
+	// last is the last token of the result, or nil if it is empty.
+	last *Token
+
+	// mode is the start condition in which lexing stopped, and modeSwitch the position at which it first changed.
+	mode       LexerMode
+	modeSwitch int
+
+	// err is the error encountered by a speculative worker.
+	err error
 }
 
-// lex is the lexing function executed in parallel by each thread.
+// lex tokenizes the portion of the source assigned to the worker, sending its result or error.
 func (w *scannerWorker) lex(ctx context.Context, resultCh chan<- lexResult, errCh chan<- error) {
-	los := NewLOS[Token](w.stackPool)
+	result, err := w.run()
+	if err != nil {
+		if !w.speculative {
+			errCh <- err
+			return
+		}
+
+		result.err = err
+	}
+
+	resultCh <- result
+}
+
+// run tokenizes the data of the worker.
+// Along with an error, it returns the tokens preceding it.
+func (w *scannerWorker) run() (lexResult, error) {
+	result := lexResult{
+		chunk:  w.chunk,
+		tokens: NewLOS[Token](w.stackPool),
+		start:  w.startingPos,
+	}
+
+	if w.indexLines {
+		result.lines = lineStarts(nil, w.data[:max(0, min(w.end, len(w.data)))], w.startingPos)
+	}
 
 	var token Token
 
 	for {
 		token.Value = nil
-		result := w.next(&token)
-		if result != LexOK {
-			if result == LexEOF {
-				resultCh <- lexResult{
-					threadID: w.id,
-					tokens:   los,
-				}
-				return
-			}
+		switch w.next(&token) {
+		case LexOK:
+			w.prev = result.tokens.Push(token)
+			result.last = w.prev
+		case LexEOF:
+			result.end = w.startingPos + w.pos
+			result.mode = w.mode
+			result.modeSwitch = w.modeSwitch
 
-			errCh <- ErrInvalid
-			return
+			return result, nil
+		default:
+			return result, w.lexError()
 		}
+	}
+}
+
+// lexErrorContext is the number of bytes surrounding the offending one that are reported in a LexError.
+const lexErrorContext = 16
+
+// lexError describes a failure at the current position of the worker.
+func (w *scannerWorker) lexError() *LexError {
+	pos := min(w.pos, len(w.data))
 
-		los.Push(token)
+	// Copying the context avoids keeping the whole source alive through the error.
+	from := max(0, pos-lexErrorContext)
+	to := min(len(w.data), pos+lexErrorContext+1)
+
+	return &LexError{
+		Offset:  w.startingPos + pos,
+		Context: string(w.data[from:to]),
 	}
 }
 
@@ -203,28 +843,63 @@ const (
 // next scans the input text and returns the next Token.
 func (w *scannerWorker) next(token *Token) LexResult {
 	for {
-		var lastFinalStateReached *LexerDFAState = nil
+		lastRuleReached := -1
 		var lastFinalStatePos int
 
+		// Tokens beginning past the end of the portion assigned to the worker are left to the following one.
+		if w.pos >= w.end {
+			return LexEOF
+		}
+		if w.sync != nil && w.sync(w.startingPos+w.pos) {
+			w.synced = true
+			return LexEOF
+		}
+
+		if w.lexer.Source != nil {
+			if result, ok := w.source(token); ok {
+				if result == LexSkip {
+					continue
+				}
+
+				return result
+			}
+		}
+
 		startPos := w.pos
-		state := &w.lexer.Automaton[0]
+		dfa := w.lexer.automaton(w.mode)
+		stateIdx := 0
 		for {
 			// If we reach the end of the source data, return EOF.
 			if w.pos == len(w.data) {
-				return LexEOF
+				// The token may continue in the data that follows.
+				if w.more {
+					w.pos = startPos
+					return LexEOF
+				}
+
+				// A final state may have been skipped because of the anchors of its rules, fall back to the last match.
+				if lastRuleReached == -1 {
+					return LexEOF
+				}
+
+				result := w.advance(token, lastFinalStatePos, lastRuleReached, startPos)
+				if result == LexSkip {
+					break
+				}
+
+				return result
 			}
 
-			stateIdx := state.Transitions[w.data[w.pos]]
+			stateIdx = dfa.next(stateIdx, w.data[w.pos])
 
 			// If we are in an invalid state:
 			if stateIdx == -1 {
 				// If we haven't reached any final state so far, return an error.
-				if lastFinalStateReached == nil {
-					fmt.Printf("could not parse token '%s' in surrounding %s at position %d\n", string(w.data[startPos:w.pos+1]), string(w.data), startPos)
+				if lastRuleReached == -1 {
 					return LexErr
 				}
 
-				result := w.advance(token, lastFinalStatePos, lastFinalStateReached, startPos)
+				result := w.advance(token, lastFinalStatePos, lastRuleReached, startPos)
 				if result == LexSkip {
 					break
 				}
@@ -232,7 +907,7 @@ func (w *scannerWorker) next(token *Token) LexResult {
 				return result
 			}
 
-			state = &w.lexer.Automaton[stateIdx]
+			state := &dfa.States[stateIdx]
 
 			// If the state is not final, keep lexing.
 			if !state.IsFinal {
@@ -240,11 +915,18 @@ func (w *scannerWorker) next(token *Token) LexResult {
 				continue
 			}
 
-			lastFinalStateReached = state
+			// If none of the rules of the state can match at this position because of their anchors, keep lexing.
+			rule, ok := w.rule(state, startPos, w.pos+1)
+			if !ok {
+				w.pos++
+				continue
+			}
+
+			lastRuleReached = rule
 			lastFinalStatePos = w.pos
 
-			if w.pos == len(w.data)-1 {
-				result := w.advance(token, lastFinalStatePos, lastFinalStateReached, startPos)
+			if w.pos == len(w.data)-1 && !w.more {
+				result := w.advance(token, lastFinalStatePos, lastRuleReached, startPos)
 				if result == LexSkip {
 					break
 				}
@@ -257,9 +939,91 @@ func (w *scannerWorker) next(token *Token) LexResult {
 	}
 }
 
-func (w *scannerWorker) advance(token *Token, lastFinalStatePos int, lastFinalStateReached *LexerDFAState, startPos int) LexResult {
+// source asks the TokenSource of the lexer for the token beginning at the current position.
+// It reports false if the token is left to the automata.
+func (w *scannerWorker) source(token *Token) (LexResult, bool) {
+	mode := w.mode
+
+	w.ctx = LexContext{
+		Start:       w.startingPos + w.pos,
+		Thread:      w.id,
+		Token:       token,
+		Prev:        w.prev,
+		State:       w.state,
+		ThreadState: w.threadState,
+		Mode:        &w.mode,
+	}
+
+	n, result := w.lexer.Source.Token(w.data[w.pos:], &w.ctx)
+	switch {
+	case result == LexEOF:
+		// The lexeme may continue in the data that follows.
+		if w.more {
+			return LexEOF, true
+		}
+
+		return LexErr, true
+	case result == LexErr || n > len(w.data)-w.pos:
+		return LexErr, true
+	case n <= 0:
+		w.mode = mode
+		return LexOK, false
+	}
+
+	token.Start = w.startingPos + w.pos
+	token.End = token.Start + n - 1
+
+	w.pos += n
+	if w.mode != mode && w.modeSwitch == math.MaxInt {
+		w.modeSwitch = w.startingPos + w.pos
+	}
+
+	return result, true
+}
+
+// rule returns the rule matched by data[start:end], which leads to the final state.
+// It is the first rule of the state whose anchors are satisfied, if any.
+func (w *scannerWorker) rule(state *LexerDFAState, start int, end int) (int, bool) {
+	if w.lexer.Anchors == nil {
+		return state.AssociatedRules[0], true
+	}
+
+	for _, rule := range state.AssociatedRules {
+		anchors := w.lexer.Anchors[rule]
+
+		if anchors&AnchorLineStart != 0 && !w.atLineStart(start) {
+			continue
+		}
+		if anchors&AnchorLineEnd != 0 && !w.atLineEnd(end) {
+			continue
+		}
+
+		return rule, true
+	}
+
+	return 0, false
+}
+
+// atLineStart reports whether a line begins at position pos of the data.
+func (w *scannerWorker) atLineStart(pos int) bool {
+	if pos == 0 {
+		return w.lineStart
+	}
+
+	return w.data[pos-1] == '\n'
+}
+
+// atLineEnd reports whether a line ends at position pos of the data.
+func (w *scannerWorker) atLineEnd(pos int) bool {
+	if pos == len(w.data) {
+		return w.lineEnd
+	}
+
+	return isLineBreak(w.data[pos])
+}
+
+func (w *scannerWorker) advance(token *Token, lastFinalStatePos int, ruleNum int, startPos int) LexResult {
 	w.pos = lastFinalStatePos + 1
-	ruleNum := lastFinalStateReached.AssociatedRules[0]
 
 	// TODO: should be changed to safe code when Run supports no-op []byte to string conversion
 	//text := unsafe.String(unsafe.SliceData(w.data[startPos:w.pos]), w.pos - startPos)
@@ -270,5 +1034,28 @@ func (w *scannerWorker) advance(token *Token, lastFinalStatePos int, lastFinalSt
 	tokenStart := w.startingPos + startPos
 	tokenEnd := tokenStart + w.pos - startPos - 1
 
-	return w.lexer.Func(ruleNum, text, tokenStart, tokenEnd, w.id, token)
+	token.Start = tokenStart
+	token.End = tokenEnd
+
+	mode := w.mode
+
+	w.ctx = LexContext{
+		Rule:        ruleNum,
+		Text:        text,
+		Start:       tokenStart,
+		End:         tokenEnd,
+		Thread:      w.id,
+		Token:       token,
+		Prev:        w.prev,
+		State:       w.state,
+		ThreadState: w.threadState,
+		Mode:        &w.mode,
+	}
+
+	result := w.lexer.Func(&w.ctx)
+	if w.mode != mode && w.modeSwitch == math.MaxInt {
+		w.modeSwitch = w.startingPos + w.pos
+	}
+
+	return result
 }
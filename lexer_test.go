@@ -0,0 +1,515 @@
+package gopapageno
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// anchoredLexer returns a lexer whose rules 0 and 1 both match the letter a, the first one only at the start of a line,
+// while rule 2 matches and skips spaces and newlines.
+func anchoredLexer() *Lexer {
+	transitions := make([][256]int, 3)
+	for i := range transitions {
+		for c := range transitions[i] {
+			transitions[i][c] = -1
+		}
+	}
+
+	transitions[0]['a'] = 1
+	transitions[0][' '] = 2
+	transitions[0]['\n'] = 2
+
+	states := []LexerDFAState{
+		{},
+		{IsFinal: true, AssociatedRules: []int{0, 1}},
+		{IsFinal: true, AssociatedRules: []int{2}},
+	}
+
+	return &Lexer{
+		Automaton:          NewLexerDFA(states, transitions),
+		CutPointsAutomaton: newlineCutPoints(),
+		Func: func(ctx *LexContext) LexResult {
+			if ctx.Rule == 2 {
+				return LexSkip
+			}
+
+			ctx.Token.Type = TokenTerm + TokenType(ctx.Rule)
+			return LexOK
+		},
+		Anchors: []LexerAnchors{AnchorLineStart, 0, 0},
+	}
+}
+
+func TestScanner_Anchors(t *testing.T) {
+	src := []byte("a a\na a\n a")
+
+	for _, concurrency := range []int{1, 3} {
+		s := anchoredLexer().Scanner(src, &RunOptions{Concurrency: concurrency})
+
+		lists, err := s.Lex(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var rules []int
+		for _, l := range lists {
+			it := l.HeadIterator()
+			for token := it.Next(); token != nil; token = it.Next() {
+				rules = append(rules, int(token.Type.Value()))
+			}
+		}
+
+		if expected := []int{0, 1, 0, 1, 1}; !slices.Equal(rules, expected) {
+			t.Errorf("with concurrency %d, expected rules %v, got %v", concurrency, expected, rules)
+		}
+	}
+}
+
+func TestNewLexerDFA(t *testing.T) {
+	transitions := make([][256]int, 2)
+	for c := range transitions[0] {
+		transitions[0][c] = -1
+		transitions[1][c] = -1
+	}
+	for c := '0'; c <= '9'; c++ {
+		transitions[0][c] = 1
+		transitions[1][c] = 1
+	}
+	transitions[1]['.'] = 0
+
+	dfa := NewLexerDFA([]LexerDFAState{{}, {IsFinal: true}}, transitions)
+
+	if dfa.NumClasses != 3 {
+		t.Errorf("expected 3 classes, got %d", dfa.NumClasses)
+	}
+
+	for state := range transitions {
+		for c := range transitions[state] {
+			if next := dfa.next(state, byte(c)); next != transitions[state][c] {
+				t.Errorf("expected state %d to reach %d through %q, got %d", state, transitions[state][c], c, next)
+			}
+		}
+	}
+}
+
+// singleByteDFA returns an automaton matching single bytes: byte c matches rules[c], unless it is negative.
+func singleByteDFA(rules [256]int) LexerDFA {
+	states := []LexerDFAState{{}}
+	transitions := [][256]int{{}}
+
+	finalStates := make(map[int]int)
+	for c, rule := range rules {
+		transitions[0][c] = -1
+		if rule < 0 {
+			continue
+		}
+
+		if _, ok := finalStates[rule]; !ok {
+			finalStates[rule] = len(states)
+			states = append(states, LexerDFAState{IsFinal: true, AssociatedRules: []int{rule}})
+		}
+		transitions[0][c] = finalStates[rule]
+	}
+
+	for len(transitions) < len(states) {
+		var none [256]int
+		for c := range none {
+			none[c] = -1
+		}
+		transitions = append(transitions, none)
+	}
+
+	return NewLexerDFA(states, transitions)
+}
+
+// stringsLexer returns a lexer with a start condition for strings. In the initial one, rule 0 matches the letter a,
+// rule 1 begins a string and rule 2 skips spaces and newlines. Within strings, rule 3 matches any byte but the quote
+// ending them, which is matched by rule 4.
+func stringsLexer() *Lexer {
+	var initialRules, stringRules [256]int
+	for c := range initialRules {
+		initialRules[c] = -1
+		stringRules[c] = 3
+	}
+
+	initialRules['a'] = 0
+	initialRules['"'] = 1
+	initialRules[' '] = 2
+	initialRules['\n'] = 2
+	stringRules['"'] = 4
+
+	return &Lexer{
+		Automaton:          singleByteDFA(initialRules),
+		ModeAutomata:       []LexerDFA{singleByteDFA(stringRules)},
+		CutPointsAutomaton: newlineCutPoints(),
+		Func: func(ctx *LexContext) LexResult {
+			switch ctx.Rule {
+			case 1:
+				*ctx.Mode = 1
+				return LexSkip
+			case 2:
+				return LexSkip
+			case 4:
+				*ctx.Mode = ModeInitial
+				return LexSkip
+			}
+
+			ctx.Token.Type = TokenTerm + TokenType(ctx.Rule)
+			return LexOK
+		},
+	}
+}
+
+func TestScanner_Modes(t *testing.T) {
+	// With concurrency 3, the second portion begins within the string.
+	src := []byte("\"x\ny\nz\" a\na\na")
+
+	for _, concurrency := range []int{1, 3} {
+		s := stringsLexer().Scanner(src, &RunOptions{Concurrency: concurrency})
+
+		lists, err := s.Lex(context.Background())
+		if err != nil {
+			t.Fatalf("with concurrency %d, unexpected error: %v", concurrency, err)
+		}
+
+		var rules []int
+		for _, l := range lists {
+			it := l.HeadIterator()
+			for token := it.Next(); token != nil; token = it.Next() {
+				rules = append(rules, int(token.Type.Value()))
+			}
+		}
+
+		if expected := []int{3, 3, 3, 3, 3, 0, 0, 0}; !slices.Equal(rules, expected) {
+			t.Errorf("with concurrency %d, expected rules %v, got %v", concurrency, expected, rules)
+		}
+
+		if s.mode != ModeInitial {
+			t.Errorf("with concurrency %d, expected to end in the initial mode, got %d", concurrency, s.mode)
+		}
+	}
+}
+
+// quotedLexer returns a lexer whose rule 0 matches the letter a, rule 1 matches quoted strings, which may span lines,
+// and rule 2 skips spaces and newlines.
+func quotedLexer() *Lexer {
+	transitions := make([][256]int, 5)
+	for i := range transitions {
+		for c := range transitions[i] {
+			transitions[i][c] = -1
+		}
+	}
+
+	transitions[0]['a'] = 1
+	transitions[0][' '] = 2
+	transitions[0]['\n'] = 2
+	transitions[0]['"'] = 3
+	for c := range transitions[3] {
+		transitions[3][c] = 3
+	}
+	transitions[3]['"'] = 4
+
+	states := []LexerDFAState{
+		{},
+		{IsFinal: true, AssociatedRules: []int{0}},
+		{IsFinal: true, AssociatedRules: []int{2}},
+		{},
+		{IsFinal: true, AssociatedRules: []int{1}},
+	}
+
+	return &Lexer{
+		Automaton:          NewLexerDFA(states, transitions),
+		CutPointsAutomaton: newlineCutPoints(),
+		Func: func(ctx *LexContext) LexResult {
+			if ctx.Rule == 2 {
+				return LexSkip
+			}
+
+			ctx.Token.Type = TokenTerm + TokenType(ctx.Rule)
+			return LexOK
+		},
+	}
+}
+
+// lexedSpans returns the rule and the span of each token in lists.
+func lexedSpans(lists []*LOS[Token]) [][3]int {
+	var spans [][3]int
+	for _, l := range lists {
+		it := l.HeadIterator()
+		for token := it.Next(); token != nil; token = it.Next() {
+			spans = append(spans, [3]int{int(token.Type.Value()), token.Start, token.End})
+		}
+	}
+
+	return spans
+}
+
+func TestScanner_CutWithinToken(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected [][3]int
+	}{
+		// The second portion begins within the string.
+		{"a \"x\ny\nz\" a\na", [][3]int{{0, 0, 0}, {1, 2, 8}, {0, 10, 10}, {0, 12, 12}}},
+		// The string swallows the second portion, and ends in the third one.
+		{"a\"" + strings.Repeat("\n", 12) + "\"a\na\na", [][3]int{{0, 0, 0}, {1, 1, 14}, {0, 15, 15}, {0, 17, 17}, {0, 19, 19}}},
+	}
+
+	for _, test := range tests {
+		for _, concurrency := range []int{1, 2, 3} {
+			s := quotedLexer().Scanner([]byte(test.src), &RunOptions{Concurrency: concurrency})
+
+			lists, err := s.Lex(context.Background())
+			if err != nil {
+				t.Fatalf("lexing %q with concurrency %d: unexpected error: %v", test.src, concurrency, err)
+			}
+
+			if spans := lexedSpans(lists); !slices.Equal(spans, test.expected) {
+				t.Errorf("lexing %q with concurrency %d: expected tokens %v, got %v", test.src, concurrency, test.expected, spans)
+			}
+
+			for i, l := range lists {
+				if l.Length() == 0 {
+					t.Errorf("lexing %q with concurrency %d: list %d is empty", test.src, concurrency, i)
+				}
+			}
+		}
+	}
+}
+
+func TestScanner_More(t *testing.T) {
+	s := quotedLexer().Scanner([]byte("a a \"x\ny"), &RunOptions{Concurrency: 2})
+	s.more = true
+
+	lists, err := s.Lex(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected, spans := [][3]int{{0, 0, 0}, {0, 2, 2}}, lexedSpans(lists); !slices.Equal(spans, expected) {
+		t.Errorf("expected tokens %v, got %v", expected, spans)
+	}
+
+	// The string may continue past the source.
+	if s.consumed != 4 {
+		t.Errorf("expected 4 bytes to be consumed, got %d", s.consumed)
+	}
+}
+
+func TestScanner_Oversplit(t *testing.T) {
+	// A long string is followed by many short tokens, so most portions are swallowed by the string.
+	src := []byte("\"" + strings.Repeat("x\n", 200) + "\"" + strings.Repeat(" a\n", 200))
+
+	lists, err := quotedLexer().Scanner(src, &RunOptions{Concurrency: 1}).Lex(context.Background())
+	if err != nil {
+		t.Fatalf("lexing sequentially: unexpected error: %v", err)
+	}
+	expected := lexedSpans(lists)
+
+	for _, concurrency := range []int{2, 4, 7} {
+		s := quotedLexer().Scanner(src, &RunOptions{Concurrency: concurrency})
+
+		if chunks := len(s.cutPoints) - 1; chunks != concurrency*chunksPerThread {
+			t.Errorf("concurrency %d: expected %d portions, got %d", concurrency, concurrency*chunksPerThread, chunks)
+		}
+
+		lists, err := s.Lex(context.Background())
+		if err != nil {
+			t.Fatalf("concurrency %d: unexpected error: %v", concurrency, err)
+		}
+
+		if len(lists) > concurrency {
+			t.Errorf("concurrency %d: expected at most %d lists, got %d", concurrency, concurrency, len(lists))
+		}
+
+		if spans := lexedSpans(lists); !slices.Equal(spans, expected) {
+			t.Errorf("concurrency %d: expected tokens %v, got %v", concurrency, expected, spans)
+		}
+	}
+}
+
+func TestScanner_ListsBeginAtCutPoints(t *testing.T) {
+	for _, src := range []string{
+		strings.Repeat("a ", 100),
+		strings.Repeat("a a a a a a a\n", 20),
+		strings.Repeat("a ", 50) + "\n" + strings.Repeat("a ", 50),
+	} {
+		for _, concurrency := range []int{2, 3, 7} {
+			lists, err := quotedLexer().Scanner([]byte(src), &RunOptions{Concurrency: concurrency}).Lex(context.Background())
+			if err != nil {
+				t.Fatalf("concurrency %d: unexpected error: %v", concurrency, err)
+			}
+
+			// Portions are cut within lines anyway, but lists may only begin after a newline.
+			for i, l := range lists[1:] {
+				if first := l.HeadIterator().Next(); src[first.Start-1] != '\n' {
+					t.Errorf("concurrency %d: list %d begins at %d, which doesn't follow a cut point", concurrency, i+1, first.Start)
+				}
+			}
+
+			if !strings.Contains(src, "\n") && len(lists) != 1 {
+				t.Errorf("concurrency %d: expected a single list for a source without cut points, got %d", concurrency, len(lists))
+			}
+		}
+	}
+}
+
+// nestedCommentsLexer returns a lexer whose rule 0 matches the letter a and rule 2 skips spaces and newlines,
+// while comments delimited by "(*" and "*)", which may be nested, are read by hand as tokens of rule 1.
+func nestedCommentsLexer() *Lexer {
+	var rules [256]int
+	for c := range rules {
+		rules[c] = -1
+	}
+
+	rules['a'] = 0
+	rules[' '] = 2
+	rules['\n'] = 2
+
+	comments := func(src []byte, ctx *LexContext) (int, LexResult) {
+		if !bytes.HasPrefix(src, []byte("(*")) {
+			return 0, LexOK
+		}
+
+		depth := 0
+		for i := 0; i+1 < len(src); i++ {
+			switch string(src[i : i+2]) {
+			case "(*":
+				depth++
+				i++
+			case "*)":
+				depth--
+				i++
+			}
+
+			if depth == 0 {
+				ctx.Token.Type = TokenTerm + 1
+				return i + 1, LexOK
+			}
+		}
+
+		return 0, LexEOF
+	}
+
+	return &Lexer{
+		Automaton:          singleByteDFA(rules),
+		CutPointsAutomaton: newlineCutPoints(),
+		Func: func(ctx *LexContext) LexResult {
+			if ctx.Rule == 2 {
+				return LexSkip
+			}
+
+			ctx.Token.Type = TokenTerm + TokenType(ctx.Rule)
+			return LexOK
+		},
+		Source: TokenSourceFunc(comments),
+	}
+}
+
+func TestScanner_TokenSource(t *testing.T) {
+	src := "a (* x\n(* y\n*) a\n*) a\na"
+	expected := [][3]int{{0, 0, 0}, {1, 2, 18}, {0, 20, 20}, {0, 22, 22}}
+
+	for _, concurrency := range []int{1, 2, 3} {
+		lists, err := nestedCommentsLexer().Scanner([]byte(src), &RunOptions{Concurrency: concurrency}).Lex(context.Background())
+		if err != nil {
+			t.Fatalf("concurrency %d: unexpected error: %v", concurrency, err)
+		}
+
+		if spans := lexedSpans(lists); !slices.Equal(spans, expected) {
+			t.Errorf("concurrency %d: expected tokens %v, got %v", concurrency, expected, spans)
+		}
+	}
+
+	// An unterminated comment is an error at the end of the source, but may continue in the data that follows.
+	s := nestedCommentsLexer().Scanner([]byte("a (* x"), &RunOptions{Concurrency: 1})
+	if _, err := s.Lex(context.Background()); err == nil {
+		t.Errorf("expected an error lexing an unterminated comment")
+	}
+
+	s.reset([]byte("a (* x"), &RunOptions{Concurrency: 1})
+	s.more = true
+
+	if _, err := s.Lex(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.consumed != 2 {
+		t.Errorf("expected 2 bytes to be consumed, got %d", s.consumed)
+	}
+}
+
+// alternatingLexer returns a lexer whose rule 0 matches the letter a, unless it follows a token of rule 0:
+// then it is a token of rule 1. Rule 2 skips spaces and newlines. Each thread counts the tokens it produces.
+func alternatingLexer() *Lexer {
+	var rules [256]int
+	for c := range rules {
+		rules[c] = -1
+	}
+
+	rules['a'] = 0
+	rules[' '] = 2
+	rules['\n'] = 2
+
+	return &Lexer{
+		Automaton:          singleByteDFA(rules),
+		CutPointsAutomaton: newlineCutPoints(),
+		Func: func(ctx *LexContext) LexResult {
+			if ctx.Rule == 2 {
+				return LexSkip
+			}
+
+			*ctx.ThreadState.(*int)++
+
+			rule := ctx.Rule
+			if ctx.Prev != nil && ctx.Prev.Type == TokenTerm {
+				rule = 1
+			}
+
+			ctx.Token.Type = TokenTerm + TokenType(rule)
+			return LexOK
+		},
+		ThreadStateFunc: func(sourceLen, concurrency, avgTokenLength int) any {
+			return new(int)
+		},
+	}
+}
+
+func TestScanner_PreviousToken(t *testing.T) {
+	src := []byte(strings.Repeat("a\n", 101))
+
+	for _, concurrency := range []int{1, 2, 5} {
+		s := alternatingLexer().Scanner(src, &RunOptions{Concurrency: concurrency})
+
+		lists, err := s.Lex(context.Background())
+		if err != nil {
+			t.Fatalf("concurrency %d: unexpected error: %v", concurrency, err)
+		}
+
+		spans := lexedSpans(lists)
+		if len(spans) != 101 {
+			t.Fatalf("concurrency %d: expected 101 tokens, got %d", concurrency, len(spans))
+		}
+
+		for i, span := range spans {
+			if span[0] != i%2 {
+				t.Errorf("concurrency %d: expected token %d to be of rule %d, got %d", concurrency, i, i%2, span[0])
+			}
+		}
+
+		if s.prev == nil || s.prev.Start != 200 {
+			t.Errorf("concurrency %d: expected the last token to begin at 200, got %v", concurrency, s.prev)
+		}
+
+		// Tokens lexed speculatively may be lexed again.
+		counted := 0
+		for _, state := range s.threadStates {
+			counted += *state.(*int)
+		}
+		if counted < len(spans) {
+			t.Errorf("concurrency %d: expected threads to count at least %d tokens, got %d", concurrency, len(spans), counted)
+		}
+	}
+}
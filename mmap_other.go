@@ -0,0 +1,18 @@
+//go:build !unix
+
+package gopapageno
+
+import (
+	"fmt"
+	"os"
+)
+
+// MapFile reads the file at filename, since memory mapping is not supported on this platform.
+func MapFile(filename string) (*MappedFile, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file: %w", err)
+	}
+
+	return &MappedFile{data: data}, nil
+}
@@ -0,0 +1,40 @@
+//go:build unix
+
+package gopapageno
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// MapFile maps the file at filename in memory, read-only.
+func MapFile(filename string) (*MappedFile, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("could not stat file: %w", err)
+	}
+
+	// Empty files can't be mapped.
+	if info.Size() == 0 {
+		return &MappedFile{data: []byte{}}, nil
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("could not map file: %w", err)
+	}
+
+	return &MappedFile{
+		data: data,
+		close: func() error {
+			return syscall.Munmap(data)
+		},
+	}, nil
+}
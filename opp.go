@@ -3,6 +3,7 @@ package gopapageno
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 )
 
 type OPParser struct {
@@ -11,6 +12,18 @@ type OPParser struct {
 	concurrency       int
 	reductionStrategy ReductionStrategy
 
+	// srcLen is the length of the source, used as the position of the end of input.
+	srcLen int
+
+	recovery  bool
+	maxErrors int
+
+	// errorsFound counts the syntax errors recorded by every worker.
+	errorsFound atomic.Int64
+
+	// state is passed to semantic actions.
+	state any
+
 	pools struct {
 		stacks       []*Pool[stack[*Token]]
 		nonterminals []*Pool[Token]
@@ -25,16 +38,24 @@ type OPParser struct {
 }
 
 func NewOPParser(g *Grammar, src []byte, opts *RunOptions) *OPParser {
+	return newOPParser(g, len(src), opts)
+}
+
+// newOPParser allocates a OPParser sized for a source of srcLen bytes.
+func newOPParser(g *Grammar, srcLen int, opts *RunOptions) *OPParser {
 	p := &OPParser{
 		g:                 g,
 		concurrency:       opts.Concurrency,
+		srcLen:            srcLen,
+		recovery:          opts.recovery,
+		maxErrors:         opts.maxErrors,
 		reductionStrategy: opts.ReductionStrategy,
 		workers:           make([]*oppWorker, opts.Concurrency),
 		results:           make([]*OPPStack, opts.Concurrency),
+		state:             g.StateFunc.call(srcLen, opts.Concurrency, opts.AvgTokenLength),
 	}
 
-	srcLen := len(src)
-	stackPoolBaseSize := stacksCount[*Token](src, p.concurrency, opts.AvgTokenLength)
+	stackPoolBaseSize := stacksCount[*Token](srcLen, p.concurrency, opts.AvgTokenLength)
 	ntPoolBaseSize := int(float64(srcLen/opts.AvgTokenLength/p.concurrency) * 1.5)
 
 	// Initialize memory pools for stacks.
@@ -49,7 +70,7 @@ func NewOPParser(g *Grammar, src []byte, opts *RunOptions) *OPParser {
 	}
 
 	if p.concurrency > 1 && (p.reductionStrategy == ReductionSweep || p.reductionStrategy == ReductionMixed) {
-		inputPoolBaseSize := stacksCount[Token](src, p.concurrency, opts.AvgTokenLength)
+		inputPoolBaseSize := stacksCount[Token](srcLen, p.concurrency, opts.AvgTokenLength)
 
 		p.pools.sweepInput = NewPool(inputPoolBaseSize, WithConstructor(newStack[Token]))
 		p.pools.sweepStack = NewPool(stackPoolBaseSize, WithConstructor(newStack[*Token]))
@@ -71,6 +92,8 @@ type oppWorker struct {
 
 	id     int
 	ntPool *Pool[Token]
+
+	recoveryState
 }
 
 func (p *OPParser) Parse(ctx context.Context, tokensLists []*LOS[Token]) (*Token, error) {
@@ -84,15 +107,19 @@ func (p *OPParser) Parse(ctx context.Context, tokensLists []*LOS[Token]) (*Token
 
 	// First parallel pass of the algorithm.
 	for thread := 0; thread < p.concurrency; thread++ {
-		var nextToken *Token
+		s := NewOPPStack(p.pools.stacks[thread])
 
-		// If the thread is not the last, also take the first token of the next stack for lookahead.
+		// The first thread begins with a # on the stack.
+		if thread == 0 {
+			s.Push(newTermToken(0))
+		}
+
+		// The last thread ends with a #, the others take the first token of the next list for lookahead.
+		nextToken := newTermToken(p.srcLen)
 		if thread < p.concurrency-1 {
-			nextInputListIter := tokensLists[thread+1].HeadIterator()
-			nextToken = nextInputListIter.Next()
+			nextToken = lookahead(tokensLists[thread+1])
 		}
 
-		s := NewOPPStack(p.pools.stacks[thread])
 		go p.workers[thread].parse(ctx, s, tokensLists[thread], nextToken, false, resultCh, errCh)
 	}
 
@@ -149,6 +176,12 @@ func (p *OPParser) Parse(ctx context.Context, tokensLists []*LOS[Token]) (*Token
 	}
 
 	root, err := p.results[0].LastNonterminal()
+
+	// When recovering from errors the partial parse tree is returned alongside them.
+	if errs := p.syntaxErrors(); errs != nil {
+		return root, errs
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -156,10 +189,164 @@ func (p *OPParser) Parse(ctx context.Context, tokensLists []*LOS[Token]) (*Token
 	return root, nil
 }
 
+// parseStream parses the lists of tokens received from batchCh, which must be closed once the source is over.
+// Results are always reduced with a single sweep.
+func (p *OPParser) parseStream(ctx context.Context, batchCh <-chan tokensBatch) (*Token, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if p.pools.sweepInput == nil {
+		p.pools.sweepInput = NewPool(1, WithConstructor(newStack[Token]))
+		p.pools.sweepStack = NewPool(1, WithConstructor(newStack[*Token]))
+	}
+
+	resultCh := make(chan parseResult[OPPStack])
+	errCh := make(chan error, 1)
+
+	// first is the result of the first list, which is never reused. The results of the others are joined into input.
+	var first *OPPStack
+	input := NewLOS[Token](p.pools.sweepInput)
+	joined := 0
+
+	// firstPass runs the first pass on lists, the last of which is followed by next.
+	firstPass := func(lists []*LOS[Token], next *Token) error {
+		p.concurrency = len(lists)
+
+		for i, list := range lists {
+			s := NewOPPStack(p.pools.stacks[i])
+			if i == 0 && first == nil {
+				s = NewOPPStack(p.pools.sweepStack)
+				s.Push(newTermToken(0))
+			}
+
+			// The lookahead is pushed here rather than by the worker, since the list may belong to the pools of the lexer,
+			// which is still running.
+			lookaheadToken := next
+			if i < len(lists)-1 {
+				lookaheadToken = lookahead(lists[i+1])
+			}
+
+			list.pool = p.pools.sweepInput
+			list.Push(*lookaheadToken)
+
+			go p.workers[i].parse(ctx, s, list, nil, false, resultCh, errCh)
+		}
+
+		if err := collectResults[OPPStack](p.results, resultCh, errCh, len(lists)); err != nil {
+			return err
+		}
+
+		results := p.results[:len(lists)]
+		if first == nil {
+			first, results = results[0], results[1:]
+		}
+
+		pushSweepInput(input, results)
+		joined += len(results)
+
+		// The results have been copied into input, so their stacks can be used again.
+		for thread := range p.workers {
+			p.pools.stacks[thread].Reset()
+		}
+
+		return nil
+	}
+
+	var stream listStream
+
+	for batch := range batchCh {
+		p.srcLen = batch.srcLen
+
+		if lists, next := stream.add(batch.lists); len(lists) > 0 {
+			if err := firstPass(lists, next); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := firstPass([]*LOS[Token]{stream.flush(p.pools.sweepInput)}, newTermToken(p.srcLen)); err != nil {
+		return nil, err
+	}
+
+	result := first
+	if joined > 0 {
+		p.concurrency = 1
+
+		go p.workers[0].parse(ctx, first.Combine(), input, nil, true, resultCh, errCh)
+
+		if err := collectResults[OPPStack](p.results, resultCh, errCh, 1); err != nil {
+			return nil, err
+		}
+
+		result = p.results[0]
+	}
+
+	root, err := result.LastNonterminal()
+
+	// When recovering from errors the partial parse tree is returned alongside them.
+	if errs := p.syntaxErrors(); errs != nil {
+		return root, errs
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// reset prepares the parser for a new source of srcLen bytes, rewinding its pools.
+// Parse trees produced before calling reset must not be used anymore.
+func (p *OPParser) reset(srcLen int, opts *RunOptions) {
+	p.concurrency = len(p.workers)
+	p.srcLen = srcLen
+	p.recovery = opts.recovery
+	p.maxErrors = opts.maxErrors
+	p.errorsFound.Store(0)
+	p.state = p.g.StateFunc.call(srcLen, p.concurrency, opts.AvgTokenLength)
+
+	for thread := range p.workers {
+		p.pools.stacks[thread].Reset()
+		p.pools.nonterminals[thread].Reset()
+
+		p.workers[thread].recoveryState.reset()
+	}
+
+	if p.pools.sweepInput != nil {
+		p.pools.sweepInput.Reset()
+		p.pools.sweepStack.Reset()
+	}
+}
+
+// syntaxErrors returns the errors that workers recovered from as an ErrorList, or nil if there are none.
+func (p *OPParser) syntaxErrors() error {
+	var errs []*SyntaxError
+	for _, w := range p.workers {
+		errs = append(errs, w.errors...)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return newErrorList(errs, p.maxErrors)
+}
+
 func (p *OPParser) CombineSweepLOS(pool *Pool[stack[Token]], stacks []*OPPStack) *LOS[Token] {
 	input := NewLOS[Token](pool)
-	for i := 0; i < p.concurrency-1; i++ {
-		iterator := stacks[i].HeadIterator()
+	pushSweepInput(input, stacks[:p.concurrency-1])
+
+	return input
+}
+
+// pushSweepInput appends to input the tokens of stacks, which follow the first one, leaving out the first token of each.
+func pushSweepInput(input *LOS[Token], stacks []*OPPStack) {
+	for _, s := range stacks {
+		iterator := s.HeadIterator()
 
 		//Ignore the first token.
 		iterator.Next()
@@ -168,41 +355,26 @@ func (p *OPParser) CombineSweepLOS(pool *Pool[stack[Token]], stacks []*OPPStack)
 			input.Push(*token)
 		}
 	}
-	return input
 }
 
 // parse implements both OPP and AOPP strategies.
 func (w *oppWorker) parse(ctx context.Context, stack *OPPStack, tokens *LOS[Token], nextToken *Token, finalPass bool, resultCh chan<- parseResult[OPPStack], errCh chan<- error) {
 	tokensIt := tokens.HeadIterator()
 
-	// If the thread is the first, push a # onto the stack
+	// The stack of the first list already holds a #.
 	// Otherwise, push the first inputToken onto the stack
 	if !finalPass {
-		if w.id == 0 {
-			stack.Push(&Token{
-				Type:       TokenTerm,
-				Value:      nil,
-				Precedence: PrecEmpty,
-				Next:       nil,
-				Child:      nil,
-			})
-		} else {
+		// Errors found by the previous passes of the worker belong to another portion of the source.
+		w.quiet = 0
+
+		if stack.Length() == 0 {
 			t := tokensIt.Next()
 			t.Precedence = PrecEmpty
 			stack.Push(t)
 		}
 
-		// If the thread is the last, push a # onto the tokens m
-		// Otherwise, push onto the tokens m the first inputToken of the next tokens m
-		if w.id == w.parser.concurrency-1 {
-			tokens.Push(Token{
-				Type:       TokenTerm,
-				Value:      nil,
-				Precedence: PrecEmpty,
-				Next:       nil,
-				Child:      nil,
-			})
-		} else if nextToken != nil {
+		// Push onto the tokens the # ending the source, or the first token of the next list.
+		if nextToken != nil {
 			tokens.Push(*nextToken)
 		}
 	}
@@ -233,25 +405,25 @@ func (w *oppWorker) parse(ctx context.Context, stack *OPPStack, tokens *LOS[Toke
 			inputToken.Precedence = PrecEmpty
 			stack.Push(inputToken)
 
+			w.shifted()
 			inputToken = tokensIt.Next()
 			continue
 		}
 
 		//Find the first terminal on the stack and get the precedence between it and the current tokens inputToken
-		firstTerminal := stack.FirstTerminal()
-
-		var prec Precedence
-		if firstTerminal == nil {
-			prec = w.parser.g.precedence(TokenTerm, inputToken.Type)
-		} else {
-			prec = w.parser.g.precedence(firstTerminal.Type, inputToken.Type)
+		top := TokenTerm
+		if firstTerminal := stack.FirstTerminal(); firstTerminal != nil {
+			top = firstTerminal.Type
 		}
 
+		prec := w.parser.g.precedence(top, inputToken.Type)
+
 		// If it's equal in precedence or yields, push the inputToken onto the stack with its precedence relation.
 		if prec == PrecEquals || prec == PrecYields {
 			inputToken.Precedence = prec
 			stack.Push(inputToken)
 
+			w.shifted()
 			inputToken = tokensIt.Next()
 		} else if prec == PrecTakes || prec == PrecAssociative {
 			//If there are no tokens yielding precedence on the stack, push inputToken onto the stack.
@@ -260,6 +432,7 @@ func (w *oppWorker) parse(ctx context.Context, stack *OPPStack, tokens *LOS[Toke
 				inputToken.Precedence = prec
 				stack.Push(inputToken)
 
+				w.shifted()
 				inputToken = tokensIt.Next()
 			} else {
 				pos = w.parser.g.MaxRHSLength - 1
@@ -274,8 +447,11 @@ func (w *oppWorker) parse(ctx context.Context, stack *OPPStack, tokens *LOS[Toke
 
 				// TODO(vvihorev): Investigate, how it could happen that no Yield was found on the stack
 				if pos < 0 {
-					errCh <- fmt.Errorf("syntax error: no Yield precedence found on the stack %v\n%v\n", rhsBuf, rhsTokensBuf)
-					return
+					if err := w.recover(w.parser.g.syntaxError(inputToken, top, "no yielding precedence found on the stack"), stack, tokensIt, &inputToken, false); err != nil {
+						errCh <- err
+						return
+					}
+					continue
 				}
 				rhsTokensBuf[pos] = token
 				rhsBuf[pos] = token.Type
@@ -287,8 +463,11 @@ func (w *oppWorker) parse(ctx context.Context, stack *OPPStack, tokens *LOS[Toke
 				} else {
 					pos--
 					if pos < 0 {
-						errCh <- fmt.Errorf("syntax error: no Yield precedence found on the stack %v\n%v\n", rhsBuf, rhsTokensBuf)
-						return
+						if err := w.recover(w.parser.g.syntaxError(inputToken, top, "no yielding precedence found on the stack"), stack, tokensIt, &inputToken, false); err != nil {
+							errCh <- err
+							return
+						}
+						continue
 					}
 					rhsTokensBuf[pos] = token
 					rhsBuf[pos] = token.Type
@@ -303,24 +482,36 @@ func (w *oppWorker) parse(ctx context.Context, stack *OPPStack, tokens *LOS[Toke
 				//Find corresponding lhs and ruleNum
 				lhs, ruleNum := w.parser.g.findRuleMatch(rhs)
 				if lhs == TokenEmpty {
-					errCh <- fmt.Errorf("could not find match for rhs %v\n%v\n", rhs, rhsTokens)
-					return
+					// The first nonterminal of the handle stands in for it, so that the partial tree isn't lost.
+					if nt := firstNonterminal(rhsTokens); w.parser.recovery && nt != nil {
+						nt.Precedence = PrecEmpty
+						stack.Push(nt)
+					}
+
+					if err := w.recover(w.parser.g.syntaxError(inputToken, top, fmt.Sprintf("no rule matches %s", w.parser.g.sprintTypes(rhs))), stack, tokensIt, &inputToken, false); err != nil {
+						errCh <- err
+						return
+					}
+					continue
 				}
 
 				newNonTerm.Type = lhs
 				lhsToken = w.ntPool.Get()
 				*lhsToken = newNonTerm
+				lhsToken.setSpan(rhsTokens)
 
 				//Execute the semantic action
-				w.parser.g.Func(ruleNum, RuleSimple, lhsToken, rhsTokens, w.id)
+				w.parser.g.Func(ruleNum, RuleSimple, lhsToken, rhsTokens, w.id, w.parser.state)
 
 				//Push the new nonterminal onto the stack
 				stack.Push(lhsToken)
 			}
 		} else {
 			//If there's no precedence relation, abort the parsing
-			errCh <- fmt.Errorf("no precedence relation found")
-			return
+			if err := w.recover(w.parser.g.syntaxError(inputToken, top, "no precedence relation found"), stack, tokensIt, &inputToken, true); err != nil {
+				errCh <- err
+				return
+			}
 		}
 	}
 
@@ -341,7 +532,7 @@ func (w *oppWorker) parse(ctx context.Context, stack *OPPStack, tokens *LOS[Toke
 	// 		*lhsToken = newNonTerm
 
 	// 		//Execute the semantic action of the axiom
-	// 		w.parser.g.Func(ruleNum, RuleSimple, lhsToken, rhsTokens, w.id)
+	// 		w.parser.g.Func(ruleNum, RuleSimple, lhsToken, rhsTokens, w.id, w.parser.state)
 
 	// 		//Push the new axiom nonterminal onto the stack
 	// 		stack.Push(lhsToken)
@@ -358,3 +549,61 @@ func (w *oppWorker) parse(ctx context.Context, stack *OPPStack, tokens *LOS[Toke
 
 	resultCh <- parseResult[OPPStack]{w.id, stack}
 }
+
+// recover records err and resynchronizes the worker after it, skipping input up to the next synchronizing terminal
+// and popping from the stack the tokens that can't precede it.
+// If the stack didn't change since the error was found, mustPop makes sure that progress is made when no input is skipped.
+// Errors found right after recovering from another one are not reported.
+// It returns the error that must abort parsing if recovery is disabled.
+func (w *oppWorker) recover(err *SyntaxError, stack *OPPStack, tokensIt *LOSIt[Token], inputToken **Token, mustPop bool) error {
+	p := w.parser
+
+	if !p.recovery {
+		return err
+	}
+
+	// Once too many errors were found, the rest of the input is skipped, keeping the partial parse tree.
+	if w.record(err, &p.errorsFound, p.maxErrors) {
+		*inputToken = nil
+		return nil
+	}
+
+	acceptable := func(t *Token) bool {
+		top := stack.FirstTerminal()
+		return top != nil && p.g.hasPrecedence(top.Type, t.Type)
+	}
+
+	t := *inputToken
+	if mustPop {
+		// The offending token was found to be unacceptable already.
+		for t != nil && t.Type != TokenTerm && !p.g.isSync(t.Type) {
+			t = tokensIt.Next()
+		}
+	} else {
+		for t != nil && t.Type != TokenTerm && !p.g.isSync(t.Type) && !acceptable(t) {
+			t = tokensIt.Next()
+		}
+	}
+
+	// Pop at least one token if no input was skipped, so that the same error can't be found again.
+	mustPop = mustPop && t == *inputToken
+	*inputToken = t
+
+	if t == nil {
+		return nil
+	}
+
+	// The first token of the stack is never popped, since it is either # or belongs to the previous worker.
+	for stack.Length() > 1 {
+		if !mustPop && acceptable(t) {
+			break
+		}
+
+		stack.Pop()
+		stack.UpdateFirstTerminal()
+
+		mustPop = false
+	}
+
+	return nil
+}
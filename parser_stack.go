@@ -33,8 +33,12 @@ func (s *parserStack) Push(token *Token) *Token {
 	return token
 }
 
-// FirstTerminal returns a pointer to the first terminal token on the stack.
+// FirstTerminal returns a pointer to the first terminal token on the stack, or nil if there is none.
 func (s *parserStack) FirstTerminal() *Token {
+	if s.firstTerminalStack == nil {
+		return nil
+	}
+
 	return s.firstTerminalStack.Data[s.firstTerminalPos]
 }
 
@@ -58,6 +58,50 @@ func (p *Pool[T]) Get() *T {
 	return addr
 }
 
+// Reset makes every item of the pool available again.
+// If more items than its length were requested since the last reset, the pool grows geometrically
+// so that they can be served without further allocations.
+// Items obtained before calling Reset must not be used afterward.
+func (p *Pool[T]) Reset() {
+	if p.cur > len(p.pool) {
+		p.grow(max(p.cur, 2*len(p.pool)))
+	} else {
+		for i := range p.cur {
+			p.resetItem(&p.pool[i])
+		}
+	}
+
+	p.cur = 0
+}
+
+// grow replaces the items of the pool with length new ones.
+func (p *Pool[T]) grow(length int) {
+	p.pool = make([]T, length)
+
+	if p.constructor != nil {
+		for i := range p.pool {
+			p.pool[i] = *p.constructor()
+		}
+	}
+}
+
+// resetItem brings an item back to its initial state.
+// Items can provide their own reset method, otherwise they are either zeroed or built again.
+func (p *Pool[T]) resetItem(item *T) {
+	if r, ok := any(item).(interface{ reset() }); ok {
+		r.reset()
+		return
+	}
+
+	if p.constructor != nil {
+		*item = *p.constructor()
+		return
+	}
+
+	var zero T
+	*item = zero
+}
+
 // Left returns the number of items remaining in the pool.
 func (p *Pool[T]) Left() int {
 	return len(p.pool) - p.cur
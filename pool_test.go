@@ -0,0 +1,49 @@
+package gopapageno
+
+import (
+	"testing"
+)
+
+func TestPool_Reset(t *testing.T) {
+	p := NewPool[int](2)
+
+	for i := range 5 {
+		*p.Get() = i + 1
+	}
+
+	p.Reset()
+
+	if p.NumAllocated() != 5 {
+		t.Fatalf("Expected pool to grow to %v items, got %v", 5, p.NumAllocated())
+	}
+
+	for range 5 {
+		if v := p.Get(); *v != 0 {
+			t.Errorf("Expected reset item, got %v", *v)
+		}
+	}
+
+	if p.Left() != 0 {
+		t.Errorf("Expected no items left, got %v", p.Left())
+	}
+
+	p.Reset()
+
+	if p.Left() != 5 {
+		t.Errorf("Expected %v items left, got %v", 5, p.Left())
+	}
+}
+
+func TestPool_ResetStacks(t *testing.T) {
+	p := NewPool(1, WithConstructor(newStack[Token]))
+
+	s := p.Get()
+	s.Tos = 3
+	s.Next = newStack[Token]()
+
+	p.Reset()
+
+	if s = p.Get(); s.Tos != 0 || s.Next != nil || s.Prev != nil {
+		t.Errorf("Expected stack to be reset, got Tos %v", s.Tos)
+	}
+}
@@ -0,0 +1,410 @@
+package gopapageno
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// DefaultWindowSize is the number of bytes read from an io.Reader before a window is handed to the lexer.
+const DefaultWindowSize int = 16 * 1024 * 1024
+
+// maxCutPointLength bounds the length of a cut point match that may straddle two reads.
+const maxCutPointLength = 256
+
+func WithWindowSize(size int) RunnerOpt {
+	return func(r *Runner) {
+		if size <= 0 {
+			size = DefaultWindowSize
+		}
+
+		r.Options.WindowSize = size
+	}
+}
+
+// A MappedFile exposes the contents of a file without copying them into the heap.
+// On platforms supporting it the file is memory-mapped, otherwise it is read in full.
+//
+// Tokens produced by Runner.Run on its Bytes may reference the file contents, so it must not be closed
+// while the parse tree is still in use.
+type MappedFile struct {
+	data  []byte
+	close func() error
+}
+
+// Bytes returns the contents of the file.
+func (f *MappedFile) Bytes() []byte {
+	return f.data
+}
+
+// Close releases the mapping. Bytes must not be used afterward.
+func (f *MappedFile) Close() error {
+	if f.close == nil {
+		return nil
+	}
+
+	err := f.close()
+	f.data, f.close = nil, nil
+
+	return err
+}
+
+// RunFile parses the contents of the file at filename, streaming it through RunReader.
+func (r *Runner) RunFile(ctx context.Context, filename string) (*Token, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not open source file: %w", err)
+	}
+	defer f.Close()
+
+	return r.RunReader(ctx, f)
+}
+
+// RunReader parses the source read from rd.
+// The source is never fully materialized: it is read in windows of RunOptions.WindowSize bytes,
+// each window ending on a cut point, and every window is lexed in parallel while the next one is being read.
+// The lists of tokens of each window are handed to the parser as soon as they are lexed, and the parser joins
+// the results of its first pass into the input of a single final sweep, whatever the ReductionStrategy.
+//
+// Lexer actions receive text backed by the window it was read into, which is never reused;
+// retaining it keeps the whole window alive.
+// Since the source is not retained, a LexError or SyntaxError returned by RunReader only reports byte offsets,
+// unless a SourceMap is built with WithSourceMap, which keeps every window alive instead.
+func (r *Runner) RunReader(ctx context.Context, rd io.Reader) (*Token, error) {
+	opts, cleanupFunc := r.prepare()
+	defer cleanupFunc()
+
+	// The total length of the source is not known in advance, so preamble and state functions receive an estimate.
+	srcLen := sizeHint(rd, opts.WindowSize)
+
+	if r.Lexer.PreambleFunc != nil {
+		r.Lexer.PreambleFunc(srcLen, opts.Concurrency)
+	}
+
+	if r.Parser.PreambleFunc != nil {
+		r.Parser.PreambleFunc(srcLen, opts.Concurrency)
+	}
+
+	scanner := r.Lexer.windowScanner(srcLen, opts)
+	parser := r.Parser.parser(srcLen, opts).(streamParser)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	windowCh := make(chan window, 1)
+	go newWindowReader(rd, r.Lexer, opts.WindowSize).run(ctx, windowCh)
+
+	var sourceMap *SourceMap
+	if opts.sourceMap != nil {
+		sourceMap = &SourceMap{}
+	}
+
+	batchCh := make(chan tokensBatch, 1)
+	parsedCh := make(chan parseOutcome, 1)
+
+	go func() {
+		root, err := parser.parseStream(ctx, batchCh)
+
+		// Once parsing fails, there is no point in going on reading the source.
+		if err != nil {
+			cancel()
+		}
+
+		parsedCh <- parseOutcome{root, err}
+
+		for range batchCh {
+		}
+	}()
+
+	err := r.lexWindows(ctx, scanner, windowCh, batchCh, sourceMap)
+	if err != nil {
+		cancel()
+	}
+
+	parsed := <-parsedCh
+
+	if err != nil && (parsed.err == nil || errors.Is(parsed.err, context.Canceled)) {
+		return nil, err
+	}
+
+	if sourceMap != nil {
+		opts.sourceMap(sourceMap)
+	}
+
+	if parsed.err != nil {
+		if sourceMap != nil {
+			locateError(parsed.err, sourceMap)
+		}
+
+		r.report(parsed.err)
+		return parsed.root, fmt.Errorf("could not parse: %w", parsed.err)
+	}
+
+	return parsed.root, nil
+}
+
+// A parseOutcome is the result of a parser running alongside the lexer.
+type parseOutcome struct {
+	root *Token
+	err  error
+}
+
+// lexWindows lexes the windows received from windowCh with scanner, sending their lists of tokens on batchCh,
+// which it closes once done. If sourceMap is not nil, the windows lexed are added to it.
+// Lexical errors are reported before being returned.
+func (r *Runner) lexWindows(ctx context.Context, scanner *Scanner, windowCh <-chan window, batchCh chan<- tokensBatch, sourceMap *SourceMap) error {
+	defer close(batchCh)
+
+	// rest is the part of the previous window that wasn't lexed, since its last token may continue in the following one.
+	// Each window begins in the start condition the previous one ends in, after its last token, which the scanner keeps.
+	var rest window
+
+	for w := range windowCh {
+		if w.err != nil {
+			return fmt.Errorf("could not read source: %w", w.err)
+		}
+
+		if len(rest.data) > 0 {
+			w.data = slices.Concat(rest.data, w.data)
+			w.offset = rest.offset
+			w.lineStart = rest.lineStart
+		}
+
+		scanner.load(w)
+
+		lists, err := scanner.Lex(ctx)
+		if err != nil {
+			if sourceMap != nil {
+				sourceMap.add(w.offset, w.data, lineStarts(nil, w.data, w.offset))
+				locateError(err, sourceMap)
+			}
+
+			r.report(err)
+			return fmt.Errorf("could not lex: %w", err)
+		}
+
+		consumed := scanner.consumed
+
+		if sourceMap != nil {
+			sourceMap.add(w.offset, w.data[:consumed], scanner.lines)
+		}
+
+		rest = window{
+			data:      w.data[consumed:],
+			offset:    w.offset + consumed,
+			lineStart: w.lineStart,
+		}
+		if consumed > 0 {
+			rest.lineStart = w.data[consumed-1] == '\n'
+		}
+
+		select {
+		case batchCh <- tokensBatch{lists: lists, srcLen: w.offset + consumed}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// joinTokensLists merges adjacent lists of tokens so that at most n non-empty lists remain,
+// balancing the number of tokens contained in each of them.
+func joinTokensLists(lists []*LOS[Token], n int) []*LOS[Token] {
+	total := 0
+	nonEmpty := make([]*LOS[Token], 0, len(lists))
+
+	for _, l := range lists {
+		if l.Length() > 0 {
+			nonEmpty = append(nonEmpty, l)
+			total += l.Length()
+		}
+	}
+
+	if len(nonEmpty) == 0 {
+		return lists[:1]
+	}
+
+	target := (total + n - 1) / n
+
+	joined := []*LOS[Token]{nonEmpty[0]}
+	for _, l := range nonEmpty[1:] {
+		last := joined[len(joined)-1]
+		if len(joined) < n && last.Length() >= target {
+			joined = append(joined, l)
+			continue
+		}
+
+		last.Merge(l)
+	}
+
+	return joined
+}
+
+// sizeHint returns the size of the source behind rd if it can be known without reading it, or fallback otherwise.
+func sizeHint(rd io.Reader, fallback int) int {
+	switch v := rd.(type) {
+	case interface{ Len() int }:
+		return v.Len()
+	case interface{ Stat() (os.FileInfo, error) }:
+		if info, err := v.Stat(); err == nil && info.Mode().IsRegular() {
+			return int(info.Size())
+		}
+	}
+
+	return fallback
+}
+
+// A window is a portion of the source that ends on a cut point.
+type window struct {
+	data   []byte
+	offset int
+	err    error
+
+	// lineStart and lineEnd report whether the window begins at the start of a line and ends at the end of one.
+	lineStart bool
+	lineEnd   bool
+
+	// last reports whether the window ends the source.
+	last bool
+}
+
+// windowReader splits a source into windows.
+type windowReader struct {
+	rd    io.Reader
+	lexer *Lexer
+	size  int
+
+	carry  []byte
+	offset int
+
+	// lineStart reports whether the next window begins at the start of a line.
+	lineStart bool
+}
+
+func newWindowReader(rd io.Reader, lexer *Lexer, size int) *windowReader {
+	if size <= 0 {
+		size = DefaultWindowSize
+	}
+
+	return &windowReader{
+		rd:        rd,
+		lexer:     lexer,
+		size:      size,
+		lineStart: true,
+	}
+}
+
+// run sends every window of the source on windowCh, closing it when the source is exhausted or an error occurs.
+func (r *windowReader) run(ctx context.Context, windowCh chan<- window) {
+	defer close(windowCh)
+
+	for {
+		w, err := r.next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				w = window{err: err}
+			}
+		}
+
+		// The last window is sent even if empty, since the part of the previous one that wasn't lexed is prepended to it.
+		if w.err != nil || len(w.data) > 0 || w.last {
+			select {
+			case windowCh <- w:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// next reads the following window.
+// Data past the last cut point is kept aside and prepended to the next window.
+// If no cut point is found, the window keeps growing until one is found or the source ends.
+// It returns io.EOF alongside the last window.
+func (r *windowReader) next() (window, error) {
+	buf := make([]byte, len(r.carry), len(r.carry)+r.size)
+	copy(buf, r.carry)
+	r.carry = nil
+
+	searchFrom := 0
+
+	for {
+		n, err := io.ReadFull(r.rd, buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			w := r.emit(buf, len(buf))
+			w.last = true
+
+			return w, io.EOF
+		}
+		if err != nil {
+			return window{}, err
+		}
+
+		if cut, ok := r.lastCutPoint(buf, searchFrom); ok {
+			r.carry = buf[cut:]
+			return r.emit(buf, cut), nil
+		}
+
+		// A match may begin just before the end of the data read so far.
+		searchFrom = max(0, len(buf)-maxCutPointLength)
+		buf = append(buf, make([]byte, r.size)...)[:len(buf)]
+	}
+}
+
+func (r *windowReader) emit(buf []byte, cut int) window {
+	w := window{
+		data:      buf[:cut:cut],
+		offset:    r.offset,
+		lineStart: r.lineStart,
+		lineEnd:   cut == len(buf) || isLineBreak(buf[cut]),
+	}
+
+	r.offset += cut
+	if cut > 0 {
+		r.lineStart = buf[cut-1] == '\n'
+	}
+
+	return w
+}
+
+// lastCutPoint returns the position of the last cut point in buf beginning after from.
+// The cut point at position 0 is ignored since it would produce an empty window.
+func (r *windowReader) lastCutPoint(buf []byte, from int) (int, bool) {
+	last, found := 0, false
+
+	// Look for a cut point in the last part of the window first, to avoid scanning it all.
+	tail := max(from, len(buf)-len(buf)/8)
+
+	for _, start := range []int{tail, from} {
+		pos := start
+
+		for {
+			cut, ok := r.lexer.nextCutPoint(buf, pos)
+			if !ok || cut >= len(buf) {
+				break
+			}
+
+			if cut > 0 {
+				last, found = cut, true
+			}
+
+			pos = cut + 1
+		}
+
+		if found {
+			return last, true
+		}
+	}
+
+	return 0, false
+}
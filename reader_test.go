@@ -0,0 +1,51 @@
+package gopapageno
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// newlineCutPoints returns an automaton matching a single newline character.
+func newlineCutPoints() LexerDFA {
+	transitions := make([][256]int, 2)
+	for i := range transitions {
+		for c := range transitions[i] {
+			transitions[i][c] = -1
+		}
+	}
+
+	transitions[0]['\n'] = 1
+
+	return NewLexerDFA([]LexerDFAState{{}, {IsFinal: true}}, transitions)
+}
+
+func TestWindowReader(t *testing.T) {
+	src := []byte(strings.Repeat("ab\ncdef\n", 10) + "a very long line without newlines\nend")
+	lexer := &Lexer{CutPointsAutomaton: newlineCutPoints()}
+
+	windowCh := make(chan window)
+	go newWindowReader(bytes.NewReader(src), lexer, 5).run(context.Background(), windowCh)
+
+	var joined []byte
+	for w := range windowCh {
+		if w.err != nil {
+			t.Fatalf("unexpected error: %v", w.err)
+		}
+
+		if w.offset != len(joined) {
+			t.Errorf("expected window at offset %d, got %d", len(joined), w.offset)
+		}
+
+		if w.offset+len(w.data) < len(src) && src[w.offset+len(w.data)] != '\n' {
+			t.Errorf("window %q does not end on a cut point", w.data)
+		}
+
+		joined = append(joined, w.data...)
+	}
+
+	if !bytes.Equal(joined, src) {
+		t.Errorf("expected windows to cover the source, got %q", joined)
+	}
+}
@@ -2,18 +2,38 @@ package gopapageno
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"runtime/debug"
 	"runtime/pprof"
+	"sync"
 )
 
+// A Runner lexes and parses sources using a Lexer and a Grammar.
+//
+// A Runner is safe for concurrent use by multiple goroutines, as long as its fields are not modified.
+// Every call to Run works on its own copy of Options and allocates its own scanner, parser and semantic state,
+// built by the StateFunc of the Lexer and the Grammar.
+// Runs of lexers or grammars providing a PreambleFunc write package-level state, so they are serialized instead.
 type Runner struct {
 	Lexer  *Lexer
 	Parser *Grammar
 
 	Options RunOptions
+
+	// cache holds the resources kept across runs when pools are reused.
+	// Only one run at a time can use it.
+	cache struct {
+		mu sync.Mutex
+
+		scanner *Scanner
+		parser  reusableParser
+
+		concurrency       int
+		reductionStrategy ReductionStrategy
+	}
 }
 
 type RunOptions struct {
@@ -24,7 +44,16 @@ type RunOptions struct {
 	AvgTokenLength int
 	ParallelFactor float64
 
-	logger *log.Logger
+	WindowSize int
+
+	logger      *log.Logger
+	diagnostics DiagnosticsFunc
+	sourceMap   SourceMapFunc
+
+	recovery  bool
+	maxErrors int
+
+	reusePools bool
 
 	cpuProfileWriter io.Writer
 	memProfileWriter io.Writer
@@ -54,6 +83,37 @@ func WithLogging(logger *log.Logger) RunnerOpt {
 	}
 }
 
+// A DiagnosticsFunc receives the lexical and syntax errors that make a run fail, before they are returned.
+type DiagnosticsFunc func(err error)
+
+// WithDiagnostics makes the Runner report errors to fn instead of writing them to its logger.
+func WithDiagnostics(fn DiagnosticsFunc) RunnerOpt {
+	return func(r *Runner) {
+		r.Options.diagnostics = fn
+	}
+}
+
+// WithErrorRecovery makes parsers go on after a syntax error, until at most maxErrors are found.
+// A non-positive maxErrors places no limit.
+// Input is skipped up to the next synchronizing terminal declared with %sync in the grammar description,
+// so that a single run reports every error alongside a partial parse tree.
+func WithErrorRecovery(maxErrors int) RunnerOpt {
+	return func(r *Runner) {
+		r.Options.recovery = true
+		r.Options.maxErrors = max(maxErrors, 0)
+	}
+}
+
+// WithPoolReuse makes the Runner keep its scanner, parser and their memory pools across runs,
+// rewinding them instead of allocating new ones for every source.
+// When enabled, the parse tree returned by a run is only valid until the following run.
+// Runs that happen concurrently with the one using the pools allocate their own.
+func WithPoolReuse(on bool) RunnerOpt {
+	return func(r *Runner) {
+		r.Options.reusePools = on
+	}
+}
+
 func WithCPUProfiling(w io.Writer) RunnerOpt {
 	return func(r *Runner) {
 		r.Options.cpuProfileWriter = w
@@ -111,6 +171,7 @@ func NewRunner(lexer *Lexer, parser *Grammar, opts ...RunnerOpt) *Runner {
 			ReductionStrategy:  ReductionSweep,
 			AvgTokenLength:     DefaultAverageTokenLength,
 			ParallelFactor:     DefaultParallelFactor,
+			WindowSize:         DefaultWindowSize,
 			logger:             discardLogger,
 			cpuProfileWriter:   nil,
 			memProfileWriter:   nil,
@@ -126,67 +187,194 @@ func NewRunner(lexer *Lexer, parser *Grammar, opts ...RunnerOpt) *Runner {
 }
 
 func (r *Runner) Run(ctx context.Context, src []byte) (*Token, error) {
-	// Old code forced a GC Run to occur, so that it would - hopefully - stop GCs from happening again during computation.
-	// However, a GC run can still be very slow.
-	// runtime.GC()
-
-	// Deferring this will cause the GC to still run at the end of computation...
-	// defer debug.SetGCPercent(1)
-
-	// This new version stops the GC from running entirely.
-	// It makes sense as an option since parsers are mostly used as standalone programs.
-	if !r.Options.gc {
-		debug.SetGCPercent(-1)
-	}
-
-	r.Options.Concurrency = r.Options.InitialConcurrency
-
-	// Profiling
-	cleanupFunc := r.startProfiling()
+	opts, cleanupFunc := r.prepare()
 	defer cleanupFunc()
 
 	// Run preamble functions before anything else.
 	if r.Lexer.PreambleFunc != nil {
-		r.Lexer.PreambleFunc(len(src), r.Options.Concurrency)
+		r.Lexer.PreambleFunc(len(src), opts.Concurrency)
 	}
 
 	if r.Parser.PreambleFunc != nil {
-		r.Parser.PreambleFunc(len(src), r.Options.Concurrency)
+		r.Parser.PreambleFunc(len(src), opts.Concurrency)
+	}
+
+	reuse := opts.reusePools && r.cache.mu.TryLock()
+	if reuse {
+		defer r.cache.mu.Unlock()
 	}
 
 	// Initialize Scanner and Grammar
-	scanner := r.Lexer.Scanner(src, &r.Options)
-	parser := r.Parser.Parser(src, &r.Options)
+	scanner := r.scanner(src, opts, reuse)
+	parser := r.parser(len(src), opts, reuse)
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	tokensLists, err := scanner.Lex(ctx)
 	if err != nil {
+		locateError(err, NewSourceMap(src))
+		r.report(err)
 		return nil, fmt.Errorf("could not lex: %w", err)
 	}
 
+	var sourceMap *SourceMap
+	if opts.sourceMap != nil {
+		sourceMap = scanner.sourceMap()
+		opts.sourceMap(sourceMap)
+	}
+
+	// When recovering from errors, a partial parse tree is returned alongside them.
 	token, err := parser.Parse(ctx, tokensLists)
 	if err != nil {
-		return nil, fmt.Errorf("could not parse: %w", err)
+		if sourceMap == nil {
+			sourceMap = NewSourceMap(src)
+		}
+
+		locateError(err, sourceMap)
+		r.report(err)
+		return token, fmt.Errorf("could not parse: %w", err)
 	}
 
 	return token, nil
 }
 
+// scanner returns a Scanner for src, reusing the one of the previous run if cached resources can be used.
+// The cache must be locked by the caller when cached is true.
+func (r *Runner) scanner(src []byte, opts *RunOptions, cached bool) *Scanner {
+	if !cached {
+		return r.Lexer.Scanner(src, opts)
+	}
+
+	if r.cache.scanner == nil {
+		r.cache.scanner = r.Lexer.Scanner(src, opts)
+	} else {
+		r.cache.scanner.reset(src, opts)
+	}
+
+	return r.cache.scanner
+}
+
+// parser returns a Parser for a source of srcLen bytes, reusing the one of the previous run if cached resources can be used.
+// A new one is built whenever the concurrency level or the reduction strategy change.
+// The cache must be locked by the caller when cached is true.
+func (r *Runner) parser(srcLen int, opts *RunOptions, cached bool) Parser {
+	if !cached {
+		return r.Parser.parser(srcLen, opts)
+	}
+
+	if r.cache.parser != nil && r.cache.concurrency == opts.Concurrency && r.cache.reductionStrategy == opts.ReductionStrategy {
+		r.cache.parser.reset(srcLen, opts)
+		return r.cache.parser
+	}
+
+	r.cache.parser = r.Parser.parser(srcLen, opts).(reusableParser)
+	r.cache.concurrency = opts.Concurrency
+	r.cache.reductionStrategy = opts.ReductionStrategy
+
+	return r.cache.parser
+}
+
+// prepare returns the options of a new run, and applies those that affect the whole process.
+// It returns a function that must be called once the run is over.
+func (r *Runner) prepare() (*RunOptions, func()) {
+	opts := r.Options
+	opts.Concurrency = opts.InitialConcurrency
+
+	// Preamble functions write package-level state, which can't be shared with other runs.
+	if r.Lexer.PreambleFunc != nil || r.Parser.PreambleFunc != nil {
+		preambleMu.Lock()
+	}
+
+	// Old code forced a GC Run to occur, so that it would - hopefully - stop GCs from happening again during computation.
+	// However, a GC run can still be very slow.
+	// runtime.GC()
+
+	// This new version stops the GC from running until every run that disabled it is over.
+	enableGC := func() {}
+	if !opts.gc {
+		enableGC = disableGC()
+	}
+
+	// Profiling
+	stopProfiling := r.startProfiling()
+
+	return &opts, func() {
+		stopProfiling()
+		enableGC()
+
+		if r.Lexer.PreambleFunc != nil || r.Parser.PreambleFunc != nil {
+			preambleMu.Unlock()
+		}
+	}
+}
+
+// preambleMu serializes runs that use preamble functions.
+var preambleMu sync.Mutex
+
+// gcState tracks the runs that disabled the garbage collector.
+var gcState struct {
+	sync.Mutex
+
+	runs    int
+	percent int
+}
+
+// disableGC stops the garbage collector, and returns a function that restores it once every run that stopped it is over.
+func disableGC() func() {
+	gcState.Lock()
+	defer gcState.Unlock()
+
+	if gcState.runs == 0 {
+		gcState.percent = debug.SetGCPercent(-1)
+	}
+	gcState.runs++
+
+	return func() {
+		gcState.Lock()
+		defer gcState.Unlock()
+
+		gcState.runs--
+		if gcState.runs == 0 {
+			debug.SetGCPercent(gcState.percent)
+		}
+	}
+}
+
+// report sends err to the diagnostics function, or to the logger if there is none.
+// Every error of an ErrorList is reported separately.
+func (r *Runner) report(err error) {
+	var list ErrorList
+	if errors.As(err, &list) {
+		for _, e := range list {
+			r.report(e)
+		}
+		return
+	}
+
+	if r.Options.diagnostics != nil {
+		r.Options.diagnostics(err)
+		return
+	}
+
+	r.Options.logger.Print(err)
+}
+
 func (r *Runner) startProfiling() func() {
 	if r.Options.cpuProfileWriter == nil || r.Options.cpuProfileWriter == io.Discard {
 		return func() {}
 	}
 
+	// Only one CPU profile can be active at a time, so concurrent runs are not profiled.
 	if err := pprof.StartCPUProfile(r.Options.cpuProfileWriter); err != nil {
-		log.Printf("could not start CPU profiling: %v", err)
+		r.Options.logger.Printf("could not start CPU profiling: %v", err)
+		return func() {}
 	}
 
 	return func() {
 		if r.Options.memProfileWriter != nil && r.Options.memProfileWriter != io.Discard {
 			if err := pprof.WriteHeapProfile(r.Options.memProfileWriter); err != nil {
-				log.Printf("Could not write memory profile: %v", err)
+				r.Options.logger.Printf("could not write memory profile: %v", err)
 			}
 		}
 
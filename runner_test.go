@@ -0,0 +1,26 @@
+package gopapageno
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestDisableGC(t *testing.T) {
+	percent := debug.SetGCPercent(50)
+	defer debug.SetGCPercent(percent)
+
+	enableFirst := disableGC()
+	enableSecond := disableGC()
+
+	enableFirst()
+
+	if p := debug.SetGCPercent(-1); p != -1 {
+		t.Errorf("Expected GC to stay disabled while a run is active, got percent %v", p)
+	}
+
+	enableSecond()
+
+	if p := debug.SetGCPercent(50); p != 50 {
+		t.Errorf("Expected GC percent to be restored to %v, got %v", 50, p)
+	}
+}
@@ -0,0 +1,131 @@
+package gopapageno
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// A SourceMap converts byte offsets in a source to lines and columns.
+//
+// The Runner builds it while lexing, each worker indexing the lines of its own portion of the source.
+// A SourceMap references the source it was built from, so it must not be modified while the map is in use.
+type SourceMap struct {
+	// lines holds the offsets at which every line but the first begins.
+	lines []int
+
+	// chunks hold the source, split into the portions it was read in.
+	chunks []sourceChunk
+}
+
+type sourceChunk struct {
+	offset int
+	data   []byte
+}
+
+// A SourceMapFunc receives the SourceMap of a source once it has been lexed.
+type SourceMapFunc func(m *SourceMap)
+
+// WithSourceMap makes the Runner build a SourceMap of every source it lexes, and pass it to fn before parsing it.
+// Errors returned by RunReader are then located too.
+func WithSourceMap(fn SourceMapFunc) RunnerOpt {
+	return func(r *Runner) {
+		r.Options.sourceMap = fn
+	}
+}
+
+// A Location is the 1-based line and column of a byte in a source.
+// Columns count UTF-8 encoded characters rather than bytes.
+type Location struct {
+	Line   int
+	Column int
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("%d:%d", l.Line, l.Column)
+}
+
+// NewSourceMap indexes the lines of src.
+func NewSourceMap(src []byte) *SourceMap {
+	m := &SourceMap{}
+	m.add(0, src, lineStarts(nil, src, 0))
+
+	return m
+}
+
+// add appends data, the portion of the source beginning at offset, whose lines begin at the offsets in lines.
+func (m *SourceMap) add(offset int, data []byte, lines []int) {
+	m.lines = append(m.lines, lines...)
+	m.chunks = append(m.chunks, sourceChunk{
+		offset: offset,
+		data:   data,
+	})
+}
+
+// Len returns the length of the source in bytes.
+func (m *SourceMap) Len() int {
+	if len(m.chunks) == 0 {
+		return 0
+	}
+
+	last := m.chunks[len(m.chunks)-1]
+	return last.offset + len(last.data)
+}
+
+// Lines returns the number of lines of the source.
+func (m *SourceMap) Lines() int {
+	return len(m.lines) + 1
+}
+
+// Locate returns the location of the byte at offset.
+// Offsets out of the bounds of the source are clamped to them.
+func (m *SourceMap) Locate(offset int) Location {
+	offset = min(max(offset, 0), m.Len())
+
+	// The number of lines beginning at or before offset.
+	line := sort.SearchInts(m.lines, offset+1)
+
+	lineStart := 0
+	if line > 0 {
+		lineStart = m.lines[line-1]
+	}
+
+	return Location{
+		Line:   line + 1,
+		Column: m.runeCount(lineStart, offset) + 1,
+	}
+}
+
+// runeCount returns the number of UTF-8 encoded characters between the offsets from and to.
+// Characters are counted by their first byte, so they may be split between chunks.
+func (m *SourceMap) runeCount(from int, to int) int {
+	i := sort.Search(len(m.chunks), func(i int) bool {
+		return m.chunks[i].offset+len(m.chunks[i].data) > from
+	})
+
+	count := 0
+	for ; i < len(m.chunks) && m.chunks[i].offset < to; i++ {
+		c := m.chunks[i]
+		for _, b := range c.data[max(from-c.offset, 0):min(to-c.offset, len(c.data))] {
+			if b&0xC0 != 0x80 {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// lineStarts appends to lines the offsets at which the lines beginning within data start.
+// data begins at offset in the source.
+func lineStarts(lines []int, data []byte, offset int) []int {
+	for pos := 0; ; {
+		i := bytes.IndexByte(data[pos:], '\n')
+		if i < 0 {
+			return lines
+		}
+
+		pos += i + 1
+		lines = append(lines, offset+pos)
+	}
+}
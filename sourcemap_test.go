@@ -0,0 +1,58 @@
+package gopapageno
+
+import (
+	"testing"
+)
+
+func TestSourceMap_Locate(t *testing.T) {
+	src := []byte("ab\ncde\n\nf")
+
+	// The same source, indexed in portions as the Runner does.
+	chunked := &SourceMap{}
+	chunked.add(0, src[:4], lineStarts(nil, src[:4], 0))
+	chunked.add(4, src[4:], lineStarts(nil, src[4:], 4))
+
+	tests := []struct {
+		offset int
+		line   int
+		column int
+	}{
+		{0, 1, 1},
+		{2, 1, 3},
+		{3, 2, 1},
+		{5, 2, 3},
+		{7, 3, 1},
+		{8, 4, 1},
+		{9, 4, 2},
+	}
+
+	for _, m := range []*SourceMap{NewSourceMap(src), chunked} {
+		for _, test := range tests {
+			if l := m.Locate(test.offset); l.Line != test.line || l.Column != test.column {
+				t.Errorf("offset %d: expected %d:%d, got %v", test.offset, test.line, test.column, l)
+			}
+		}
+	}
+}
+
+func TestSourceMap_LocateUTF8(t *testing.T) {
+	src := []byte("héllo\n日本語 x")
+	m := NewSourceMap(src)
+
+	tests := []struct {
+		offset int
+		line   int
+		column int
+	}{
+		{3, 1, 3},
+		{7, 2, 1},
+		{10, 2, 2},
+		{17, 2, 5},
+	}
+
+	for _, test := range tests {
+		if l := m.Locate(test.offset); l.Line != test.line || l.Column != test.column {
+			t.Errorf("offset %d: expected %d:%d, got %v", test.offset, test.line, test.column, l)
+		}
+	}
+}
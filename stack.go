@@ -46,6 +46,13 @@ func newStackOf[T any](length int) *stack[T] {
 	}
 }
 
+// reset empties the stack and unlinks it, keeping its storage.
+func (s *stack[T]) reset() {
+	s.Tos = 0
+	s.Prev = nil
+	s.Next = nil
+}
+
 func (s *stack[T]) Push(t T) {
 	if s.Tos >= s.Size {
 		panic("calculations were wrong.")
@@ -73,13 +80,13 @@ func stackSize[T any]() int {
 	return 1024 * 1024 / int(typeSize)
 }
 
-func stacksCount[T any](src []byte, concurrency int, avgTokenLen int) int {
-	return int(math.Ceil(float64(len(src)) / float64(avgTokenLen) / float64(concurrency) / float64(stackSize[T]())))
+func stacksCount[T any](srcLen int, concurrency int, avgTokenLen int) int {
+	return int(math.Ceil(float64(srcLen) / float64(avgTokenLen) / float64(concurrency) / float64(stackSize[T]())))
 }
 
-func stacksCountFactored[T any](src []byte, opts *RunOptions) int {
+func stacksCountFactored[T any](srcLen int, opts *RunOptions) int {
 	parallelMult := 1.0 - (0.999 * opts.ParallelFactor)
-	elements := float64(len(src)) / float64(opts.AvgTokenLength) / float64(opts.Concurrency) * parallelMult
+	elements := float64(srcLen) / float64(opts.AvgTokenLength) / float64(opts.Concurrency) * parallelMult
 
 	return int(math.Floor(elements / float64(stackSize[T]())))
 }
@@ -2,6 +2,7 @@ package gopapageno
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -26,6 +27,17 @@ type Token struct {
 
 	Value any
 
+	// Start and End are the offsets of the first and last byte of the token in the source.
+	// Nonterminals span from the first to the last byte of the tokens they were reduced from.
+	Start int
+	End   int
+
+	// Shift is added to the offsets of the tokens descending from t, which are shared with a tree parsed before an edit
+	// moved them: their own Start and End are still the ones they had in that tree.
+	// Only trees updated by IncrementalParser.Reparse hold shifts, and the offset of a token in the source is found
+	// by adding to its Start and End the shifts of all its ancestors.
+	Shift int
+
 	Next      *Token
 	Child     *Token
 	LastChild *Token
@@ -35,6 +47,54 @@ func (t *Token) IsTerminal() bool {
 	return t.Type.IsTerminal()
 }
 
+// ValueOf returns the semantic value of t as a T, or the zero value of T if t holds no value.
+// Values of tokens whose type is declared with %type are stored as a *T, unless T is itself a pointer type.
+// It panics if t holds a value of another type, which means that the actions of the grammar disagree on it.
+func ValueOf[T any](t *Token) T {
+	var zero T
+
+	switch v := t.Value.(type) {
+	case nil:
+		return zero
+	case *T:
+		if v == nil {
+			return zero
+		}
+		return *v
+	case T:
+		return v
+	}
+
+	panic(fmt.Sprintf("ValueOf[%v] called on a token holding a value of type %T", reflect.TypeFor[T](), t.Value))
+}
+
+// SetValue stores v as the semantic value of t.
+// The value is copied into the memory t already holds for a T, or into a new item taken from pool,
+// so that storing it doesn't allocate.
+func SetValue[T any](t *Token, v T, pool *Pool[T]) {
+	if p, ok := t.Value.(*T); ok {
+		*p = v
+		return
+	}
+
+	p := pool.Get()
+	*p = v
+
+	t.Value = p
+}
+
+// setSpan sets the span of t to the one covered by rhs, the tokens it was reduced from.
+func (t *Token) setSpan(rhs []*Token) {
+	t.Start = rhs[0].Start
+	t.End = rhs[len(rhs)-1].End
+}
+
+// extendSpan widens the span of t to also cover rhs, the tokens appended to it.
+func (t *Token) extendSpan(rhs []*Token) {
+	t.Start = min(t.Start, rhs[0].Start)
+	t.End = max(t.End, rhs[len(rhs)-1].End)
+}
+
 // Height computes the height of the AST rooted in `t`.
 // It can be used as an evaluation metric for tree-balance, as left/right-skewed trees will have a bigger height compared to balanced trees.
 func (t *Token) Height() int {
@@ -2,6 +2,7 @@ package gopapageno_test
 
 import (
 	"github.com/giornetta/gopapageno"
+	"strings"
 	"testing"
 )
 
@@ -109,3 +110,45 @@ func TestToken_Height(t *testing.T) {
 		t.Errorf("Balanced Tree expected 4, got %d", h)
 	}
 }
+
+func TestValueOf(t *testing.T) {
+	pool := gopapageno.NewPool[int64](1)
+
+	token := &gopapageno.Token{}
+	if v := gopapageno.ValueOf[int64](token); v != 0 {
+		t.Errorf("Expected zero value, got %d", v)
+	}
+
+	gopapageno.SetValue(token, 42, pool)
+	stored := token.Value.(*int64)
+
+	gopapageno.SetValue(token, 43, pool)
+	if token.Value.(*int64) != stored {
+		t.Errorf("Expected value to be stored in place")
+	}
+
+	if v := gopapageno.ValueOf[int64](token); v != 43 {
+		t.Errorf("Expected 43, got %d", v)
+	}
+
+	s := "value"
+	token.Value = &s
+	if v := gopapageno.ValueOf[*string](token); v != &s {
+		t.Errorf("Expected pointer values to be returned as they are")
+	}
+}
+
+func TestValueOfMismatch(t *testing.T) {
+	token := &gopapageno.Token{Value: "value"}
+
+	defer func() {
+		r := recover()
+
+		msg, ok := r.(string)
+		if !ok || !strings.Contains(msg, "int64") || !strings.Contains(msg, "string") {
+			t.Errorf("Expected a panic naming both types, got %v", r)
+		}
+	}()
+
+	gopapageno.ValueOf[int64](token)
+}